@@ -0,0 +1,191 @@
+package main
+
+import (
+	"context"
+	"io"
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/golang-standards/project-layout/internal/pkg/auth"
+	"github.com/golang-standards/project-layout/internal/pkg/config"
+	"github.com/golang-standards/project-layout/internal/pkg/interceptors"
+	pb "github.com/golang-standards/project-layout/pkg/api/user/v1"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+	"google.golang.org/protobuf/types/known/emptypb"
+)
+
+// principalServer answers with the principal each call runs as
+type principalServer struct {
+	pb.UnimplementedUserServiceServer
+}
+
+func principalUser(ctx context.Context) *pb.User {
+	principal, _ := auth.PrincipalFromContext(ctx)
+	// Email carries the impersonating admin, if any
+	return &pb.User{Id: principal.UserID, Email: principal.ImpersonatorID}
+}
+
+func (principalServer) GetMe(ctx context.Context, _ *emptypb.Empty) (*pb.GetUserResponse, error) {
+	return &pb.GetUserResponse{User: principalUser(ctx)}, nil
+}
+
+func (principalServer) UpdateUser(ctx context.Context, _ *pb.UpdateUserRequest) (*pb.UpdateUserResponse, error) {
+	return &pb.UpdateUserResponse{User: principalUser(ctx)}, nil
+}
+
+func (principalServer) StreamUsers(_ *pb.StreamUsersRequest, stream grpc.ServerStreamingServer[pb.User]) error {
+	return stream.Send(principalUser(stream.Context()))
+}
+
+// newAuthClient serves principalServer behind the auth interceptors built from the
+// default config with a JWT secret and impersonation enabled
+func newAuthClient(t *testing.T) (pb.UserServiceClient, *auth.Authenticator) {
+	t.Helper()
+	const secret = "0123456789abcdef0123456789abcdef"
+
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	content := "auth:\n  jwt:\n    secret: " + secret + "\n  impersonation:\n    enabled: true\n"
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatalf("write config: %v", err)
+	}
+	cfg, err := config.Load(path)
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+
+	chain, err := interceptors.NewChain(nil)
+	if err != nil {
+		t.Fatalf("NewChain: %v", err)
+	}
+	authenticator := addAuthInterceptors(chain, cfg.Auth)
+	if authenticator == nil {
+		t.Fatal("addAuthInterceptors returned no authenticator")
+	}
+
+	listener := bufconn.Listen(1 << 20)
+	server := grpc.NewServer(chain.ServerOptions()...)
+	pb.RegisterUserServiceServer(server, principalServer{})
+	go func() { _ = server.Serve(listener) }()
+	t.Cleanup(server.Stop)
+
+	conn, err := grpc.NewClient("passthrough:///bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) { return listener.DialContext(ctx) }),
+		grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if err != nil {
+		t.Fatalf("dial: %v", err)
+	}
+	t.Cleanup(func() { _ = conn.Close() })
+	return pb.NewUserServiceClient(conn), authenticator
+}
+
+func TestImpersonationThroughAuthChain(t *testing.T) {
+	client, authenticator := newAuthClient(t)
+
+	tests := []struct {
+		name             string
+		principal        auth.Principal
+		impersonate      string
+		call             func(ctx context.Context) (*pb.User, error)
+		wantCode         codes.Code
+		wantUserID       string
+		wantImpersonator string
+	}{
+		{
+			name:             "allowed method",
+			principal:        auth.Principal{UserID: "admin-1", Role: auth.RoleAdmin},
+			impersonate:      "user-1",
+			call:             getMe(client),
+			wantUserID:       "user-1",
+			wantImpersonator: "admin-1",
+		},
+		{
+			name:        "admin method is refused",
+			principal:   auth.Principal{UserID: "admin-1", Role: auth.RoleAdmin},
+			impersonate: "user-1",
+			call:        updateUser(client),
+			wantCode:    codes.PermissionDenied,
+		},
+		{
+			name:        "stream is restricted",
+			principal:   auth.Principal{UserID: "admin-1", Role: auth.RoleAdmin},
+			impersonate: "user-1",
+			call:        streamUsers(client),
+			wantCode:    codes.PermissionDenied,
+		},
+		{
+			name:       "admin stream without impersonation",
+			principal:  auth.Principal{UserID: "admin-1", Role: auth.RoleAdmin},
+			call:       streamUsers(client),
+			wantUserID: "admin-1",
+		},
+		{
+			name:        "user may not impersonate",
+			principal:   auth.Principal{UserID: "user-2", Role: auth.RoleUser},
+			impersonate: "user-1",
+			call:        getMe(client),
+			wantCode:    codes.PermissionDenied,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			token, err := authenticator.Issue(tt.principal, time.Hour)
+			if err != nil {
+				t.Fatalf("Issue: %v", err)
+			}
+			ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+			defer cancel()
+			ctx = metadata.AppendToOutgoingContext(ctx, auth.AuthorizationMetadataKey, "Bearer "+token)
+			if tt.impersonate != "" {
+				ctx = metadata.AppendToOutgoingContext(ctx, auth.ImpersonateMetadataKey, tt.impersonate)
+			}
+
+			user, err := tt.call(ctx)
+			if code := status.Code(err); code != tt.wantCode {
+				t.Fatalf("code = %v, want %v (%v)", code, tt.wantCode, err)
+			}
+			if err != nil {
+				return
+			}
+			if user.Id != tt.wantUserID || user.Email != tt.wantImpersonator {
+				t.Errorf("principal = %q impersonated by %q, want %q by %q", user.Id, user.Email, tt.wantUserID, tt.wantImpersonator)
+			}
+		})
+	}
+}
+
+func getMe(client pb.UserServiceClient) func(ctx context.Context) (*pb.User, error) {
+	return func(ctx context.Context) (*pb.User, error) {
+		resp, err := client.GetMe(ctx, &emptypb.Empty{})
+		return resp.GetUser(), err
+	}
+}
+
+func updateUser(client pb.UserServiceClient) func(ctx context.Context) (*pb.User, error) {
+	return func(ctx context.Context) (*pb.User, error) {
+		resp, err := client.UpdateUser(ctx, &pb.UpdateUserRequest{Id: "user-1"})
+		return resp.GetUser(), err
+	}
+}
+
+func streamUsers(client pb.UserServiceClient) func(ctx context.Context) (*pb.User, error) {
+	return func(ctx context.Context) (*pb.User, error) {
+		stream, err := client.StreamUsers(ctx, &pb.StreamUsersRequest{})
+		if err != nil {
+			return nil, err
+		}
+		user, err := stream.Recv()
+		if err == io.EOF {
+			return nil, status.Error(codes.Internal, "stream ended without a user")
+		}
+		return user, err
+	}
+}
@@ -0,0 +1,142 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/golang-standards/project-layout/internal/app/user-service/model"
+	"github.com/golang-standards/project-layout/internal/app/user-service/repository"
+	"github.com/golang-standards/project-layout/internal/app/user-service/service"
+	"github.com/golang-standards/project-layout/internal/pkg/config"
+	"github.com/golang-standards/project-layout/internal/pkg/database"
+	"github.com/golang-standards/project-layout/internal/pkg/emailnorm"
+	"github.com/golang-standards/project-layout/internal/pkg/events"
+	"github.com/golang-standards/project-layout/internal/pkg/logger"
+	"github.com/golang-standards/project-layout/internal/pkg/validation"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// createAdminCommand is the subcommand that bootstraps an administrator account
+const createAdminCommand = "create-admin"
+
+// runCreateAdmin creates an active, verified user with the admin role and prints its ID.
+// Like the server, it applies pending migrations only with --migrate or database.auto_migrate.
+func runCreateAdmin(args []string) error {
+	fs := flag.NewFlagSet(createAdminCommand, flag.ContinueOnError)
+	email := fs.String("email", "", "administrator email (required)")
+	password := fs.String("password", "", "administrator password (default $APP_ADMIN_PASSWORD)")
+	firstName := fs.String("first-name", "Admin", "administrator first name")
+	lastName := fs.String("last-name", "", "administrator last name")
+	configFile := fs.String("config", "", "path to the config file (default $APP_CONFIG_FILE or configs/config.yaml)")
+	migrate := fs.Bool("migrate", false, "apply pending database migrations first")
+	if err := fs.Parse(args); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return nil
+		}
+		return err
+	}
+
+	if *password == "" {
+		*password = os.Getenv("APP_ADMIN_PASSWORD")
+	}
+	if *email == "" || *password == "" {
+		return errors.New("--email and --password (or APP_ADMIN_PASSWORD) are required")
+	}
+
+	cfg, err := config.Load(*configFile)
+	if err != nil {
+		return fmt.Errorf("failed to load configuration: %w", err)
+	}
+	if cfg.Database.Driver == config.DriverMemory {
+		return errors.New("create-admin needs persistent storage; the memory driver loses the user on exit")
+	}
+
+	log, err := logger.NewLoggerFromConfig(cfg.Logger)
+	if err != nil {
+		return fmt.Errorf("failed to initialize logger: %w", err)
+	}
+	defer log.Sync()
+
+	store, err := repository.New(cfg.Database)
+	if err != nil {
+		return fmt.Errorf("failed to initialize storage: %w", err)
+	}
+	defer database.Close(store.DB)
+	if cfg.Database.AutoMigrate || *migrate {
+		if err := database.RunMigrations(store.DB); err != nil {
+			return fmt.Errorf("failed to run migrations: %w", err)
+		}
+	} else if pending, err := database.PendingMigrations(store.DB); err != nil {
+		return fmt.Errorf("failed to check for pending migrations: %w", err)
+	} else if pending > 0 {
+		return fmt.Errorf("%d database migrations are pending; apply them with --migrate", pending)
+	}
+
+	// The user.created event goes through the outbox like the server's. Without it, events
+	// are held until the transaction commits, so a rolled-back admin is never announced.
+	var publisher events.Publisher = events.NewNoopPublisher()
+	if cfg.Kafka.Enabled() && !cfg.Outbox.Enabled {
+		kafkaPublisher, err := events.NewKafkaPublisher(cfg.Kafka, false, log, events.NewMetrics(prometheus.NewRegistry()))
+		if err != nil {
+			return fmt.Errorf("failed to create Kafka event publisher: %w", err)
+		}
+		defer kafkaPublisher.Close()
+		publisher = kafkaPublisher
+	}
+	pending := &heldPublisher{}
+
+	ctx := context.Background()
+	var user *model.User
+	err = store.Users.WithTx(ctx, func(txRepo repository.UserRepository) error {
+		userService := service.NewUserService(txRepo, log, service.Options{
+			EmailNormalizer: emailnorm.New(cfg.Email),
+			PasswordPolicy:  validation.PasswordPolicy(cfg.Auth.PasswordPolicy),
+			BcryptCost:      cfg.Auth.BcryptCost,
+			Events:          pending,
+			Outbox:          cfg.Outbox.Enabled,
+		})
+
+		var err error
+		user, err = userService.CreateUser(ctx, *email, *password, *firstName, *lastName, "")
+		if err != nil {
+			if errors.Is(err, service.ErrInvalidPassword) {
+				return fmt.Errorf("password does not meet the policy: %w", err)
+			}
+			return fmt.Errorf("failed to create administrator: %w", err)
+		}
+
+		// The administrator proved nothing by email, but must be able to log in immediately
+		user.Role = model.UserRoleAdmin
+		user.Status = model.UserStatusActive
+		user.EmailVerified = true
+		if err := txRepo.Update(ctx, user, []string{"role", "status", "email_verified"}); err != nil {
+			return fmt.Errorf("failed to activate administrator: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	for _, event := range pending.events {
+		if err := publisher.Publish(ctx, event); err != nil {
+			log.Error("Failed to publish user event", "error", err, "type", event.Type, "user_id", event.UserID)
+		}
+	}
+
+	fmt.Println(user.ID)
+	return nil
+}
+
+// heldPublisher collects events for publishing once the transaction they belong to commits
+type heldPublisher struct {
+	events []events.Event
+}
+
+func (p *heldPublisher) Publish(ctx context.Context, event events.Event) error {
+	p.events = append(p.events, event)
+	return nil
+}
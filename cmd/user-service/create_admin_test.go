@@ -0,0 +1,88 @@
+package main
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/golang-standards/project-layout/internal/app/user-service/model"
+	"github.com/golang-standards/project-layout/internal/app/user-service/repository"
+	"github.com/golang-standards/project-layout/internal/pkg/config"
+	"github.com/golang-standards/project-layout/internal/pkg/database"
+)
+
+// writeAdminConfig writes a config file using a SQLite database in dir
+func writeAdminConfig(t *testing.T, dir string, autoMigrate bool) string {
+	t.Helper()
+	path := filepath.Join(dir, "config.yaml")
+	content := "database:\n" +
+		"  driver: sqlite\n" +
+		"  sqlite_path: " + filepath.Join(dir, "admin.db") + "\n" +
+		"  auto_migrate: " + map[bool]string{false: "false", true: "true"}[autoMigrate] + "\n" +
+		"auth:\n" +
+		"  bcrypt_cost: 4\n"
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatalf("write config: %v", err)
+	}
+	return path
+}
+
+func TestRunCreateAdmin(t *testing.T) {
+	tests := []struct {
+		name        string
+		autoMigrate bool
+		args        []string
+		wantErr     string
+		wantAdmin   bool
+	}{
+		{name: "pending migrations", wantErr: "pending"},
+		{name: "migrate flag", args: []string{"--migrate"}, wantAdmin: true},
+		{name: "auto migrate", autoMigrate: true, wantAdmin: true},
+		{name: "password too short", autoMigrate: true, args: []string{"--password", "short"}, wantErr: "policy"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			configPath := writeAdminConfig(t, dir, tt.autoMigrate)
+			args := append([]string{"--email", "admin@example.com", "--password", "correct-horse", "--config", configPath}, tt.args...)
+
+			err := runCreateAdmin(args)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("runCreateAdmin = %v, want an error containing %q", err, tt.wantErr)
+				}
+			} else if err != nil {
+				t.Fatalf("runCreateAdmin: %v", err)
+			}
+
+			db, err := database.NewSQLiteDB(config.DatabaseConfig{SQLitePath: filepath.Join(dir, "admin.db"), MaxOpenConns: 1})
+			if err != nil {
+				t.Fatalf("open sqlite: %v", err)
+			}
+			defer database.Close(db)
+			if !db.Migrator().HasTable("users") {
+				if tt.wantAdmin {
+					t.Fatal("users table missing after create-admin")
+				}
+				return
+			}
+
+			user, err := repository.NewUserRepository(db, database.RetryPolicy{}).GetByEmail(context.Background(), "admin@example.com")
+			if !tt.wantAdmin {
+				if err == nil {
+					t.Errorf("found user %s, want none after a failed create-admin", user.ID)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("GetByEmail: %v", err)
+			}
+			if user.Role != model.UserRoleAdmin || user.Status != model.UserStatusActive || !user.EmailVerified {
+				t.Errorf("admin = role %q, status %q, verified %v; want an active, verified admin", user.Role, user.Status, user.EmailVerified)
+			}
+		})
+	}
+}
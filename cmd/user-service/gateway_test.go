@@ -0,0 +1,33 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/golang-standards/project-layout/internal/pkg/ratelimit"
+)
+
+func TestGatewayHeaderMatcher(t *testing.T) {
+	tests := []struct {
+		header string
+		want   string
+		wantOK bool
+	}{
+		{header: "Authorization", want: "authorization", wantOK: true},
+		{header: "X-Request-Id", want: "x-request-id", wantOK: true},
+		{header: "X-Consistency-Token", want: "x-consistency-token", wantOK: true},
+		{header: "Idempotency-Key", want: "idempotency-key", wantOK: true},
+		{header: "Grpc-Metadata-Tenant", want: "Tenant", wantOK: true},
+		{header: "Grpc-Metadata-" + ratelimit.GatewayMetadataKey},
+		{header: "Grpc-Metadata-X-Gateway-Token"},
+		{header: "X-Gateway-Token"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.header, func(t *testing.T) {
+			got, ok := gatewayHeaderMatcher(tt.header)
+			if ok != tt.wantOK || got != tt.want {
+				t.Errorf("gatewayHeaderMatcher(%q) = %q, %v; want %q, %v", tt.header, got, ok, tt.want, tt.wantOK)
+			}
+		})
+	}
+}
@@ -2,25 +2,57 @@ package main
 
 import (
 	"context"
+	"errors"
+	"flag"
 	"fmt"
-	"net"
 	"net/http"
 	"os"
 	"os/signal"
+	"strings"
+	"sync"
 	"syscall"
 	"time"
 
 	"github.com/golang-standards/project-layout/internal/app/user-service/handler"
 	"github.com/golang-standards/project-layout/internal/app/user-service/repository"
 	"github.com/golang-standards/project-layout/internal/app/user-service/service"
+	"github.com/golang-standards/project-layout/internal/pkg/auth"
+	"github.com/golang-standards/project-layout/internal/pkg/concurrency"
 	"github.com/golang-standards/project-layout/internal/pkg/config"
+	"github.com/golang-standards/project-layout/internal/pkg/consistency"
+	"github.com/golang-standards/project-layout/internal/pkg/cors"
 	"github.com/golang-standards/project-layout/internal/pkg/database"
+	"github.com/golang-standards/project-layout/internal/pkg/dedup"
+	"github.com/golang-standards/project-layout/internal/pkg/drain"
+	"github.com/golang-standards/project-layout/internal/pkg/emailnorm"
+	"github.com/golang-standards/project-layout/internal/pkg/events"
+	"github.com/golang-standards/project-layout/internal/pkg/health"
+	"github.com/golang-standards/project-layout/internal/pkg/interceptors"
+	"github.com/golang-standards/project-layout/internal/pkg/listener"
 	"github.com/golang-standards/project-layout/internal/pkg/logger"
+	"github.com/golang-standards/project-layout/internal/pkg/mailer"
+	"github.com/golang-standards/project-layout/internal/pkg/metrics"
+	"github.com/golang-standards/project-layout/internal/pkg/ratelimit"
+	"github.com/golang-standards/project-layout/internal/pkg/recovery"
+	"github.com/golang-standards/project-layout/internal/pkg/requestid"
+	"github.com/golang-standards/project-layout/internal/pkg/timeout"
+	"github.com/golang-standards/project-layout/internal/pkg/tlsconfig"
+	"github.com/golang-standards/project-layout/internal/pkg/tracing"
+	"github.com/golang-standards/project-layout/internal/pkg/validation"
 	pb "github.com/golang-standards/project-layout/pkg/api/user/v1"
 
+	"github.com/grpc-ecosystem/grpc-gateway/v2/runtime"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	"github.com/redis/go-redis/v9"
+	"go.opentelemetry.io/contrib/instrumentation/google.golang.org/grpc/otelgrpc"
 	"google.golang.org/grpc"
-	"google.golang.org/grpc/health"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/credentials/insecure"
+	grpchealth "google.golang.org/grpc/health"
 	"google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/keepalive"
+	"google.golang.org/grpc/metadata"
 	"google.golang.org/grpc/reflection"
 )
 
@@ -36,72 +68,376 @@ var (
 // @host localhost:50051
 // @BasePath /api/v1
 func main() {
+	if len(os.Args) > 1 && os.Args[1] == createAdminCommand {
+		if err := runCreateAdmin(os.Args[2:]); err != nil {
+			fmt.Fprintln(os.Stderr, "create-admin:", err)
+			os.Exit(1)
+		}
+		return
+	}
+
+	migrateOnly := flag.Bool("migrate", false, "apply pending database migrations and exit")
+	rollbackSteps := flag.Int("rollback", 0, "roll back this many database migrations and exit")
+	migrateDryRun := flag.Bool("migrate-dry-run", false, "print the SQL of pending database migrations without applying it and exit")
+	configFile := flag.String("config", "", "path to the config file (default $APP_CONFIG_FILE or configs/config.yaml)")
+	flag.Parse()
+
+	// Load configuration (errors are reported with a default logger)
+	cfg, err := config.Load(*configFile)
+	if err != nil {
+		logger.NewLogger().Fatal("Failed to load configuration", "error", err)
+	}
+
 	// Initialize logger
-	log := logger.NewLogger()
+	log, err := logger.NewLoggerFromConfig(cfg.Logger)
+	if err != nil {
+		logger.NewLogger().Fatal("Failed to initialize logger", "error", err)
+	}
 	defer log.Sync()
 
 	log.Info("Starting User Service",
 		"version", Version,
 		"build_time", BuildTime,
 		"git_commit", GitCommit,
+		"shutdown_timeout", cfg.Server.ShutdownTimeout,
 	)
 
-	// Load configuration
-	cfg, err := config.Load()
+	// Initialize tracing
+	shutdownTracing, err := tracing.Init(context.Background(), cfg.Tracing)
 	if err != nil {
-		log.Fatal("Failed to load configuration", "error", err)
+		log.Fatal("Failed to initialize tracing", "error", err)
 	}
 
-	// Initialize database
-	db, err := database.NewPostgresDB(cfg.Database)
+	// Initialize storage, labeling database connections with the service name and version
+	setRuntimeDefaults(cfg)
+	store, err := repository.New(cfg.Database)
 	if err != nil {
-		log.Fatal("Failed to connect to database", "error", err)
+		log.Fatal("Failed to initialize storage", "error", err, "driver", cfg.Database.Driver)
 	}
+	db := store.DB
+	if db == nil && (*migrateOnly || *rollbackSteps > 0 || *migrateDryRun) {
+		log.Fatal("Migrations require a SQL database", "driver", cfg.Database.Driver)
+	}
+
+	// Background tasks stop when this context is cancelled on shutdown
+	bgCtx, stopBackground := context.WithCancel(context.Background())
+	defer stopBackground()
+
+	var pinger database.Pinger
+	if db != nil {
+		// Sample connection pool saturation for alerting
+		sqlDB, err := db.DB()
+		if err != nil {
+			log.Fatal("Failed to get underlying database", "error", err)
+		}
+		pinger, err = database.NewPinger(db)
+		if err != nil {
+			log.Fatal("Failed to create database health check", "error", err)
+		}
+		poolMetrics := metrics.NewPoolMetrics(prometheus.DefaultRegisterer)
+		go poolMetrics.Run(bgCtx, cfg.Metrics.PoolScrapeInterval, sqlDB.Stats)
 
-	// Run migrations
-	if err := database.RunMigrations(db); err != nil {
-		log.Fatal("Failed to run migrations", "error", err)
+		// Show pending migrations when asked, without applying them
+		if *migrateDryRun {
+			plan, err := database.MigrationPlan(db)
+			if err != nil {
+				log.Fatal("Failed to plan migrations", "error", err)
+			}
+			if len(plan) == 0 {
+				fmt.Println("-- no pending migrations")
+			}
+			for _, step := range plan {
+				fmt.Println(step)
+				fmt.Println()
+			}
+			return
+		}
+
+		// Roll back migrations when asked, without starting the servers
+		if *rollbackSteps > 0 {
+			if err := database.Rollback(db, *rollbackSteps); err != nil {
+				log.Fatal("Failed to roll back migrations", "error", err)
+			}
+			log.Info("Rolled back migrations", "steps", *rollbackSteps)
+			return
+		}
+
+		// Run migrations unless they are managed by a separate job
+		if cfg.Database.AutoMigrate || *migrateOnly {
+			if err := database.RunMigrations(db); err != nil {
+				log.Fatal("Failed to run migrations", "error", err)
+			}
+			log.Info("Database migrations applied")
+		} else if pending, err := database.PendingMigrations(db); err != nil {
+			log.Warn("Skipped migrations and could not check for pending ones", "error", err)
+		} else if pending > 0 {
+			log.Warn("Skipped migrations with some still pending; run them with --migrate", "pending", pending)
+		} else {
+			log.Info("Skipped migrations; schema is up to date")
+		}
+		if *migrateOnly {
+			return
+		}
+
+		// Fail fast when the schema lags behind the models, e.g. after a skipped migration
+		if err := database.VerifySchema(db, repository.Models()...); err != nil {
+			log.Fatal("Database schema verification failed", "error", err)
+		}
+	} else {
+		log.Warn("Using in-memory storage; data is lost on restart", "driver", cfg.Database.Driver)
 	}
 
 	// Initialize repository, service, and handler
-	userRepo := repository.NewUserRepository(db)
-	userService := service.NewUserService(userRepo, log)
-	userHandler := handler.NewUserHandler(userService, log)
+	var redisClient *redis.Client
+	userRepo := store.Users
+	if cfg.Redis.Addr != "" {
+		redisClient = redis.NewClient(&redis.Options{
+			Addr:     cfg.Redis.Addr,
+			Password: cfg.Redis.Password,
+			DB:       cfg.Redis.DB,
+		})
+		defer redisClient.Close()
+		userRepo = repository.NewCachedUserRepository(userRepo, redisClient, cfg.Redis.CacheTTL)
+		log.Info("User caching enabled", "redis_addr", cfg.Redis.Addr, "ttl", cfg.Redis.CacheTTL)
+	}
+	// Publish user lifecycle events to Kafka when configured; queued events are flushed on shutdown.
+	// With the outbox only the relay publishes, and it must wait for each event to be delivered.
+	var eventPublisher events.Publisher = events.NewNoopPublisher()
+	var kafkaPublisher *events.KafkaPublisher
+	if cfg.Kafka.Enabled() {
+		kafkaPublisher, err = events.NewKafkaPublisher(cfg.Kafka, !cfg.Outbox.Enabled, log, events.NewMetrics(prometheus.DefaultRegisterer))
+		if err != nil {
+			log.Fatal("Failed to create Kafka event publisher", "error", err)
+		}
+		eventPublisher = kafkaPublisher
+		log.Info("Publishing user events to Kafka", "brokers", cfg.Kafka.Brokers, "topic", cfg.Kafka.Topic, "encoding", cfg.Kafka.Encoding)
+	}
+	var outboxRelay *events.Relay
+	if cfg.Outbox.Enabled {
+		outboxRelay = events.NewRelay(store.Outbox, eventPublisher, cfg.Outbox.BatchSize, cfg.Outbox.Retention, log)
+		go outboxRelay.Run(bgCtx, cfg.Outbox.RelayInterval)
+		log.Info("Relaying user events through the outbox", "interval", cfg.Outbox.RelayInterval, "batch_size", cfg.Outbox.BatchSize)
+	}
+	var notifier service.Notifier
+	if cfg.SMTP.Enabled() {
+		notifier = service.NewEmailNotifier(mailer.NewSMTPMailer(cfg.SMTP), cfg.SMTP.ResetURL, cfg.SMTP.VerifyURL)
+		log.Info("Sending account email through SMTP", "host", cfg.SMTP.Host, "port", cfg.SMTP.Port)
+	} else {
+		log.Warn("No SMTP server configured; password reset and email verification are disabled")
+	}
+	userService := service.NewUserService(userRepo, log, service.Options{
+		EmailNormalizer:            emailnorm.New(cfg.Email),
+		PasswordPolicy:             validation.PasswordPolicy(cfg.Auth.PasswordPolicy),
+		BcryptCost:                 cfg.Auth.BcryptCost,
+		MinVerificationTime:        cfg.Auth.MinVerificationTime,
+		BatchGetPartialResults:     cfg.Service.BatchGetPartialResults,
+		ResetTokens:                store.ResetTokens,
+		ResetTokenTTL:              cfg.Auth.PasswordResetTTL,
+		Notifier:                   notifier,
+		VerificationTokens:         store.VerificationTokens,
+		VerificationTokenTTL:       cfg.Auth.EmailVerificationTTL,
+		VerificationResendInterval: cfg.Auth.VerificationResendInterval,
+		RequireEmailVerification:   cfg.Auth.RequireEmailVerification,
+		Lockout:                    service.LockoutPolicy(cfg.Auth.Lockout),
+		Events:                     eventPublisher,
+		Outbox:                     cfg.Outbox.Enabled,
+	})
+	userHandler := handler.NewUserHandler(userService, log, handler.Options{
+		MaskContactFields: cfg.Auth.MaskContactFields,
+		Build: handler.BuildInfo{
+			Version:   Version,
+			BuildTime: BuildTime,
+			GitCommit: GitCommit,
+		},
+	})
+
+	// Track drain state and in-flight requests for deploy tooling
+	drainState := drain.NewState()
+
+	// Readiness checks served on /readyz; each dependency registers its own probe
+	readiness := health.NewChecker(cfg.Server.HealthCheckInterval)
+	readiness.Register("drain", func(context.Context) error {
+		if drainState.Draining() {
+			return errors.New("draining")
+		}
+		return nil
+	})
+	if pinger != nil {
+		readiness.Register("database", pinger.Ping)
+	}
+	if redisClient != nil {
+		readiness.Register("redis", func(ctx context.Context) error {
+			return redisClient.Ping(ctx).Err()
+		})
+	}
+
+	// Register gRPC and build info metrics with the default Prometheus registry
+	grpcMetrics := metrics.NewMetrics(prometheus.DefaultRegisterer, cfg.Metrics.Buckets)
+	metrics.RegisterBuildInfo(prometheus.DefaultRegisterer, Version, GitCommit, BuildTime)
+
+	// Rate limiting shares one store between the gRPC interceptor and the REST gateway
+	var limiter ratelimit.Store
+	if cfg.RateLimit.Enabled {
+		if cfg.RateLimit.Store == "redis" {
+			limiter = ratelimit.NewRedisStore(redisClient, cfg.RateLimit.Rate, cfg.RateLimit.Burst)
+		} else {
+			limiter = ratelimit.NewMemoryStore(cfg.RateLimit.Rate, cfg.RateLimit.Burst)
+		}
+	}
+	rateLimitMetrics := ratelimit.NewMetrics(prometheus.DefaultRegisterer)
+	// The gateway presents this token so its calls, already limited over HTTP, aren't limited twice
+	gatewayToken, err := ratelimit.NewGatewayToken()
+	if err != nil {
+		log.Fatal("Failed to generate the gateway token", "error", err)
+	}
+	timeouts := timeout.NewPolicy(cfg.Server.DefaultTimeout, cfg.Server.MethodTimeouts)
+
+	// Apply reloadable settings when the config files change or on SIGHUP; other
+	// settings only take effect on restart
+	live := newLiveConfig(log, cfg, limiter, timeouts)
+	go func() {
+		if err := config.Watch(bgCtx, live.apply); err != nil {
+			log.Error("Config watcher stopped", "error", err)
+		}
+	}()
+	go live.reloadOnSignal(bgCtx)
+
+	// The chain orders interceptors by stage; see the interceptors package for the order
+	chain, err := interceptors.NewChain(cfg.Server.DisabledInterceptors)
+	if err != nil {
+		log.Fatal("Invalid interceptor configuration", "error", err)
+	}
+	concurrencyLimiter := concurrency.NewLimiter(cfg.Server.MaxConcurrentRequests)
+	chain.
+		Unary(interceptors.StageRecovery, recovery.UnaryServerInterceptor(log)).
+		Stream(interceptors.StageRecovery, recovery.StreamServerInterceptor(log)).
+		Unary(interceptors.StageDrain, drain.UnaryServerInterceptor(drainState)).
+		Stream(interceptors.StageDrain, drain.StreamServerInterceptor(drainState)).
+		Unary(interceptors.StageConcurrency, concurrency.UnaryServerInterceptor(concurrencyLimiter)).
+		Stream(interceptors.StageConcurrency, concurrency.StreamServerInterceptor(concurrencyLimiter)).
+		Unary(interceptors.StageRequestID, requestid.UnaryServerInterceptor()).
+		Stream(interceptors.StageRequestID, requestid.StreamServerInterceptor()).
+		Unary(interceptors.StageTracing, tracing.UnaryServerInterceptor()).
+		Unary(interceptors.StageTimeout, timeout.UnaryServerInterceptor(timeouts)).
+		Stream(interceptors.StageTimeout, timeout.StreamServerInterceptor(timeouts)).
+		Unary(interceptors.StageLogging, logger.UnaryServerInterceptor(log, cfg.Logger.LogPayloads, cfg.Logger.MaxPayloadBytes)).
+		Stream(interceptors.StageLogging, logger.StreamServerInterceptor(log)).
+		Unary(interceptors.StageMetrics, metrics.UnaryServerInterceptor(grpcMetrics)).
+		Stream(interceptors.StageMetrics, metrics.StreamServerInterceptor(grpcMetrics)).
+		Unary(interceptors.StageDedup, dedup.UnaryServerInterceptor(dedup.NewDeduplicator(cfg.Server.DedupWindow))).
+		Unary(interceptors.StageDatabase, consistency.UnaryServerInterceptor(cfg.Database.ReadYourWritesWindow)).
+		Stream(interceptors.StageDatabase, consistency.StreamServerInterceptor(cfg.Database.ReadYourWritesWindow))
+	authenticator := addAuthInterceptors(chain, cfg.Auth)
+	if authenticator == nil {
+		log.Warn("No JWT secret configured; all callers are anonymous and admin methods are refused")
+	}
+	if limiter != nil {
+		chain.
+			Unary(interceptors.StageRateLimit, ratelimit.UnaryServerInterceptor(limiter, rateLimitMetrics, gatewayToken)).
+			Stream(interceptors.StageRateLimit, ratelimit.StreamServerInterceptor(limiter, rateLimitMetrics, gatewayToken))
+	}
+	if len(cfg.Server.DisabledInterceptors) > 0 {
+		log.Warn("Some gRPC interceptors are disabled", "stages", cfg.Server.DisabledInterceptors)
+	}
 
 	// Create gRPC server
-	grpcServer := grpc.NewServer(
-		grpc.ChainUnaryInterceptor(
-			logger.UnaryServerInterceptor(log),
-			// Add more interceptors here (auth, metrics, etc.)
-		),
+	serverOpts := append(chain.ServerOptions(),
+		grpc.StatsHandler(otelgrpc.NewServerHandler()),
+		grpc.KeepaliveParams(keepalive.ServerParameters{
+			MaxConnectionIdle:     cfg.Server.Keepalive.MaxConnectionIdle,
+			MaxConnectionAge:      cfg.Server.Keepalive.MaxConnectionAge,
+			MaxConnectionAgeGrace: cfg.Server.Keepalive.MaxConnectionAgeGrace,
+			Time:                  cfg.Server.Keepalive.Time,
+			Timeout:               cfg.Server.Keepalive.Timeout,
+		}),
+		grpc.KeepaliveEnforcementPolicy(keepalive.EnforcementPolicy{
+			MinTime:             cfg.Server.Keepalive.MinTime,
+			PermitWithoutStream: cfg.Server.Keepalive.PermitWithoutStream,
+		}),
 	)
+	if cfg.Server.MaxRecvMsgSize > 0 {
+		serverOpts = append(serverOpts, grpc.MaxRecvMsgSize(cfg.Server.MaxRecvMsgSize))
+	}
+	if cfg.Server.MaxSendMsgSize > 0 {
+		serverOpts = append(serverOpts, grpc.MaxSendMsgSize(cfg.Server.MaxSendMsgSize))
+	}
+	gatewayCreds := insecure.NewCredentials()
+	if cfg.Server.TLS.Enabled() {
+		serverTLS, err := tlsconfig.Server(cfg.Server.TLS)
+		if err != nil {
+			log.Fatal("Failed to configure gRPC TLS", "error", err)
+		}
+		loopbackTLS, err := tlsconfig.Loopback(cfg.Server.TLS)
+		if err != nil {
+			log.Fatal("Failed to configure gateway TLS", "error", err)
+		}
+		serverOpts = append(serverOpts, grpc.Creds(credentials.NewTLS(serverTLS)))
+		gatewayCreds = credentials.NewTLS(loopbackTLS)
+		log.Info("gRPC TLS enabled", "mutual_tls", cfg.Server.TLS.ClientCAFile != "")
+	} else {
+		log.Warn("gRPC TLS is not configured, serving plaintext")
+	}
+	grpcServer := grpc.NewServer(serverOpts...)
 
 	// Register services
 	pb.RegisterUserServiceServer(grpcServer, userHandler)
-	grpc_health_v1.RegisterHealthServer(grpcServer, health.NewServer())
+	healthServer := grpchealth.NewServer()
+	grpc_health_v1.RegisterHealthServer(grpcServer, healthServer)
+	if pinger != nil {
+		go watchDatabaseHealth(bgCtx, log, pinger, healthServer, cfg.Server.HealthCheckInterval)
+	} else {
+		healthServer.SetServingStatus("user.v1.UserService", grpc_health_v1.HealthCheckResponse_SERVING)
+	}
 
 	// Register reflection service on gRPC server
 	reflection.Register(grpcServer)
 
 	// Start gRPC server
 	grpcAddr := fmt.Sprintf(":%s", cfg.Server.GRPCPort)
-	lis, err := net.Listen("tcp", grpcAddr)
+	lis, err := listener.Listen(context.Background(), grpcAddr, cfg.Server.ReusePort)
 	if err != nil {
 		log.Fatal("Failed to listen", "error", err, "address", grpcAddr)
 	}
 
-	// Start HTTP server for health checks and metrics
+	// REST gateway that proxies JSON requests to the local gRPC server
+	gateway, err := newGatewayMux(bgCtx, fmt.Sprintf("localhost:%s", cfg.Server.GRPCPort), gatewayCreds, gatewayToken)
+	if err != nil {
+		log.Fatal("Failed to create REST gateway", "error", err)
+	}
+	if limiter != nil {
+		gateway = ratelimit.HTTPMiddleware(limiter, rateLimitMetrics, log, gateway)
+	}
+
+	// Start HTTP server for health checks, metrics, and the REST gateway
 	httpAddr := fmt.Sprintf(":%s", cfg.Server.HTTPPort)
+	httpHandler := setupHTTPHandlers(log, drainState, readiness, gateway, authenticator)
+	if cfg.Server.CORS.Enabled() {
+		httpHandler = cors.Middleware(cfg.Server.CORS, httpHandler)
+		log.Info("CORS enabled", "allowed_origins", cfg.Server.CORS.AllowedOrigins)
+	}
 	httpServer := &http.Server{
 		Addr:         httpAddr,
-		Handler:      setupHTTPHandlers(log),
+		Handler:      httpHandler,
 		ReadTimeout:  15 * time.Second,
 		WriteTimeout: 15 * time.Second,
 		IdleTimeout:  60 * time.Second,
 	}
+	// HTTP TLS is configured separately so e.g. metrics can stay plaintext behind a mesh
+	if cfg.Server.HTTPTLS.Enabled() {
+		httpTLS, err := tlsconfig.Server(cfg.Server.HTTPTLS)
+		if err != nil {
+			log.Fatal("Failed to configure HTTP TLS", "error", err)
+		}
+		httpServer.TLSConfig = httpTLS
+	}
+	// The HTTP port is handed over between processes the same way as the gRPC port
+	httpLis, err := listener.Listen(context.Background(), httpAddr, cfg.Server.ReusePort)
+	if err != nil {
+		log.Fatal("Failed to listen", "error", err, "address", httpAddr)
+	}
 
-	// Channel to listen for errors
-	serverErrors := make(chan error, 1)
+	// Channel to listen for errors; buffered so neither server blocks reporting one
+	serverErrors := make(chan error, 2)
 
 	// Start gRPC server in a goroutine
 	go func() {
@@ -111,8 +447,14 @@ func main() {
 
 	// Start HTTP server in a goroutine
 	go func() {
+		if httpServer.TLSConfig != nil {
+			log.Info("HTTPS server listening", "address", httpAddr)
+			// The certificate is already loaded into TLSConfig
+			serverErrors <- httpServer.ServeTLS(httpLis, "", "")
+			return
+		}
 		log.Info("HTTP server listening", "address", httpAddr)
-		serverErrors <- httpServer.ListenAndServe()
+		serverErrors <- httpServer.Serve(httpLis)
 	}()
 
 	// Channel to listen for interrupt signals
@@ -126,46 +468,158 @@ func main() {
 	case sig := <-shutdown:
 		log.Info("Received shutdown signal", "signal", sig)
 
+		// Stop background tasks and report NOT_SERVING while draining
+		stopBackground()
+		healthServer.Shutdown()
+
 		// Graceful shutdown with timeout
-		ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+		ctx, cancel := context.WithTimeout(context.Background(), cfg.Server.ShutdownTimeout)
 		defer cancel()
 
-		// Shutdown HTTP server
-		if err := httpServer.Shutdown(ctx); err != nil {
-			log.Error("HTTP server shutdown error", "error", err)
-			httpServer.Close()
+		// Drain HTTP and gRPC concurrently under the shared deadline
+		var wg sync.WaitGroup
+		wg.Add(2)
+		go func() {
+			defer wg.Done()
+			start := time.Now()
+			if err := httpServer.Shutdown(ctx); err != nil {
+				log.Error("HTTP server shutdown error", "error", err)
+				httpServer.Close()
+			}
+			log.Info("HTTP server drained", "duration", time.Since(start))
+		}()
+		go func() {
+			defer wg.Done()
+			start := time.Now()
+			stopped := make(chan struct{})
+			go func() {
+				grpcServer.GracefulStop()
+				close(stopped)
+			}()
+			select {
+			case <-stopped:
+			case <-ctx.Done():
+				log.Warn("gRPC graceful stop exceeded deadline, forcing stop")
+				grpcServer.Stop()
+			}
+			log.Info("gRPC server drained", "duration", time.Since(start))
+		}()
+		wg.Wait()
+
+		// Relay events committed while draining, then flush queued events now that no
+		// request can publish more
+		if outboxRelay != nil {
+			if err := outboxRelay.Flush(ctx); err != nil {
+				log.Error("Outbox relay shutdown error", "error", err)
+			}
+		}
+		if kafkaPublisher != nil {
+			if err := kafkaPublisher.Close(); err != nil {
+				log.Error("Event publisher shutdown error", "error", err)
+			}
 		}
 
-		// Gracefully stop gRPC server
-		grpcServer.GracefulStop()
+		// Flush pending spans
+		if err := shutdownTracing(ctx); err != nil {
+			log.Error("Tracing shutdown error", "error", err)
+		}
 
 		log.Info("Server stopped gracefully")
 	}
 }
 
+// watchDatabaseHealth pings the database every interval and reports the user service as
+// NOT_SERVING while it is unreachable. It returns when ctx is cancelled.
+func watchDatabaseHealth(ctx context.Context, log logger.Logger, db database.Pinger, healthServer *grpchealth.Server, interval time.Duration) {
+	const service = "user.v1.UserService"
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	last := grpc_health_v1.HealthCheckResponse_UNKNOWN
+	for {
+		pingCtx, cancel := context.WithTimeout(ctx, interval)
+		err := db.Ping(pingCtx)
+		cancel()
+		if ctx.Err() != nil {
+			return
+		}
+
+		current := grpc_health_v1.HealthCheckResponse_SERVING
+		if err != nil {
+			current = grpc_health_v1.HealthCheckResponse_NOT_SERVING
+		}
+		if current != last {
+			if err != nil {
+				log.Error("Database unreachable, reporting NOT_SERVING", "error", err)
+			} else if last != grpc_health_v1.HealthCheckResponse_UNKNOWN {
+				log.Info("Database reachable again, reporting SERVING")
+			}
+			healthServer.SetServingStatus(service, current)
+			last = current
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
 // setupHTTPHandlers configures HTTP endpoints for health checks and metrics
-func setupHTTPHandlers(log logger.Logger) http.Handler {
+// addAuthInterceptors adds authentication, impersonation and role enforcement to the
+// chain. It returns the authenticator, or nil when no JWT secret is configured.
+func addAuthInterceptors(chain *interceptors.Chain, cfg config.AuthConfig) *auth.Authenticator {
+	impersonator := auth.NewImpersonator(cfg.Impersonation.Enabled, cfg.Impersonation.AllowedMethods)
+	chain.
+		Unary(interceptors.StageImpersonation, auth.ImpersonationInterceptor(impersonator)).
+		Stream(interceptors.StageImpersonation, auth.ImpersonationStreamInterceptor(impersonator))
+
+	var authenticator *auth.Authenticator
+	if cfg.JWT.Enabled() {
+		authenticator = auth.NewAuthenticator(cfg.JWT.Secret, cfg.JWT.Issuer)
+		chain.
+			Unary(interceptors.StageAuthn, auth.AuthenticationInterceptor(authenticator)).
+			Stream(interceptors.StageAuthn, auth.AuthenticationStreamInterceptor(authenticator))
+	}
+	if cfg.Roles.Enforce {
+		chain.
+			Unary(interceptors.StageAuth, auth.RequireRole(auth.RoleAdmin, cfg.Roles.AdminMethods...)).
+			Stream(interceptors.StageAuth, auth.RequireRoleStream(auth.RoleAdmin, cfg.Roles.AdminMethods...))
+	}
+	return authenticator
+}
+
+func setupHTTPHandlers(log logger.Logger, drainState *drain.State, readiness *health.Checker, gateway http.Handler, authenticator *auth.Authenticator) http.Handler {
 	mux := http.NewServeMux()
 
-	// Health check endpoint
-	mux.HandleFunc("/health", func(w http.ResponseWriter, r *http.Request) {
-		w.WriteHeader(http.StatusOK)
-		w.Write([]byte(`{"status":"healthy"}`))
-	})
+	// REST API served by grpc-gateway
+	mux.Handle("/api/", gateway)
+
+	// Liveness: the process is up
+	mux.Handle("/livez", health.LivezHandler())
+
+	// Readiness: every registered dependency check passes
+	mux.Handle("/readyz", readiness.ReadyzHandler())
+
+	// Drain endpoint: flips readiness and acknowledges with the in-flight count. It shares
+	// the public listener, so only admins may call it.
+	mux.Handle("/drain", auth.RequireRoleHTTP(authenticator, auth.RoleAdmin, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			w.WriteHeader(http.StatusMethodNotAllowed)
+			return
+		}
+		inFlight := drainState.SetDraining()
+		log.Info("Drain requested", "in_flight", inFlight)
 
-	// Readiness check endpoint
-	mux.HandleFunc("/ready", func(w http.ResponseWriter, r *http.Request) {
-		// Add your readiness logic here (e.g., check database connection)
+		w.Header().Set("Content-Type", "application/json")
 		w.WriteHeader(http.StatusOK)
-		w.Write([]byte(`{"status":"ready"}`))
-	})
+		fmt.Fprintf(w, `{"draining":%t,"in_flight":%d}`, drainState.Draining(), inFlight)
+	})))
 
 	// Metrics endpoint (for Prometheus)
-	mux.HandleFunc("/metrics", func(w http.ResponseWriter, r *http.Request) {
-		// Prometheus metrics would be exposed here
-		w.WriteHeader(http.StatusOK)
-		w.Write([]byte("# Metrics endpoint\n"))
-	})
+	mux.Handle("/metrics", promhttp.Handler())
 
 	// Version info endpoint
 	mux.HandleFunc("/version", func(w http.ResponseWriter, r *http.Request) {
@@ -177,3 +631,44 @@ func setupHTTPHandlers(log logger.Logger) http.Handler {
 
 	return mux
 }
+
+// newGatewayMux creates a grpc-gateway mux that forwards REST calls to the gRPC server at grpcAddr
+// using creds. gRPC status codes are translated to HTTP status codes by the gateway's default error handler.
+// Every proxied call carries gatewayToken, so the rate limiter can tell it from direct calls.
+func newGatewayMux(ctx context.Context, grpcAddr string, creds credentials.TransportCredentials, gatewayToken string) (http.Handler, error) {
+	mux := runtime.NewServeMux(
+		runtime.WithIncomingHeaderMatcher(gatewayHeaderMatcher),
+		runtime.WithMetadata(func(context.Context, *http.Request) metadata.MD {
+			return metadata.Pairs(ratelimit.GatewayMetadataKey, gatewayToken)
+		}),
+	)
+	opts := []grpc.DialOption{grpc.WithTransportCredentials(creds)}
+	if err := pb.RegisterUserServiceHandlerFromEndpoint(ctx, mux, grpcAddr, opts); err != nil {
+		return nil, err
+	}
+	return mux, nil
+}
+
+// gatewayHeaderMatcher forwards auth, request ID and trace context headers as plain metadata so the
+// interceptors see them exactly as they would on a direct gRPC call
+func gatewayHeaderMatcher(key string) (string, bool) {
+	switch strings.ToLower(key) {
+	case "authorization":
+		return "authorization", true
+	case requestid.MetadataKey:
+		return requestid.MetadataKey, true
+	case consistency.MetadataKey:
+		return consistency.MetadataKey, true
+	case dedup.MetadataKey:
+		return dedup.MetadataKey, true
+	case tracing.TraceparentKey, tracing.TracestateKey:
+		return strings.ToLower(key), true
+	default:
+		// Clients must not be able to present a gateway token of their own
+		name, ok := runtime.DefaultHeaderMatcher(key)
+		if ok && strings.EqualFold(name, ratelimit.GatewayMetadataKey) {
+			return "", false
+		}
+		return name, ok
+	}
+}
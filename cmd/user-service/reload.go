@@ -0,0 +1,102 @@
+package main
+
+import (
+	"context"
+	"os"
+	"os/signal"
+	"reflect"
+	"sync"
+	"syscall"
+
+	"github.com/golang-standards/project-layout/internal/pkg/config"
+	"github.com/golang-standards/project-layout/internal/pkg/logger"
+	"github.com/golang-standards/project-layout/internal/pkg/ratelimit"
+	"github.com/golang-standards/project-layout/internal/pkg/timeout"
+)
+
+// setRuntimeDefaults fills settings derived at runtime rather than from config files
+func setRuntimeDefaults(cfg *config.Config) {
+	if cfg.Database.ApplicationName == "" {
+		cfg.Database.ApplicationName = "user-service-" + Version
+	}
+}
+
+// liveConfig applies the reloadable parts of a new configuration to the running server:
+// the log level, rate limit rate and burst, and request timeouts
+type liveConfig struct {
+	log      logger.Logger
+	limiter  ratelimit.Store // nil when rate limiting is disabled
+	timeouts *timeout.Policy
+
+	mu sync.Mutex
+	// started is the configuration the server was started with, which settings that
+	// need a restart are compared against
+	started *config.Config
+	current *config.Config
+}
+
+func newLiveConfig(log logger.Logger, cfg *config.Config, limiter ratelimit.Store, timeouts *timeout.Policy) *liveConfig {
+	return &liveConfig{
+		log:      log,
+		limiter:  limiter,
+		timeouts: timeouts,
+		started:  cfg,
+		current:  cfg,
+	}
+}
+
+// reloadOnSignal reloads the configuration on SIGHUP until ctx is cancelled
+func (l *liveConfig) reloadOnSignal(ctx context.Context) {
+	hup := make(chan os.Signal, 1)
+	signal.Notify(hup, syscall.SIGHUP)
+	defer signal.Stop(hup)
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-hup:
+			l.log.Info("Reloading configuration on SIGHUP")
+			l.apply(config.Reload())
+		}
+	}
+}
+
+// apply applies newCfg, or logs err and keeps the current configuration
+func (l *liveConfig) apply(newCfg *config.Config, err error) {
+	if err != nil {
+		l.log.Error("Ignoring invalid configuration change", "error", err)
+		return
+	}
+	setRuntimeDefaults(newCfg)
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	old := l.current
+
+	if newCfg.Logger.Level != old.Logger.Level {
+		if err := l.log.SetLevel(newCfg.Logger.Level); err != nil {
+			l.log.Error("Failed to change log level", "error", err)
+			newCfg.Logger.Level = old.Logger.Level
+		} else {
+			l.log.Info("Log level changed", "from", old.Logger.Level, "to", newCfg.Logger.Level)
+		}
+	}
+
+	if l.limiter != nil && (newCfg.RateLimit.Rate != old.RateLimit.Rate || newCfg.RateLimit.Burst != old.RateLimit.Burst) {
+		l.limiter.SetLimits(newCfg.RateLimit.Rate, newCfg.RateLimit.Burst)
+		l.log.Info("Rate limits changed", "rate", newCfg.RateLimit.Rate, "burst", newCfg.RateLimit.Burst)
+	}
+
+	if newCfg.Server.DefaultTimeout != old.Server.DefaultTimeout ||
+		!reflect.DeepEqual(newCfg.Server.MethodTimeouts, old.Server.MethodTimeouts) {
+		l.timeouts.Set(newCfg.Server.DefaultTimeout, newCfg.Server.MethodTimeouts)
+		l.log.Info("Request timeouts changed", "default_timeout", newCfg.Server.DefaultTimeout,
+			"method_timeouts", newCfg.Server.MethodTimeouts)
+	}
+
+	if sections := config.RestartRequired(l.started, newCfg); len(sections) > 0 {
+		l.log.Warn("Configuration changes require a restart to take effect", "sections", sections)
+	}
+	l.current = newCfg
+}
@@ -0,0 +1,50 @@
+package handler
+
+import (
+	pb "github.com/golang-standards/project-layout/pkg/api/user/v1"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/types/known/fieldmaskpb"
+)
+
+// readMask is the set of User fields a read_mask selects; nil selects every field
+type readMask map[protoreflect.Name]bool
+
+// parseReadMask validates a read_mask against the User message. Paths may use proto or
+// JSON names; an empty or absent mask returns all fields.
+func parseReadMask(mask *fieldmaskpb.FieldMask) (readMask, error) {
+	if len(mask.GetPaths()) == 0 {
+		return nil, nil
+	}
+
+	fields := (&pb.User{}).ProtoReflect().Descriptor().Fields()
+	selected := make(readMask, len(mask.GetPaths()))
+	for _, path := range mask.GetPaths() {
+		field := fields.ByName(protoreflect.Name(path))
+		if field == nil {
+			field = fields.ByJSONName(path)
+		}
+		if field == nil {
+			return nil, status.Errorf(codes.InvalidArgument, "read_mask: unknown field %q", path)
+		}
+		selected[field.Name()] = true
+	}
+	return selected, nil
+}
+
+// apply clears the fields of user the mask doesn't select
+func (m readMask) apply(user *pb.User) *pb.User {
+	if m == nil {
+		return user
+	}
+
+	msg := user.ProtoReflect()
+	msg.Range(func(field protoreflect.FieldDescriptor, _ protoreflect.Value) bool {
+		if !m[field.Name()] {
+			msg.Clear(field)
+		}
+		return true
+	})
+	return user
+}
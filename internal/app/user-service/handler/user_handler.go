@@ -3,30 +3,55 @@ package handler
 import (
 	"context"
 	"errors"
+	"fmt"
+	"time"
 
 	"github.com/golang-standards/project-layout/internal/app/user-service/model"
 	"github.com/golang-standards/project-layout/internal/app/user-service/repository"
 	"github.com/golang-standards/project-layout/internal/app/user-service/service"
+	"github.com/golang-standards/project-layout/internal/pkg/apperror"
+	"github.com/golang-standards/project-layout/internal/pkg/auth"
 	"github.com/golang-standards/project-layout/internal/pkg/logger"
 	pb "github.com/golang-standards/project-layout/pkg/api/user/v1"
+	"github.com/golang-standards/project-layout/pkg/pagination"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/status"
 	"google.golang.org/protobuf/types/known/emptypb"
 	"google.golang.org/protobuf/types/known/timestamppb"
 )
 
+// Options holds optional settings for the user handler
+type Options struct {
+	// MaskContactFields redacts email and phone of other users in list, stream and batch get responses for non-admin callers
+	MaskContactFields bool
+	// Build identifies the running binary in GetVersion responses
+	Build BuildInfo
+}
+
+// BuildInfo describes the running build, as set at link time
+type BuildInfo struct {
+	Version   string
+	BuildTime string
+	GitCommit string
+}
+
 // UserHandler implements the gRPC user service
 type UserHandler struct {
 	pb.UnimplementedUserServiceServer
-	service service.UserService
-	logger  logger.Logger
+	service           service.UserService
+	logger            logger.Logger
+	maskContactFields bool
+	build             BuildInfo
 }
 
 // NewUserHandler creates a new user handler
-func NewUserHandler(service service.UserService, logger logger.Logger) *UserHandler {
+func NewUserHandler(service service.UserService, logger logger.Logger, opts Options) *UserHandler {
 	return &UserHandler{
-		service: service,
-		logger:  logger,
+		service:           service,
+		logger:            logger,
+		maskContactFields: opts.MaskContactFields,
+		build:             opts.Build,
 	}
 }
 
@@ -36,11 +61,7 @@ func (h *UserHandler) CreateUser(ctx context.Context, req *pb.CreateUserRequest)
 
 	user, err := h.service.CreateUser(ctx, req.Email, req.Password, req.FirstName, req.LastName, req.Phone)
 	if err != nil {
-		if errors.Is(err, repository.ErrUserAlreadyExists) {
-			return nil, status.Error(codes.AlreadyExists, "user already exists")
-		}
-		h.logger.Error("Failed to create user", "error", err)
-		return nil, status.Error(codes.Internal, "failed to create user")
+		return nil, h.grpcError(ctx, err, "create user")
 	}
 
 	return &pb.CreateUserResponse{
@@ -48,17 +69,83 @@ func (h *UserHandler) CreateUser(ctx context.Context, req *pb.CreateUserRequest)
 	}, nil
 }
 
+// CreateUsersBatch creates many users in one transaction. Each row costs a bcrypt hash, so
+// only admins may call it, even when role enforcement is turned off.
+func (h *UserHandler) CreateUsersBatch(ctx context.Context, req *pb.CreateUsersBatchRequest) (*pb.CreateUsersBatchResponse, error) {
+	h.logger.Info("CreateUsersBatch request received", "count", len(req.Users))
+
+	if principal, _ := auth.PrincipalFromContext(ctx); !principal.IsAdmin() {
+		return nil, status.Error(codes.PermissionDenied, "creating users in batch requires admin role")
+	}
+
+	inputs := make([]service.CreateUserInput, len(req.Users))
+	for i, u := range req.Users {
+		inputs[i] = service.CreateUserInput{
+			Email:     u.Email,
+			Password:  u.Password,
+			FirstName: u.FirstName,
+			LastName:  u.LastName,
+			Phone:     u.Phone,
+		}
+	}
+
+	users, err := h.service.CreateUsersBatch(ctx, inputs)
+	if err != nil {
+		var batchErr *service.BatchValidationError
+		if errors.As(err, &batchErr) {
+			return nil, batchValidationStatus(batchErr)
+		}
+		if errors.Is(err, repository.ErrUserAlreadyExists) {
+			return nil, status.Error(codes.AlreadyExists, "one or more users already exist")
+		}
+		return nil, h.grpcError(ctx, err, "create users")
+	}
+
+	pbUsers := make([]*pb.User, len(users))
+	for i, user := range users {
+		pbUsers[i] = h.modelToProto(user)
+	}
+
+	return &pb.CreateUsersBatchResponse{
+		Users: pbUsers,
+	}, nil
+}
+
 // GetUser retrieves a user by ID
 func (h *UserHandler) GetUser(ctx context.Context, req *pb.GetUserRequest) (*pb.GetUserResponse, error) {
 	h.logger.Debug("GetUser request received", "user_id", req.Id)
 
+	mask, err := parseReadMask(req.ReadMask)
+	if err != nil {
+		return nil, err
+	}
+
 	user, err := h.service.GetUser(ctx, req.Id)
 	if err != nil {
+		return nil, h.grpcError(ctx, err, "get user")
+	}
+
+	return &pb.GetUserResponse{
+		User: mask.apply(h.modelToProto(user)),
+	}, nil
+}
+
+// GetMe retrieves the authenticated caller's own user
+func (h *UserHandler) GetMe(ctx context.Context, _ *emptypb.Empty) (*pb.GetUserResponse, error) {
+	userID, ok := auth.UserIDFromContext(ctx)
+	if !ok {
+		return nil, status.Error(codes.Unauthenticated, "no authenticated user")
+	}
+
+	h.logger.Debug("GetMe request received", "user_id", userID)
+
+	user, err := h.service.GetUser(ctx, userID)
+	if err != nil {
+		// The token outlived its user, e.g. after deletion
 		if errors.Is(err, repository.ErrUserNotFound) {
-			return nil, status.Error(codes.NotFound, "user not found")
+			return nil, status.Error(codes.Unauthenticated, "authenticated user no longer exists")
 		}
-		h.logger.Error("Failed to get user", "error", err)
-		return nil, status.Error(codes.Internal, "failed to get user")
+		return nil, h.grpcError(ctx, err, "get current user")
 	}
 
 	return &pb.GetUserResponse{
@@ -66,17 +153,54 @@ func (h *UserHandler) GetUser(ctx context.Context, req *pb.GetUserRequest) (*pb.
 	}, nil
 }
 
+// BatchGetUsers retrieves multiple users by ID
+func (h *UserHandler) BatchGetUsers(ctx context.Context, req *pb.BatchGetUsersRequest) (*pb.BatchGetUsersResponse, error) {
+	h.logger.Debug("BatchGetUsers request received", "count", len(req.Ids))
+
+	result, err := h.service.BatchGetUsers(ctx, req.Ids)
+	if err != nil {
+		return nil, h.grpcError(ctx, err, "get users")
+	}
+
+	pbUsers := make([]*pb.User, len(result.Users))
+	for i, user := range result.Users {
+		pbUsers[i] = h.listView(ctx, user)
+	}
+
+	notFound := make(map[string]bool, len(result.NotFound))
+	for _, id := range result.NotFound {
+		notFound[id] = true
+	}
+	var pbErrors []*pb.BatchGetError
+	reported := make(map[string]bool)
+	for _, id := range req.Ids {
+		err, failed := result.Failed[id]
+		if reported[id] || (!failed && !notFound[id]) {
+			continue
+		}
+		reported[id] = true
+		if failed {
+			h.logger.Error("Failed to get user in batch", "error", err, "user_id", id)
+		} else {
+			err = repository.ErrUserNotFound
+		}
+		pbErrors = append(pbErrors, batchGetError(id, err))
+	}
+
+	return &pb.BatchGetUsersResponse{
+		Users:       pbUsers,
+		NotFoundIds: result.NotFound,
+		Errors:      pbErrors,
+	}, nil
+}
+
 // GetUserByEmail retrieves a user by email
 func (h *UserHandler) GetUserByEmail(ctx context.Context, req *pb.GetUserByEmailRequest) (*pb.GetUserResponse, error) {
 	h.logger.Debug("GetUserByEmail request received", "email", req.Email)
 
 	user, err := h.service.GetUserByEmail(ctx, req.Email)
 	if err != nil {
-		if errors.Is(err, repository.ErrUserNotFound) {
-			return nil, status.Error(codes.NotFound, "user not found")
-		}
-		h.logger.Error("Failed to get user by email", "error", err)
-		return nil, status.Error(codes.Internal, "failed to get user")
+		return nil, h.grpcError(ctx, err, "get user by email")
 	}
 
 	return &pb.GetUserResponse{
@@ -88,30 +212,21 @@ func (h *UserHandler) GetUserByEmail(ctx context.Context, req *pb.GetUserByEmail
 func (h *UserHandler) UpdateUser(ctx context.Context, req *pb.UpdateUserRequest) (*pb.UpdateUserResponse, error) {
 	h.logger.Info("UpdateUser request received", "user_id", req.Id)
 
-	updates := make(map[string]interface{})
-	if req.Email != nil {
-		updates["email"] = *req.Email
-	}
-	if req.FirstName != nil {
-		updates["first_name"] = *req.FirstName
-	}
-	if req.LastName != nil {
-		updates["last_name"] = *req.LastName
-	}
-	if req.Phone != nil {
-		updates["phone"] = *req.Phone
+	updates := h.presentUpdates(req)
+	if req.UpdateMask != nil {
+		masked, err := h.maskedUpdates(req)
+		if err != nil {
+			return nil, err
+		}
+		updates = masked
 	}
-	if req.Status != nil {
-		updates["status"] = h.protoStatusToModel(*req.Status)
+	if req.Version != nil {
+		updates["version"] = *req.Version
 	}
 
 	user, err := h.service.UpdateUser(ctx, req.Id, updates)
 	if err != nil {
-		if errors.Is(err, repository.ErrUserNotFound) {
-			return nil, status.Error(codes.NotFound, "user not found")
-		}
-		h.logger.Error("Failed to update user", "error", err)
-		return nil, status.Error(codes.Internal, "failed to update user")
+		return nil, h.grpcError(ctx, err, "update user")
 	}
 
 	return &pb.UpdateUserResponse{
@@ -121,64 +236,511 @@ func (h *UserHandler) UpdateUser(ctx context.Context, req *pb.UpdateUserRequest)
 
 // DeleteUser deletes a user
 func (h *UserHandler) DeleteUser(ctx context.Context, req *pb.DeleteUserRequest) (*emptypb.Empty, error) {
-	h.logger.Info("DeleteUser request received", "user_id", req.Id)
+	h.logger.Info("DeleteUser request received", "user_id", req.Id, "hard", req.Hard)
 
-	if err := h.service.DeleteUser(ctx, req.Id); err != nil {
-		if errors.Is(err, repository.ErrUserNotFound) {
-			return nil, status.Error(codes.NotFound, "user not found")
+	if req.Hard {
+		principal, _ := auth.PrincipalFromContext(ctx)
+		if !principal.IsAdmin() {
+			return nil, status.Error(codes.PermissionDenied, "hard delete requires admin role")
+		}
+		if err := h.service.PurgeUser(ctx, req.Id); err != nil {
+			return nil, h.grpcError(ctx, err, "purge user")
 		}
-		h.logger.Error("Failed to delete user", "error", err)
-		return nil, status.Error(codes.Internal, "failed to delete user")
+		return &emptypb.Empty{}, nil
+	}
+
+	if err := h.service.DeleteUser(ctx, req.Id, req.GetDeletionReason()); err != nil {
+		return nil, h.grpcError(ctx, err, "delete user")
 	}
 
 	return &emptypb.Empty{}, nil
 }
 
+// RestoreUser restores a soft-deleted user
+func (h *UserHandler) RestoreUser(ctx context.Context, req *pb.RestoreUserRequest) (*pb.RestoreUserResponse, error) {
+	h.logger.Info("RestoreUser request received", "user_id", req.Id)
+
+	user, err := h.service.RestoreUser(ctx, req.Id)
+	if err != nil {
+		if errors.Is(err, repository.ErrUserNotFound) {
+			return nil, status.Error(codes.NotFound, "deleted user not found")
+		}
+		if errors.Is(err, repository.ErrUserAlreadyExists) {
+			return nil, status.Error(codes.AlreadyExists, "an active user with this email already exists")
+		}
+		return nil, h.grpcError(ctx, err, "restore user")
+	}
+
+	return &pb.RestoreUserResponse{
+		User: h.modelToProto(user),
+	}, nil
+}
+
 // ListUsers retrieves a paginated list of users
 func (h *UserHandler) ListUsers(ctx context.Context, req *pb.ListUsersRequest) (*pb.ListUsersResponse, error) {
 	h.logger.Debug("ListUsers request received", "page", req.Page, "page_size", req.PageSize)
 
-	page := int(req.Page)
-	pageSize := int(req.PageSize)
+	filter, err := h.listFilter(ctx, req.Filter, req.Status, req.IncludeDeleted)
+	if err != nil {
+		return nil, err
+	}
+	if err := setDateRanges(req, &filter); err != nil {
+		return nil, err
+	}
+	mask, err := parseReadMask(req.ReadMask)
+	if err != nil {
+		return nil, err
+	}
+
+	if req.Cursor != nil {
+		return h.listUsersCursor(ctx, req, filter, mask)
+	}
+
+	params := pagination.NewParams(int(req.Page), int(req.PageSize))
+	users, total, err := h.service.ListUsers(ctx, params, filter, h.sortKeys(req))
+	if err != nil {
+		return nil, h.grpcError(ctx, err, "list users")
+	}
+
+	pbUsers := make([]*pb.User, len(users))
+	for i, user := range users {
+		pbUsers[i] = mask.apply(h.listView(ctx, user))
+	}
+
+	result := pagination.NewResult(params, total)
+	return &pb.ListUsersResponse{
+		Users:      pbUsers,
+		Total:      int32(result.Total),
+		Page:       int32(result.Page),
+		PageSize:   int32(result.PageSize),
+		HasMore:    result.HasMore,
+		TotalPages: int32(result.TotalPages),
+	}, nil
+}
+
+// CountUsers counts the users matching the filters
+func (h *UserHandler) CountUsers(ctx context.Context, req *pb.CountUsersRequest) (*pb.CountUsersResponse, error) {
+	h.logger.Debug("CountUsers request received")
+
+	filter, err := h.listFilter(ctx, req.Filter, req.Status, req.IncludeDeleted)
+	if err != nil {
+		return nil, err
+	}
+	if err := setDateRanges(req, &filter); err != nil {
+		return nil, err
+	}
+
+	count, err := h.service.CountUsers(ctx, filter)
+	if err != nil {
+		return nil, h.grpcError(ctx, err, "count users")
+	}
+
+	return &pb.CountUsersResponse{
+		Count: count,
+	}, nil
+}
+
+// StreamUsers streams every user matching the filter
+func (h *UserHandler) StreamUsers(req *pb.StreamUsersRequest, stream pb.UserService_StreamUsersServer) error {
+	ctx := stream.Context()
+	h.logger.Info("StreamUsers request received", "batch_size", req.BatchSize)
+
+	filter, err := h.listFilter(ctx, req.Filter, req.Status, req.IncludeDeleted)
+	if err != nil {
+		return err
+	}
+
+	err = h.service.StreamUsers(ctx, filter, int(req.BatchSize), func(user *model.User) error {
+		return stream.Send(h.listView(ctx, user))
+	})
+	if err != nil {
+		return h.grpcError(ctx, err, "stream users")
+	}
+
+	return nil
+}
+
+// ChangePassword changes a user's password
+func (h *UserHandler) ChangePassword(ctx context.Context, req *pb.ChangePasswordRequest) (*emptypb.Empty, error) {
+	h.logger.Info("ChangePassword request received", "user_id", req.Id)
+
+	if err := h.service.ChangePassword(ctx, req.Id, req.CurrentPassword, req.NewPassword); err != nil {
+		return nil, h.grpcError(ctx, err, "change password")
+	}
+
+	return &emptypb.Empty{}, nil
+}
+
+// RequestPasswordReset issues a password reset token
+func (h *UserHandler) RequestPasswordReset(ctx context.Context, req *pb.RequestPasswordResetRequest) (*emptypb.Empty, error) {
+	h.logger.Info("RequestPasswordReset request received")
+
+	if err := h.service.RequestPasswordReset(ctx, req.Email); err != nil {
+		return nil, h.grpcError(ctx, err, "request password reset")
+	}
+
+	return &emptypb.Empty{}, nil
+}
+
+// ResetPassword sets a new password using a reset token
+func (h *UserHandler) ResetPassword(ctx context.Context, req *pb.ResetPasswordRequest) (*emptypb.Empty, error) {
+	h.logger.Info("ResetPassword request received")
+
+	if err := h.service.ResetPassword(ctx, req.Token, req.NewPassword); err != nil {
+		return nil, h.grpcError(ctx, err, "reset password")
+	}
+
+	return &emptypb.Empty{}, nil
+}
+
+// VerifyEmail verifies a user's email address using a verification token
+func (h *UserHandler) VerifyEmail(ctx context.Context, req *pb.VerifyEmailRequest) (*pb.VerifyEmailResponse, error) {
+	h.logger.Info("VerifyEmail request received")
+
+	user, err := h.service.VerifyEmail(ctx, req.Token)
+	if err != nil {
+		return nil, h.grpcError(ctx, err, "verify email")
+	}
+
+	return &pb.VerifyEmailResponse{
+		User: h.modelToProto(user),
+	}, nil
+}
+
+// ValidateCredentials checks an email and password
+func (h *UserHandler) ValidateCredentials(ctx context.Context, req *pb.ValidateCredentialsRequest) (*pb.ValidateCredentialsResponse, error) {
+	h.logger.Info("ValidateCredentials request received")
+
+	user, err := h.service.ValidatePassword(ctx, req.Email, req.Password)
+	if err != nil {
+		if errors.Is(err, repository.ErrUserNotFound) || errors.Is(err, service.ErrInvalidPassword) {
+			err = service.ErrInvalidCredentials
+		}
+		return nil, h.grpcError(ctx, err, "validate credentials")
+	}
+
+	return &pb.ValidateCredentialsResponse{
+		User: h.modelToProto(user),
+	}, nil
+}
+
+// ResendVerification sends a new email verification token
+func (h *UserHandler) ResendVerification(ctx context.Context, req *pb.ResendVerificationRequest) (*emptypb.Empty, error) {
+	h.logger.Info("ResendVerification request received")
+
+	if err := h.service.ResendVerification(ctx, req.Email); err != nil {
+		return nil, h.grpcError(ctx, err, "resend verification")
+	}
+
+	return &emptypb.Empty{}, nil
+}
+
+// UnlockUser clears a user's login lockout
+func (h *UserHandler) UnlockUser(ctx context.Context, req *pb.UnlockUserRequest) (*emptypb.Empty, error) {
+	h.logger.Info("UnlockUser request received", "user_id", req.Id)
+
+	if principal, _ := auth.PrincipalFromContext(ctx); !principal.IsAdmin() {
+		return nil, status.Error(codes.PermissionDenied, "unlocking users requires admin role")
+	}
+
+	if err := h.service.UnlockUser(ctx, req.Id); err != nil {
+		return nil, h.grpcError(ctx, err, "unlock user")
+	}
+
+	return &emptypb.Empty{}, nil
+}
+
+// SetUserStatus changes only a user's status
+func (h *UserHandler) SetUserStatus(ctx context.Context, req *pb.SetUserStatusRequest) (*pb.SetUserStatusResponse, error) {
+	h.logger.Info("SetUserStatus request received", "user_id", req.Id, "status", req.Status)
+
+	if req.Status == pb.UserStatus_USER_STATUS_UNSPECIFIED {
+		return nil, status.Error(codes.InvalidArgument, "status is required")
+	}
+	newStatus := h.protoStatusToModel(req.Status)
+	if h.modelStatusToProto(newStatus) != req.Status {
+		return nil, status.Errorf(codes.InvalidArgument, "unknown status %v", req.Status)
+	}
+
+	user, err := h.service.SetUserStatus(ctx, req.Id, newStatus)
+	if err != nil {
+		return nil, h.grpcError(ctx, err, "set user status")
+	}
+
+	return &pb.SetUserStatusResponse{
+		User: h.modelToProto(user),
+	}, nil
+}
+
+// DeactivateUser makes a user inactive without deleting them
+func (h *UserHandler) DeactivateUser(ctx context.Context, req *pb.DeactivateUserRequest) (*pb.DeactivateUserResponse, error) {
+	h.logger.Info("DeactivateUser request received", "user_id", req.Id)
+
+	user, err := h.service.DeactivateUser(ctx, req.Id)
+	if err != nil {
+		return nil, h.grpcError(ctx, err, "deactivate user")
+	}
+
+	return &pb.DeactivateUserResponse{
+		User: h.modelToProto(user),
+	}, nil
+}
+
+// ReactivateUser makes an inactive user active again
+func (h *UserHandler) ReactivateUser(ctx context.Context, req *pb.ReactivateUserRequest) (*pb.ReactivateUserResponse, error) {
+	h.logger.Info("ReactivateUser request received", "user_id", req.Id)
+
+	user, err := h.service.ReactivateUser(ctx, req.Id)
+	if err != nil {
+		return nil, h.grpcError(ctx, err, "reactivate user")
+	}
+
+	return &pb.ReactivateUserResponse{
+		User: h.modelToProto(user),
+	}, nil
+}
+
+// AnonymizeUser replaces a user's personal data with tombstone values
+func (h *UserHandler) AnonymizeUser(ctx context.Context, req *pb.AnonymizeUserRequest) (*pb.AnonymizeUserResponse, error) {
+	h.logger.Info("AnonymizeUser request received", "user_id", req.Id)
+
+	user, err := h.service.AnonymizeUser(ctx, req.Id)
+	if err != nil {
+		return nil, h.grpcError(ctx, err, "anonymize user")
+	}
+
+	return &pb.AnonymizeUserResponse{
+		User: h.modelToProto(user),
+	}, nil
+}
+
+// GetVersion reports the build of the running server
+func (h *UserHandler) GetVersion(ctx context.Context, _ *emptypb.Empty) (*pb.GetVersionResponse, error) {
+	return &pb.GetVersionResponse{
+		Version:   h.build.Version,
+		BuildTime: h.build.BuildTime,
+		GitCommit: h.build.GitCommit,
+	}, nil
+}
+
+// grpcError translates a service error into a gRPC error via apperror. Unexpected errors
+// are logged and reported as "failed to <action>" so internals don't reach the client.
+func (h *UserHandler) grpcError(ctx context.Context, err error, action string) error {
+	if ctxErr := ctx.Err(); ctxErr != nil {
+		return status.FromContextError(ctxErr).Err()
+	}
+	st := apperror.ToGRPCStatus(err)
+	if st.Code() == codes.Internal {
+		h.logger.Error("Failed to "+action, "error", err)
+		return status.Error(codes.Internal, "failed to "+action)
+	}
+	return st.Err()
+}
+
+// updatableFields are the update_mask paths UpdateUser accepts
+var updatableFields = map[string]bool{
+	"email":      true,
+	"first_name": true,
+	"last_name":  true,
+	"phone":      true,
+	"status":     true,
+}
+
+// presentUpdates collects the fields set on the request
+func (h *UserHandler) presentUpdates(req *pb.UpdateUserRequest) map[string]interface{} {
+	updates := make(map[string]interface{})
+	if req.Email != nil {
+		updates["email"] = *req.Email
+	}
+	if req.FirstName != nil {
+		updates["first_name"] = *req.FirstName
+	}
+	if req.LastName != nil {
+		updates["last_name"] = *req.LastName
+	}
+	if req.Phone != nil {
+		updates["phone"] = *req.Phone
+	}
+	if req.Status != nil {
+		updates["status"] = h.protoStatusToModel(*req.Status)
+	}
+	return updates
+}
+
+// maskedUpdates collects exactly the fields named in the update mask; unset fields clear the value
+func (h *UserHandler) maskedUpdates(req *pb.UpdateUserRequest) (map[string]interface{}, error) {
+	if len(req.UpdateMask.Paths) == 0 {
+		return nil, status.Error(codes.InvalidArgument, "update_mask must name at least one field")
+	}
 
-	if page < 1 {
-		page = 1
+	updates := make(map[string]interface{}, len(req.UpdateMask.Paths))
+	for _, path := range req.UpdateMask.Paths {
+		path = maskFieldName(req, path)
+		if !updatableFields[path] {
+			return nil, status.Errorf(codes.InvalidArgument, "update_mask: unknown or immutable field %q", path)
+		}
+		if _, ok := updates[path]; ok {
+			return nil, status.Errorf(codes.InvalidArgument, "update_mask: duplicate field %q", path)
+		}
+
+		switch path {
+		case "email":
+			updates[path] = req.GetEmail()
+		case "first_name":
+			updates[path] = req.GetFirstName()
+		case "last_name":
+			updates[path] = req.GetLastName()
+		case "phone":
+			updates[path] = req.GetPhone()
+		case "status":
+			if req.GetStatus() == pb.UserStatus_USER_STATUS_UNSPECIFIED {
+				return nil, status.Error(codes.InvalidArgument, "update_mask: status cannot be cleared")
+			}
+			updates[path] = h.protoStatusToModel(req.GetStatus())
+		}
 	}
-	if pageSize < 1 {
-		pageSize = 10
+	return updates, nil
+}
+
+// maskFieldName maps a mask path given as a JSON name, as REST clients send it
+// (e.g. firstName), to the proto field name; other paths are returned unchanged
+func maskFieldName(req *pb.UpdateUserRequest, path string) string {
+	if field := req.ProtoReflect().Descriptor().Fields().ByJSONName(path); field != nil {
+		return string(field.Name())
 	}
+	return path
+}
 
-	users, total, err := h.service.ListUsers(ctx, page, pageSize, req.Filter)
+// listUsersCursor serves ListUsers using keyset pagination
+func (h *UserHandler) listUsersCursor(ctx context.Context, req *pb.ListUsersRequest, filter repository.ListFilter, mask readMask) (*pb.ListUsersResponse, error) {
+	users, nextCursor, err := h.service.ListUsersCursor(ctx, req.GetCursor(), int(req.PageSize), filter)
 	if err != nil {
-		h.logger.Error("Failed to list users", "error", err)
-		return nil, status.Error(codes.Internal, "failed to list users")
+		return nil, h.grpcError(ctx, err, "list users")
 	}
 
 	pbUsers := make([]*pb.User, len(users))
 	for i, user := range users {
-		pbUsers[i] = h.modelToProto(user)
+		pbUsers[i] = mask.apply(h.listView(ctx, user))
 	}
 
 	return &pb.ListUsersResponse{
-		Users:    pbUsers,
-		Total:    int32(total),
-		Page:     int32(page),
-		PageSize: int32(pageSize),
+		Users:      pbUsers,
+		PageSize:   int32(len(users)),
+		NextCursor: nextCursor,
+		HasMore:    nextCursor != "",
 	}, nil
 }
 
 // modelToProto converts model.User to pb.User
 func (h *UserHandler) modelToProto(user *model.User) *pb.User {
-	return &pb.User{
-		Id:        user.ID,
-		Email:     user.Email,
-		FirstName: user.FirstName,
-		LastName:  user.LastName,
-		Phone:     user.Phone,
-		Status:    h.modelStatusToProto(user.Status),
-		CreatedAt: timestamppb.New(user.CreatedAt),
-		UpdatedAt: timestamppb.New(user.UpdatedAt),
+	pbUser := &pb.User{
+		Id:            user.ID,
+		Email:         user.Email,
+		FirstName:     user.FirstName,
+		LastName:      user.LastName,
+		Phone:         user.Phone,
+		Status:        h.modelStatusToProto(user.Status),
+		Role:          h.modelRoleToProto(user.Role),
+		EmailVerified: user.EmailVerified,
+		Version:       user.Version,
+		CreatedAt:     timestamppb.New(user.CreatedAt),
+		UpdatedAt:     timestamppb.New(user.UpdatedAt),
+	}
+	if user.DeletedAt.Valid {
+		pbUser.DeletedAt = timestamppb.New(user.DeletedAt.Time)
+		pbUser.DeletionReason = user.DeletedReason
+	}
+	return pbUser
+}
+
+// listFilter builds the repository filter, rejecting unknown statuses; only admins may list deleted users
+func (h *UserHandler) listFilter(ctx context.Context, query string, userStatus pb.UserStatus, includeDeleted bool) (repository.ListFilter, error) {
+	filter := repository.ListFilter{Query: query}
+	if userStatus != pb.UserStatus_USER_STATUS_UNSPECIFIED {
+		filter.Status = h.protoStatusToModel(userStatus)
+		if h.modelStatusToProto(filter.Status) != userStatus {
+			return filter, status.Errorf(codes.InvalidArgument, "unknown status %v", userStatus)
+		}
+	}
+	if includeDeleted {
+		principal, _ := auth.PrincipalFromContext(ctx)
+		if !principal.IsAdmin() {
+			return filter, status.Error(codes.PermissionDenied, "include_deleted requires admin role")
+		}
+		filter.IncludeDeleted = true
+	}
+	return filter, nil
+}
+
+// dateRangeRequest is a request carrying date range bounds
+type dateRangeRequest interface {
+	GetCreatedAfter() *timestamppb.Timestamp
+	GetCreatedBefore() *timestamppb.Timestamp
+	GetUpdatedAfter() *timestamppb.Timestamp
+	GetUpdatedBefore() *timestamppb.Timestamp
+}
+
+// setDateRanges copies the request's date range bounds into filter
+func setDateRanges(req dateRangeRequest, filter *repository.ListFilter) error {
+	bounds := []struct {
+		name string
+		ts   *timestamppb.Timestamp
+		dst  *time.Time
+	}{
+		{"created_after", req.GetCreatedAfter(), &filter.CreatedAfter},
+		{"created_before", req.GetCreatedBefore(), &filter.CreatedBefore},
+		{"updated_after", req.GetUpdatedAfter(), &filter.UpdatedAfter},
+		{"updated_before", req.GetUpdatedBefore(), &filter.UpdatedBefore},
 	}
+	for _, b := range bounds {
+		if b.ts == nil {
+			continue
+		}
+		if err := b.ts.CheckValid(); err != nil {
+			return status.Errorf(codes.InvalidArgument, "%s: %v", b.name, err)
+		}
+		*b.dst = b.ts.AsTime()
+	}
+	return nil
+}
+
+// sortKeys extracts the requested ordering, preferring the multi-key sort over sort_by/sort_order
+func (h *UserHandler) sortKeys(req *pb.ListUsersRequest) []repository.SortKey {
+	if len(req.Sort) > 0 {
+		keys := make([]repository.SortKey, len(req.Sort))
+		for i, key := range req.Sort {
+			keys[i] = repository.SortKey{Field: key.Field, Order: key.Order}
+		}
+		return keys
+	}
+
+	if req.SortBy == "" && req.SortOrder == "" {
+		return nil
+	}
+
+	field := req.SortBy
+	if field == "" {
+		field = "created_at"
+	}
+	return []repository.SortKey{{Field: field, Order: req.SortOrder}}
+}
+
+// listView converts a user for a list, stream or batch get response, redacting contact
+// fields the caller may not see
+func (h *UserHandler) listView(ctx context.Context, user *model.User) *pb.User {
+	pbUser := h.modelToProto(user)
+	if !h.maskContactFields {
+		return pbUser
+	}
+
+	principal, _ := auth.PrincipalFromContext(ctx)
+	if principal.IsAdmin() || (principal.UserID != "" && principal.UserID == user.ID) {
+		return pbUser
+	}
+
+	pbUser.Email = ""
+	pbUser.Phone = ""
+	return pbUser
 }
 
 // modelStatusToProto converts model status to proto status
@@ -195,6 +757,18 @@ func (h *UserHandler) modelStatusToProto(status model.UserStatus) pb.UserStatus
 	}
 }
 
+// modelRoleToProto converts model role to proto role
+func (h *UserHandler) modelRoleToProto(role model.UserRole) pb.UserRole {
+	switch role {
+	case model.UserRoleUser:
+		return pb.UserRole_USER_ROLE_USER
+	case model.UserRoleAdmin:
+		return pb.UserRole_USER_ROLE_ADMIN
+	default:
+		return pb.UserRole_USER_ROLE_UNSPECIFIED
+	}
+}
+
 // protoStatusToModel converts proto status to model status
 func (h *UserHandler) protoStatusToModel(status pb.UserStatus) model.UserStatus {
 	switch status {
@@ -208,3 +782,51 @@ func (h *UserHandler) protoStatusToModel(status pb.UserStatus) model.UserStatus
 		return model.UserStatusActive
 	}
 }
+
+// batchValidationStatus reports each invalid row as a BadRequest field violation
+func batchValidationStatus(batchErr *service.BatchValidationError) error {
+	violations := make([]*errdetails.BadRequest_FieldViolation, len(batchErr.Rows))
+	for i, row := range batchErr.Rows {
+		violations[i] = &errdetails.BadRequest_FieldViolation{
+			Field:       fmt.Sprintf("users[%d]", row.Index),
+			Description: row.Err.Error(),
+		}
+	}
+
+	st, err := status.New(codes.InvalidArgument, "invalid users in batch").
+		WithDetails(&errdetails.BadRequest{FieldViolations: violations})
+	if err != nil {
+		return status.Error(codes.InvalidArgument, batchErr.Error())
+	}
+	return st.Err()
+}
+
+// batchGetError reports a batch get failure for id as a status carrying the
+// resource and, for lookup failures, the reason; internal errors are not exposed
+func batchGetError(id string, err error) *pb.BatchGetError {
+	resource := &errdetails.ResourceInfo{ResourceType: "user", ResourceName: id}
+
+	var st *status.Status
+	switch {
+	case errors.Is(err, context.DeadlineExceeded), errors.Is(err, context.Canceled):
+		st = status.FromContextError(err)
+	default:
+		st = apperror.ToGRPCStatus(err)
+	}
+	if st.Code() == codes.Internal {
+		st = status.New(codes.Internal, "failed to get user")
+	}
+
+	withDetails, err := st.WithDetails(resource)
+	if st.Code() != codes.NotFound {
+		withDetails, err = st.WithDetails(resource, &errdetails.ErrorInfo{
+			Reason:   "LOOKUP_FAILED",
+			Domain:   "user.v1",
+			Metadata: map[string]string{"code": st.Code().String()},
+		})
+	}
+	if err == nil {
+		st = withDetails
+	}
+	return &pb.BatchGetError{Id: id, Status: st.Proto()}
+}
@@ -0,0 +1,525 @@
+package handler
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/golang-standards/project-layout/internal/app/user-service/model"
+	"github.com/golang-standards/project-layout/internal/app/user-service/repository"
+	"github.com/golang-standards/project-layout/internal/app/user-service/service"
+	"github.com/golang-standards/project-layout/internal/pkg/auth"
+	"github.com/golang-standards/project-layout/internal/pkg/logger"
+	pb "github.com/golang-standards/project-layout/pkg/api/user/v1"
+	"github.com/golang-standards/project-layout/pkg/pagination"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/fieldmaskpb"
+	"gorm.io/gorm"
+)
+
+// fakeService implements the methods a test sets; calling any other panics
+type fakeService struct {
+	service.UserService
+	createUsersBatch func(ctx context.Context, inputs []service.CreateUserInput) ([]*model.User, error)
+	validatePassword func(ctx context.Context, email, password string) (*model.User, error)
+	listUsers        func(ctx context.Context, params pagination.Params, filter repository.ListFilter, sort []repository.SortKey) ([]*model.User, int64, error)
+	updateUser       func(ctx context.Context, id string, updates map[string]interface{}) (*model.User, error)
+	batchGetUsers    func(ctx context.Context, ids []string) (*service.BatchGetResult, error)
+	streamUsers      func(ctx context.Context, filter repository.ListFilter, batchSize int, fn func(*model.User) error) error
+}
+
+func (f *fakeService) CreateUsersBatch(ctx context.Context, inputs []service.CreateUserInput) ([]*model.User, error) {
+	return f.createUsersBatch(ctx, inputs)
+}
+
+func (f *fakeService) ValidatePassword(ctx context.Context, email, password string) (*model.User, error) {
+	return f.validatePassword(ctx, email, password)
+}
+
+func (f *fakeService) ListUsers(ctx context.Context, params pagination.Params, filter repository.ListFilter, sort []repository.SortKey) ([]*model.User, int64, error) {
+	return f.listUsers(ctx, params, filter, sort)
+}
+
+func (f *fakeService) UpdateUser(ctx context.Context, id string, updates map[string]interface{}) (*model.User, error) {
+	return f.updateUser(ctx, id, updates)
+}
+
+func (f *fakeService) BatchGetUsers(ctx context.Context, ids []string) (*service.BatchGetResult, error) {
+	return f.batchGetUsers(ctx, ids)
+}
+
+func (f *fakeService) StreamUsers(ctx context.Context, filter repository.ListFilter, batchSize int, fn func(*model.User) error) error {
+	return f.streamUsers(ctx, filter, batchSize, fn)
+}
+
+// userStream is a StreamUsers server stream collecting the users sent
+type userStream struct {
+	grpc.ServerStream
+	ctx   context.Context
+	users []*pb.User
+}
+
+func (s *userStream) Context() context.Context { return s.ctx }
+
+func (s *userStream) Send(user *pb.User) error {
+	s.users = append(s.users, user)
+	return nil
+}
+
+func TestCreateUsersBatchRequiresAdmin(t *testing.T) {
+	tests := []struct {
+		name     string
+		ctx      context.Context
+		wantCode codes.Code
+	}{
+		{name: "admin", ctx: auth.WithPrincipal(context.Background(), auth.Principal{UserID: "a1", Role: auth.RoleAdmin})},
+		{name: "user", ctx: auth.WithPrincipal(context.Background(), auth.Principal{UserID: "u1", Role: auth.RoleUser}), wantCode: codes.PermissionDenied},
+		{name: "anonymous", ctx: context.Background(), wantCode: codes.PermissionDenied},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			called := false
+			svc := &fakeService{createUsersBatch: func(ctx context.Context, inputs []service.CreateUserInput) ([]*model.User, error) {
+				called = true
+				return []*model.User{{ID: "user-1", Email: inputs[0].Email}}, nil
+			}}
+			h := NewUserHandler(svc, logger.NewNopLogger(), Options{})
+
+			_, err := h.CreateUsersBatch(tt.ctx, &pb.CreateUsersBatchRequest{
+				Users: []*pb.CreateUserRequest{{Email: "jane@example.com", Password: "Correct-Horse-Battery-42"}},
+			})
+			if got := status.Code(err); got != tt.wantCode {
+				t.Fatalf("code = %v, want %v", got, tt.wantCode)
+			}
+			if called != (tt.wantCode == codes.OK) {
+				t.Errorf("service called = %v, want %v", called, tt.wantCode == codes.OK)
+			}
+		})
+	}
+}
+
+func TestValidateCredentials(t *testing.T) {
+	tests := []struct {
+		name     string
+		err      error
+		wantCode codes.Code
+	}{
+		{name: "valid"},
+		{name: "unknown email", err: repository.ErrUserNotFound, wantCode: codes.Unauthenticated},
+		{name: "wrong password", err: service.ErrInvalidPassword, wantCode: codes.Unauthenticated},
+		{name: "locked", err: service.ErrAccountLocked, wantCode: codes.FailedPrecondition},
+		{name: "inactive", err: fmt.Errorf("%w: account is suspended", service.ErrAccountInactive), wantCode: codes.FailedPrecondition},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			svc := &fakeService{validatePassword: func(ctx context.Context, email, password string) (*model.User, error) {
+				if tt.err != nil {
+					return nil, tt.err
+				}
+				return &model.User{ID: "user-1", Email: email}, nil
+			}}
+			h := NewUserHandler(svc, logger.NewNopLogger(), Options{})
+
+			resp, err := h.ValidateCredentials(context.Background(), &pb.ValidateCredentialsRequest{Email: "jane@example.com", Password: "secret"})
+			if got := status.Code(err); got != tt.wantCode {
+				t.Fatalf("code = %v, want %v", got, tt.wantCode)
+			}
+			if tt.wantCode == codes.OK && resp.User.GetId() != "user-1" {
+				t.Errorf("user = %v, want user-1", resp.User)
+			}
+			if tt.wantCode == codes.Unauthenticated && status.Convert(err).Message() != service.ErrInvalidCredentials.Error() {
+				t.Errorf("message = %q, want the same one for every credential failure", status.Convert(err).Message())
+			}
+		})
+	}
+}
+
+func TestListUsersStatusFilter(t *testing.T) {
+	tests := []struct {
+		name       string
+		status     pb.UserStatus
+		wantCode   codes.Code
+		wantStatus model.UserStatus
+	}{
+		{name: "any status", status: pb.UserStatus_USER_STATUS_UNSPECIFIED},
+		{name: "suspended", status: pb.UserStatus_USER_STATUS_SUSPENDED, wantStatus: model.UserStatusSuspended},
+		{name: "unknown", status: pb.UserStatus(99), wantCode: codes.InvalidArgument},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got repository.ListFilter
+			svc := &fakeService{listUsers: func(ctx context.Context, params pagination.Params, filter repository.ListFilter, sort []repository.SortKey) ([]*model.User, int64, error) {
+				got = filter
+				return nil, 0, nil
+			}}
+			h := NewUserHandler(svc, logger.NewNopLogger(), Options{})
+
+			_, err := h.ListUsers(context.Background(), &pb.ListUsersRequest{Filter: "doe", Status: tt.status})
+			if code := status.Code(err); code != tt.wantCode {
+				t.Fatalf("code = %v, want %v", code, tt.wantCode)
+			}
+			if tt.wantCode == codes.OK && (got.Status != tt.wantStatus || got.Query != "doe") {
+				t.Errorf("filter = %+v, want status %q with query %q", got, tt.wantStatus, "doe")
+			}
+		})
+	}
+}
+
+func TestListUsersDeletionReason(t *testing.T) {
+	deletedAt := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name       string
+		ctx        context.Context
+		user       *model.User
+		wantCode   codes.Code
+		wantReason string
+	}{
+		{
+			name:       "admin sees reason",
+			ctx:        auth.WithPrincipal(context.Background(), auth.Principal{UserID: "a1", Role: auth.RoleAdmin}),
+			user:       &model.User{ID: "u1", DeletedAt: gorm.DeletedAt{Time: deletedAt, Valid: true}, DeletedReason: "fraud"},
+			wantReason: "fraud",
+		},
+		{
+			name: "live user has no reason",
+			ctx:  auth.WithPrincipal(context.Background(), auth.Principal{UserID: "a1", Role: auth.RoleAdmin}),
+			user: &model.User{ID: "u1"},
+		},
+		{
+			name:     "user may not list deleted",
+			ctx:      auth.WithPrincipal(context.Background(), auth.Principal{UserID: "u1", Role: auth.RoleUser}),
+			wantCode: codes.PermissionDenied,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			svc := &fakeService{listUsers: func(ctx context.Context, params pagination.Params, filter repository.ListFilter, sort []repository.SortKey) ([]*model.User, int64, error) {
+				if !filter.IncludeDeleted {
+					t.Errorf("filter.IncludeDeleted = false, want true")
+				}
+				return []*model.User{tt.user}, 1, nil
+			}}
+			h := NewUserHandler(svc, logger.NewNopLogger(), Options{})
+
+			resp, err := h.ListUsers(tt.ctx, &pb.ListUsersRequest{IncludeDeleted: true})
+			if code := status.Code(err); code != tt.wantCode {
+				t.Fatalf("code = %v, want %v", code, tt.wantCode)
+			}
+			if tt.wantCode != codes.OK {
+				return
+			}
+			if got := resp.Users[0].GetDeletionReason(); got != tt.wantReason {
+				t.Errorf("deletion_reason = %q, want %q", got, tt.wantReason)
+			}
+			if got := resp.Users[0].GetDeletedAt() != nil; got != tt.user.DeletedAt.Valid {
+				t.Errorf("deleted_at set = %v, want %v", got, tt.user.DeletedAt.Valid)
+			}
+		})
+	}
+}
+
+func TestUpdateUserMask(t *testing.T) {
+	tests := []struct {
+		name        string
+		req         *pb.UpdateUserRequest
+		wantCode    codes.Code
+		wantUpdates map[string]interface{}
+	}{
+		{
+			name:        "without mask updates set fields",
+			req:         &pb.UpdateUserRequest{FirstName: proto.String("Jane"), Phone: proto.String("")},
+			wantUpdates: map[string]interface{}{"first_name": "Jane", "phone": ""},
+		},
+		{
+			name: "mask limits the update",
+			req: &pb.UpdateUserRequest{
+				FirstName:  proto.String("Jane"),
+				LastName:   proto.String("Roe"),
+				UpdateMask: &fieldmaskpb.FieldMask{Paths: []string{"first_name"}},
+			},
+			wantUpdates: map[string]interface{}{"first_name": "Jane"},
+		},
+		{
+			name:        "mask clears unset fields",
+			req:         &pb.UpdateUserRequest{UpdateMask: &fieldmaskpb.FieldMask{Paths: []string{"phone", "last_name"}}},
+			wantUpdates: map[string]interface{}{"phone": "", "last_name": ""},
+		},
+		{
+			name:        "JSON field names",
+			req:         &pb.UpdateUserRequest{FirstName: proto.String("Jane"), UpdateMask: &fieldmaskpb.FieldMask{Paths: []string{"firstName"}}},
+			wantUpdates: map[string]interface{}{"first_name": "Jane"},
+		},
+		{
+			name: "status",
+			req: &pb.UpdateUserRequest{
+				Status:     pb.UserStatus_USER_STATUS_SUSPENDED.Enum(),
+				UpdateMask: &fieldmaskpb.FieldMask{Paths: []string{"status"}},
+			},
+			wantUpdates: map[string]interface{}{"status": model.UserStatusSuspended},
+		},
+		{
+			name:     "status cannot be cleared",
+			req:      &pb.UpdateUserRequest{UpdateMask: &fieldmaskpb.FieldMask{Paths: []string{"status"}}},
+			wantCode: codes.InvalidArgument,
+		},
+		{
+			name:     "empty mask",
+			req:      &pb.UpdateUserRequest{FirstName: proto.String("Jane"), UpdateMask: &fieldmaskpb.FieldMask{}},
+			wantCode: codes.InvalidArgument,
+		},
+		{
+			name:     "unknown path",
+			req:      &pb.UpdateUserRequest{UpdateMask: &fieldmaskpb.FieldMask{Paths: []string{"nickname"}}},
+			wantCode: codes.InvalidArgument,
+		},
+		{
+			name:     "immutable path",
+			req:      &pb.UpdateUserRequest{UpdateMask: &fieldmaskpb.FieldMask{Paths: []string{"id"}}},
+			wantCode: codes.InvalidArgument,
+		},
+		{
+			name:     "duplicate path",
+			req:      &pb.UpdateUserRequest{UpdateMask: &fieldmaskpb.FieldMask{Paths: []string{"first_name", "firstName"}}},
+			wantCode: codes.InvalidArgument,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got map[string]interface{}
+			svc := &fakeService{updateUser: func(ctx context.Context, id string, updates map[string]interface{}) (*model.User, error) {
+				got = updates
+				return &model.User{ID: id}, nil
+			}}
+			h := NewUserHandler(svc, logger.NewNopLogger(), Options{})
+
+			tt.req.Id = "user-1"
+			_, err := h.UpdateUser(context.Background(), tt.req)
+			if code := status.Code(err); code != tt.wantCode {
+				t.Fatalf("code = %v, want %v", code, tt.wantCode)
+			}
+			if tt.wantCode != codes.OK {
+				if got != nil {
+					t.Errorf("service called with %v, want no call", got)
+				}
+				return
+			}
+			if !reflect.DeepEqual(got, tt.wantUpdates) {
+				t.Errorf("updates = %v, want %v", got, tt.wantUpdates)
+			}
+		})
+	}
+}
+
+func TestBatchGetUsersErrorDetails(t *testing.T) {
+	type wantError struct {
+		id     string
+		code   codes.Code
+		reason string
+	}
+
+	tests := []struct {
+		name       string
+		ids        []string
+		result     *service.BatchGetResult
+		wantUsers  []string
+		wantErrors []wantError
+	}{
+		{
+			name:      "all found",
+			ids:       []string{"u1", "u2"},
+			result:    &service.BatchGetResult{Users: []*model.User{{ID: "u1"}, {ID: "u2"}}},
+			wantUsers: []string{"u1", "u2"},
+		},
+		{
+			name:       "not found",
+			ids:        []string{"u1", "u2"},
+			result:     &service.BatchGetResult{Users: []*model.User{{ID: "u1"}}, NotFound: []string{"u2"}},
+			wantUsers:  []string{"u1"},
+			wantErrors: []wantError{{id: "u2", code: codes.NotFound}},
+		},
+		{
+			name: "failures in request order",
+			ids:  []string{"u3", "u1", "u2", "u3"},
+			result: &service.BatchGetResult{
+				Users:    []*model.User{{ID: "u1"}},
+				NotFound: []string{"u2"},
+				Failed: map[string]error{
+					"u3": errors.New("connection reset"),
+				},
+			},
+			wantUsers: []string{"u1"},
+			wantErrors: []wantError{
+				{id: "u3", code: codes.Internal, reason: "LOOKUP_FAILED"},
+				{id: "u2", code: codes.NotFound},
+			},
+		},
+		{
+			name: "deadline",
+			ids:  []string{"u1"},
+			result: &service.BatchGetResult{
+				Failed: map[string]error{"u1": fmt.Errorf("failed to get user: %w", context.DeadlineExceeded)},
+			},
+			wantErrors: []wantError{{id: "u1", code: codes.DeadlineExceeded, reason: "LOOKUP_FAILED"}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			svc := &fakeService{batchGetUsers: func(ctx context.Context, ids []string) (*service.BatchGetResult, error) {
+				return tt.result, nil
+			}}
+			h := NewUserHandler(svc, logger.NewNopLogger(), Options{})
+
+			resp, err := h.BatchGetUsers(context.Background(), &pb.BatchGetUsersRequest{Ids: tt.ids})
+			if err != nil {
+				t.Fatalf("BatchGetUsers: %v", err)
+			}
+
+			var gotUsers []string
+			for _, user := range resp.Users {
+				gotUsers = append(gotUsers, user.Id)
+			}
+			if !reflect.DeepEqual(gotUsers, tt.wantUsers) {
+				t.Errorf("users = %v, want %v", gotUsers, tt.wantUsers)
+			}
+
+			if len(resp.Errors) != len(tt.wantErrors) {
+				t.Fatalf("errors = %v, want %d", resp.Errors, len(tt.wantErrors))
+			}
+			for i, want := range tt.wantErrors {
+				got := resp.Errors[i]
+				st := status.FromProto(got.Status)
+				if got.Id != want.id || st.Code() != want.code {
+					t.Errorf("errors[%d] = %s %v, want %s %v", i, got.Id, st.Code(), want.id, want.code)
+				}
+				if st.Code() == codes.Internal && st.Message() != "failed to get user" {
+					t.Errorf("errors[%d] message = %q, want the internal error hidden", i, st.Message())
+				}
+
+				var resource, reason string
+				for _, detail := range st.Details() {
+					switch d := detail.(type) {
+					case *errdetails.ResourceInfo:
+						resource = d.ResourceName
+					case *errdetails.ErrorInfo:
+						reason = d.Reason
+					}
+				}
+				if resource != want.id || reason != want.reason {
+					t.Errorf("errors[%d] details = resource %q reason %q, want %q %q", i, resource, reason, want.id, want.reason)
+				}
+			}
+		})
+	}
+}
+
+func TestContactFieldMasking(t *testing.T) {
+	users := []*model.User{
+		{ID: "u1", Email: "jane@example.com", Phone: "+15555550100"},
+		{ID: "u2", Email: "john@example.com", Phone: "+15555550101"},
+	}
+	svc := &fakeService{
+		listUsers: func(ctx context.Context, params pagination.Params, filter repository.ListFilter, sort []repository.SortKey) ([]*model.User, int64, error) {
+			return users, int64(len(users)), nil
+		},
+		streamUsers: func(ctx context.Context, filter repository.ListFilter, batchSize int, fn func(*model.User) error) error {
+			for _, user := range users {
+				if err := fn(user); err != nil {
+					return err
+				}
+			}
+			return nil
+		},
+		batchGetUsers: func(ctx context.Context, ids []string) (*service.BatchGetResult, error) {
+			return &service.BatchGetResult{Users: users}, nil
+		},
+	}
+
+	reads := map[string]func(h *UserHandler, ctx context.Context) ([]*pb.User, error){
+		"ListUsers": func(h *UserHandler, ctx context.Context) ([]*pb.User, error) {
+			resp, err := h.ListUsers(ctx, &pb.ListUsersRequest{})
+			return resp.GetUsers(), err
+		},
+		"StreamUsers": func(h *UserHandler, ctx context.Context) ([]*pb.User, error) {
+			stream := &userStream{ctx: ctx}
+			err := h.StreamUsers(&pb.StreamUsersRequest{}, stream)
+			return stream.users, err
+		},
+		"BatchGetUsers": func(h *UserHandler, ctx context.Context) ([]*pb.User, error) {
+			resp, err := h.BatchGetUsers(ctx, &pb.BatchGetUsersRequest{Ids: []string{"u1", "u2"}})
+			return resp.GetUsers(), err
+		},
+	}
+
+	tests := []struct {
+		name        string
+		mask        bool
+		ctx         context.Context
+		wantVisible map[string]bool
+	}{
+		{
+			name:        "admin sees everyone",
+			mask:        true,
+			ctx:         auth.WithPrincipal(context.Background(), auth.Principal{UserID: "a1", Role: auth.RoleAdmin}),
+			wantVisible: map[string]bool{"u1": true, "u2": true},
+		},
+		{
+			name:        "user sees only themselves",
+			mask:        true,
+			ctx:         auth.WithPrincipal(context.Background(), auth.Principal{UserID: "u1", Role: auth.RoleUser}),
+			wantVisible: map[string]bool{"u1": true},
+		},
+		{
+			name:        "other user sees no one",
+			mask:        true,
+			ctx:         auth.WithPrincipal(context.Background(), auth.Principal{UserID: "u3", Role: auth.RoleUser}),
+			wantVisible: map[string]bool{},
+		},
+		{
+			name:        "anonymous sees no one",
+			mask:        true,
+			ctx:         context.Background(),
+			wantVisible: map[string]bool{},
+		},
+		{
+			name:        "masking disabled",
+			ctx:         context.Background(),
+			wantVisible: map[string]bool{"u1": true, "u2": true},
+		},
+	}
+
+	for _, tt := range tests {
+		for read, call := range reads {
+			t.Run(tt.name+"/"+read, func(t *testing.T) {
+				h := NewUserHandler(svc, logger.NewNopLogger(), Options{MaskContactFields: tt.mask})
+
+				got, err := call(h, tt.ctx)
+				if err != nil {
+					t.Fatalf("%s: %v", read, err)
+				}
+				if len(got) != len(users) {
+					t.Fatalf("got %d users, want %d", len(got), len(users))
+				}
+				for i, user := range got {
+					visible := tt.wantVisible[user.Id]
+					if (user.Email == users[i].Email) != visible || (user.Phone == users[i].Phone) != visible {
+						t.Errorf("user %s email %q phone %q, want contact fields visible = %v", user.Id, user.Email, user.Phone, visible)
+					}
+				}
+			})
+		}
+	}
+}
@@ -0,0 +1,33 @@
+package model
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// AuditRecord is an audit event stored in the same transaction as the change it
+// describes. Details holds the event details encoded as JSON.
+type AuditRecord struct {
+	ID             string    `gorm:"type:uuid;primary_key" json:"id"`
+	Action         string    `gorm:"not null" json:"action"`
+	TargetID       string    `gorm:"not null" json:"target_id"`
+	ActorID        string    `gorm:"not null" json:"actor_id"`
+	ImpersonatorID string    `gorm:"not null" json:"impersonator_id"`
+	Details        string    `gorm:"not null" json:"details"`
+	CreatedAt      time.Time `gorm:"not null" json:"created_at"`
+}
+
+// TableName overrides the table name
+func (AuditRecord) TableName() string {
+	return "audit_log"
+}
+
+// BeforeCreate hook
+func (r *AuditRecord) BeforeCreate(tx *gorm.DB) error {
+	if r.ID == "" {
+		r.ID = uuid.NewString()
+	}
+	return nil
+}
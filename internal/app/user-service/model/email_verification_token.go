@@ -0,0 +1,32 @@
+package model
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// EmailVerificationToken is a single-use, time-limited token proving ownership of an email address.
+// Only a hash of the token is stored.
+type EmailVerificationToken struct {
+	ID        string     `gorm:"type:uuid;primary_key" json:"id"`
+	UserID    string     `gorm:"type:uuid;index;not null" json:"user_id"`
+	TokenHash string     `gorm:"uniqueIndex;not null" json:"-"`
+	ExpiresAt time.Time  `gorm:"not null" json:"expires_at"`
+	UsedAt    *time.Time `json:"used_at"`
+	CreatedAt time.Time  `gorm:"autoCreateTime" json:"created_at"`
+}
+
+// TableName overrides the table name
+func (EmailVerificationToken) TableName() string {
+	return "email_verification_tokens"
+}
+
+// BeforeCreate hook
+func (t *EmailVerificationToken) BeforeCreate(tx *gorm.DB) error {
+	if t.ID == "" {
+		t.ID = uuid.NewString()
+	}
+	return nil
+}
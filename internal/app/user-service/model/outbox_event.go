@@ -0,0 +1,35 @@
+package model
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// OutboxEvent is a user event stored in the same transaction as the change that caused
+// it, until the outbox relay publishes it. Payload is the event encoded as JSON. Events
+// the relay can't decode are flagged with FailedAt and LastError and skipped.
+type OutboxEvent struct {
+	ID          string     `gorm:"type:uuid;primary_key" json:"id"`
+	EventType   string     `gorm:"not null" json:"event_type"`
+	UserID      string     `gorm:"type:uuid;not null" json:"user_id"`
+	Payload     string     `gorm:"not null" json:"payload"`
+	CreatedAt   time.Time  `gorm:"autoCreateTime" json:"created_at"`
+	PublishedAt *time.Time `json:"published_at"`
+	FailedAt    *time.Time `json:"failed_at"`
+	LastError   string     `json:"last_error"`
+}
+
+// TableName overrides the table name
+func (OutboxEvent) TableName() string {
+	return "outbox"
+}
+
+// BeforeCreate hook
+func (e *OutboxEvent) BeforeCreate(tx *gorm.DB) error {
+	if e.ID == "" {
+		e.ID = uuid.NewString()
+	}
+	return nil
+}
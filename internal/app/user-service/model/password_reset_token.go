@@ -0,0 +1,32 @@
+package model
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// PasswordResetToken is a single-use, time-limited token for resetting a password.
+// Only a hash of the token is stored.
+type PasswordResetToken struct {
+	ID        string     `gorm:"type:uuid;primary_key" json:"id"`
+	UserID    string     `gorm:"type:uuid;index;not null" json:"user_id"`
+	TokenHash string     `gorm:"uniqueIndex;not null" json:"-"`
+	ExpiresAt time.Time  `gorm:"not null" json:"expires_at"`
+	UsedAt    *time.Time `json:"used_at"`
+	CreatedAt time.Time  `gorm:"autoCreateTime" json:"created_at"`
+}
+
+// TableName overrides the table name
+func (PasswordResetToken) TableName() string {
+	return "password_reset_tokens"
+}
+
+// BeforeCreate hook
+func (t *PasswordResetToken) BeforeCreate(tx *gorm.DB) error {
+	if t.ID == "" {
+		t.ID = uuid.NewString()
+	}
+	return nil
+}
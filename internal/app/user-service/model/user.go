@@ -1,8 +1,12 @@
 package model
 
 import (
+	"reflect"
+	"strconv"
+	"strings"
 	"time"
 
+	"github.com/google/uuid"
 	"gorm.io/gorm"
 )
 
@@ -15,18 +19,61 @@ const (
 	UserStatusSuspended UserStatus = "suspended"
 )
 
+// UserRole is the authorization role granted to a user
+type UserRole string
+
+const (
+	UserRoleUser  UserRole = "user"
+	UserRoleAdmin UserRole = "admin"
+)
+
 // User represents a user entity
 type User struct {
-	ID        string         `gorm:"type:uuid;primary_key;default:gen_random_uuid()" json:"id"`
-	Email     string         `gorm:"uniqueIndex;not null" json:"email"`
-	Password  string         `gorm:"not null" json:"-"` // Never expose password in JSON
-	FirstName string         `gorm:"size:100" json:"first_name"`
-	LastName  string         `gorm:"size:100" json:"last_name"`
-	Phone     string         `gorm:"size:20" json:"phone"`
-	Status    UserStatus     `gorm:"type:varchar(20);default:'active'" json:"status"`
-	CreatedAt time.Time      `gorm:"autoCreateTime" json:"created_at"`
-	UpdatedAt time.Time      `gorm:"autoUpdateTime" json:"updated_at"`
-	DeletedAt gorm.DeletedAt `gorm:"index" json:"-"`
+	ID             string         `gorm:"type:uuid;primary_key" json:"id"`
+	Email          string         `gorm:"uniqueIndex;not null" json:"email"`
+	CanonicalEmail string         `gorm:"uniqueIndex;not null" json:"-"`           // Uniqueness key with provider aliases collapsed
+	OrgID          *string        `gorm:"type:uuid;index" json:"org_id,omitempty"` // Owning organization (tenant), if any
+	Password       string         `gorm:"not null" json:"-"`                       // Never expose password in JSON
+	FirstName      string         `gorm:"size:100" json:"first_name"`
+	LastName       string         `gorm:"size:100" json:"last_name"`
+	Phone          string         `gorm:"size:20" json:"phone"`
+	Status         UserStatus     `gorm:"type:varchar(20);default:'active'" json:"status"`
+	Role           UserRole       `gorm:"type:varchar(20);not null;default:'user'" json:"role"`
+	EmailVerified  bool           `gorm:"not null;default:false" json:"email_verified"`
+	Version        int64          `gorm:"not null;default:1" json:"version"` // Incremented on every update for optimistic locking
+	CreatedAt      time.Time      `gorm:"autoCreateTime" json:"created_at"`
+	UpdatedAt      time.Time      `gorm:"autoUpdateTime" json:"updated_at"`
+	DeletedAt      gorm.DeletedAt `gorm:"index" json:"-"`
+	DeletedReason  string         `gorm:"size:500" json:"-"`
+
+	// Failed login tracking for account lockout
+	FailedLoginAttempts    int        `gorm:"not null;default:0" json:"-"`
+	FailedLoginWindowStart *time.Time `json:"-"`
+	LockedUntil            *time.Time `json:"locked_until,omitempty"`
+}
+
+// Column size limits in characters, read from the gorm size tags above so input
+// validation cannot drift from the schema
+var (
+	MaxFirstNameLength = columnSize("FirstName")
+	MaxLastNameLength  = columnSize("LastName")
+	MaxPhoneLength     = columnSize("Phone")
+)
+
+// columnSize returns the size from a User field's gorm tag; it panics when there is
+// none, since that is a programming error caught at startup
+func columnSize(field string) int {
+	f, ok := reflect.TypeOf(User{}).FieldByName(field)
+	if ok {
+		for _, setting := range strings.Split(f.Tag.Get("gorm"), ";") {
+			if v, found := strings.CutPrefix(setting, "size:"); found {
+				if size, err := strconv.Atoi(v); err == nil {
+					return size
+				}
+			}
+		}
+	}
+	panic("model: no gorm size tag on User." + field)
 }
 
 // TableName overrides the table name
@@ -34,10 +81,34 @@ func (User) TableName() string {
 	return "users"
 }
 
-// BeforeCreate hook
+// AnonymizedEmailDomain is the domain of the tombstone emails given to anonymized users.
+// The .invalid TLD is reserved, so these addresses can never receive mail.
+const AnonymizedEmailDomain = "anonymized.invalid"
+
+// AnonymizedEmail returns the unique tombstone email for an anonymized user
+func AnonymizedEmail(id string) string {
+	return "user-" + id + "@" + AnonymizedEmailDomain
+}
+
+// IsAnonymized reports whether the user's personal data has been replaced with tombstones
+func (u *User) IsAnonymized() bool {
+	return u.Email == AnonymizedEmail(u.ID)
+}
+
+// BeforeCreate hook. IDs are generated here rather than by the database, so the
+// schema needs no pgcrypto and stays portable across Postgres and SQLite.
 func (u *User) BeforeCreate(tx *gorm.DB) error {
+	if u.ID == "" {
+		u.ID = uuid.NewString()
+	}
 	if u.Status == "" {
 		u.Status = UserStatusActive
 	}
+	if u.Role == "" {
+		u.Role = UserRoleUser
+	}
+	if u.CanonicalEmail == "" {
+		u.CanonicalEmail = u.Email
+	}
 	return nil
 }
@@ -0,0 +1,42 @@
+package repository
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/golang-standards/project-layout/internal/app/user-service/model"
+	"github.com/golang-standards/project-layout/internal/pkg/audit"
+	"github.com/golang-standards/project-layout/internal/pkg/database"
+)
+
+// AddAuditEvent stores an audit record. Call it on the repository passed to a WithTx
+// callback so the record commits or rolls back with the change it describes.
+func (r *userRepository) AddAuditEvent(ctx context.Context, event audit.Event) error {
+	details := ""
+	if len(event.Details) > 0 {
+		encoded, err := json.Marshal(event.Details)
+		if err != nil {
+			return fmt.Errorf("failed to encode audit details: %w", err)
+		}
+		details = string(encoded)
+	}
+
+	record := &model.AuditRecord{
+		Action:         event.Action,
+		TargetID:       event.TargetID,
+		ActorID:        event.ActorID,
+		ImpersonatorID: event.ImpersonatorID,
+		Details:        details,
+		CreatedAt:      event.Time,
+	}
+	err := database.Retry(ctx, r.retry, func() error {
+		return r.db.WithContext(ctx).Create(record).Error
+	})
+	if err != nil {
+		return fmt.Errorf("failed to add audit record: %w", err)
+	}
+	database.MarkWrite(ctx)
+
+	return nil
+}
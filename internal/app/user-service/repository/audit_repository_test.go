@@ -0,0 +1,82 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/golang-standards/project-layout/internal/app/user-service/model"
+	"github.com/golang-standards/project-layout/internal/pkg/audit"
+	"github.com/golang-standards/project-layout/internal/pkg/database"
+)
+
+func TestAddAuditEventInTransaction(t *testing.T) {
+	errAbort := errors.New("abort")
+
+	// Each backend reports the audit actions it has stored
+	backends := map[string]func(t *testing.T) (UserRepository, func() []string){
+		"sqlite": func(t *testing.T) (UserRepository, func() []string) {
+			db := newTestSQLiteDB(t)
+			return NewUserRepository(db, database.RetryPolicy{}), func() []string {
+				var actions []string
+				if err := db.Model(&model.AuditRecord{}).Order("created_at").Pluck("action", &actions).Error; err != nil {
+					t.Fatalf("read audit_log: %v", err)
+				}
+				return actions
+			}
+		},
+		"memory": func(t *testing.T) (UserRepository, func() []string) {
+			repo := NewInMemoryUserRepository().(*inMemoryUserRepository)
+			return repo, func() []string {
+				var actions []string
+				for _, event := range repo.audit {
+					actions = append(actions, event.Action)
+				}
+				return actions
+			}
+		},
+	}
+
+	tests := []struct {
+		name        string
+		fnErr       error
+		wantActions []string
+		wantUser    bool
+	}{
+		{name: "commit", wantActions: []string{"user.created"}, wantUser: true},
+		{name: "rollback", fnErr: errAbort},
+	}
+
+	for backend, newRepo := range backends {
+		for _, tt := range tests {
+			t.Run(backend+"/"+tt.name, func(t *testing.T) {
+				repo, auditActions := newRepo(t)
+				ctx := context.Background()
+				user := testUser("jane@example.com", "Jane", "Doe", nil)
+
+				err := repo.WithTx(ctx, func(txRepo UserRepository) error {
+					if err := txRepo.Create(ctx, user); err != nil {
+						return err
+					}
+					event := audit.Event{Action: "user.created", TargetID: user.ID, Details: map[string]string{"source": "test"}, Time: time.Now().UTC()}
+					if err := txRepo.AddAuditEvent(ctx, event); err != nil {
+						return err
+					}
+					return tt.fnErr
+				})
+				if !errors.Is(err, tt.fnErr) {
+					t.Fatalf("WithTx = %v, want %v", err, tt.fnErr)
+				}
+
+				if got := auditActions(); len(got) != len(tt.wantActions) || (len(got) > 0 && got[0] != tt.wantActions[0]) {
+					t.Errorf("audit actions = %v, want %v", got, tt.wantActions)
+				}
+				_, err = repo.GetByEmail(ctx, "jane@example.com")
+				if (err == nil) != tt.wantUser {
+					t.Errorf("GetByEmail = %v, want user stored = %v", err, tt.wantUser)
+				}
+			})
+		}
+	}
+}
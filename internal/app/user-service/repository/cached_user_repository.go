@@ -0,0 +1,228 @@
+package repository
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"time"
+
+	"github.com/golang-standards/project-layout/internal/app/user-service/model"
+	"github.com/golang-standards/project-layout/internal/pkg/database"
+	"github.com/golang-standards/project-layout/internal/pkg/logger"
+	"github.com/redis/go-redis/v9"
+)
+
+// cacheKeyPrefix namespaces cached users in Redis. It is versioned with the encoding,
+// so entries written with the password hash by older releases are never read.
+const cacheKeyPrefix = "user-service:user:v2:"
+
+// cachedUserRepository caches GetByID in Redis in front of another UserRepository.
+// Redis failures are logged and fall through to the wrapped repository.
+type cachedUserRepository struct {
+	UserRepository
+	client *redis.Client
+	ttl    time.Duration
+	// deferred collects the IDs to invalidate once the enclosing transaction ends; nil
+	// outside a transaction
+	deferred *[]string
+}
+
+// NewCachedUserRepository wraps next with a Redis read-through cache for GetByID
+func NewCachedUserRepository(next UserRepository, client *redis.Client, ttl time.Duration) UserRepository {
+	return &cachedUserRepository{
+		UserRepository: next,
+		client:         client,
+		ttl:            ttl,
+	}
+}
+
+// cachedUser is the cache encoding of model.User. It keeps every persisted field except
+// the password hash, which must not leave the database: users served from the cache have
+// an empty Password, so callers that check it read with database.WithPrimary, which
+// bypasses the cache.
+type cachedUser struct {
+	ID                     string           `json:"id"`
+	Email                  string           `json:"email"`
+	CanonicalEmail         string           `json:"canonical_email"`
+	OrgID                  *string          `json:"org_id,omitempty"`
+	FirstName              string           `json:"first_name"`
+	LastName               string           `json:"last_name"`
+	Phone                  string           `json:"phone"`
+	Status                 model.UserStatus `json:"status"`
+	Role                   model.UserRole   `json:"role"`
+	EmailVerified          bool             `json:"email_verified"`
+	Version                int64            `json:"version"`
+	CreatedAt              time.Time        `json:"created_at"`
+	UpdatedAt              time.Time        `json:"updated_at"`
+	FailedLoginAttempts    int              `json:"failed_login_attempts"`
+	FailedLoginWindowStart *time.Time       `json:"failed_login_window_start,omitempty"`
+	LockedUntil            *time.Time       `json:"locked_until,omitempty"`
+}
+
+func toCachedUser(user *model.User) cachedUser {
+	return cachedUser{
+		ID:                     user.ID,
+		Email:                  user.Email,
+		CanonicalEmail:         user.CanonicalEmail,
+		OrgID:                  user.OrgID,
+		FirstName:              user.FirstName,
+		LastName:               user.LastName,
+		Phone:                  user.Phone,
+		Status:                 user.Status,
+		Role:                   user.Role,
+		EmailVerified:          user.EmailVerified,
+		Version:                user.Version,
+		CreatedAt:              user.CreatedAt,
+		UpdatedAt:              user.UpdatedAt,
+		FailedLoginAttempts:    user.FailedLoginAttempts,
+		FailedLoginWindowStart: user.FailedLoginWindowStart,
+		LockedUntil:            user.LockedUntil,
+	}
+}
+
+func (c cachedUser) toModel() *model.User {
+	return &model.User{
+		ID:                     c.ID,
+		Email:                  c.Email,
+		CanonicalEmail:         c.CanonicalEmail,
+		OrgID:                  c.OrgID,
+		FirstName:              c.FirstName,
+		LastName:               c.LastName,
+		Phone:                  c.Phone,
+		Status:                 c.Status,
+		Role:                   c.Role,
+		EmailVerified:          c.EmailVerified,
+		Version:                c.Version,
+		CreatedAt:              c.CreatedAt,
+		UpdatedAt:              c.UpdatedAt,
+		FailedLoginAttempts:    c.FailedLoginAttempts,
+		FailedLoginWindowStart: c.FailedLoginWindowStart,
+		LockedUntil:            c.LockedUntil,
+	}
+}
+
+// GetByID returns the cached user, loading and caching it on a miss. Reads that must
+// see the primary skip the cache, which may trail it.
+func (r *cachedUserRepository) GetByID(ctx context.Context, id string) (*model.User, error) {
+	if database.ReadFromPrimary(ctx) {
+		return r.UserRepository.GetByID(ctx, id)
+	}
+
+	data, err := r.client.Get(ctx, cacheKeyPrefix+id).Bytes()
+	switch {
+	case err == nil:
+		var cached cachedUser
+		if err := json.Unmarshal(data, &cached); err == nil {
+			return cached.toModel(), nil
+		}
+		logger.FromContext(ctx).Warn("Discarding undecodable cached user", "user_id", id)
+	case !errors.Is(err, redis.Nil):
+		logger.FromContext(ctx).Warn("User cache read failed, reading from database", "error", err, "user_id", id)
+	}
+
+	user, err := r.UserRepository.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	data, err = json.Marshal(toCachedUser(user))
+	if err == nil {
+		err = r.client.Set(ctx, cacheKeyPrefix+id, data, r.ttl).Err()
+	}
+	if err != nil {
+		logger.FromContext(ctx).Warn("User cache write failed", "error", err, "user_id", id)
+	}
+
+	return user, nil
+}
+
+// Update updates the user and invalidates its cache entry
+func (r *cachedUserRepository) Update(ctx context.Context, user *model.User, fields []string) error {
+	err := r.UserRepository.Update(ctx, user, fields)
+	if user != nil {
+		r.invalidate(ctx, user.ID)
+	}
+	return err
+}
+
+// Delete deletes the user and invalidates its cache entry
+func (r *cachedUserRepository) Delete(ctx context.Context, id, reason string) error {
+	err := r.UserRepository.Delete(ctx, id, reason)
+	r.invalidate(ctx, id)
+	return err
+}
+
+// Restore restores the user and invalidates its cache entry
+func (r *cachedUserRepository) Restore(ctx context.Context, id string) (*model.User, error) {
+	user, err := r.UserRepository.Restore(ctx, id)
+	r.invalidate(ctx, id)
+	return user, err
+}
+
+// Purge removes the user and invalidates its cache entry
+func (r *cachedUserRepository) Purge(ctx context.Context, id string) error {
+	err := r.UserRepository.Purge(ctx, id)
+	r.invalidate(ctx, id)
+	return err
+}
+
+// RecordFailedLogin records the failure and invalidates the cache entry
+func (r *cachedUserRepository) RecordFailedLogin(ctx context.Context, id string, now, windowStart time.Time) (int, error) {
+	attempts, err := r.UserRepository.RecordFailedLogin(ctx, id, now, windowStart)
+	r.invalidate(ctx, id)
+	return attempts, err
+}
+
+// Lock locks the user and invalidates the cache entry
+func (r *cachedUserRepository) Lock(ctx context.Context, id string, until time.Time) error {
+	err := r.UserRepository.Lock(ctx, id, until)
+	r.invalidate(ctx, id)
+	return err
+}
+
+// ResetFailedLogins clears the lockout and invalidates the cache entry
+func (r *cachedUserRepository) ResetFailedLogins(ctx context.Context, id string) error {
+	err := r.UserRepository.ResetFailedLogins(ctx, id)
+	r.invalidate(ctx, id)
+	return err
+}
+
+// WithTx runs fn in a transaction with a repository whose writes invalidate cache
+// entries once the transaction ends. Invalidating earlier would let a concurrent read
+// cache the old row again before the commit.
+func (r *cachedUserRepository) WithTx(ctx context.Context, fn func(txRepo UserRepository) error) error {
+	// A nested transaction hands its invalidations to the outermost one
+	deferred := r.deferred
+	if deferred == nil {
+		deferred = new([]string)
+		defer func() {
+			for _, id := range *deferred {
+				r.invalidate(ctx, id)
+			}
+		}()
+	}
+
+	return r.UserRepository.WithTx(ctx, func(txRepo UserRepository) error {
+		return fn(&cachedUserRepository{
+			UserRepository: txRepo,
+			client:         r.client,
+			ttl:            r.ttl,
+			deferred:       deferred,
+		})
+	})
+}
+
+// invalidate drops a cached user, or queues it until the transaction ends. It runs even
+// when the write failed, since the write may still have been applied.
+func (r *cachedUserRepository) invalidate(ctx context.Context, id string) {
+	if id == "" {
+		return
+	}
+	if r.deferred != nil {
+		*r.deferred = append(*r.deferred, id)
+		return
+	}
+	if err := r.client.Del(ctx, cacheKeyPrefix+id).Err(); err != nil {
+		logger.FromContext(ctx).Warn("User cache invalidation failed", "error", err, "user_id", id)
+	}
+}
@@ -0,0 +1,159 @@
+package repository_test
+
+import (
+	"context"
+	"net"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/golang-standards/project-layout/internal/app/user-service/model"
+	"github.com/golang-standards/project-layout/internal/app/user-service/repository"
+	"github.com/golang-standards/project-layout/internal/app/user-service/repository/mocks"
+	"github.com/golang-standards/project-layout/internal/pkg/database"
+	"github.com/redis/go-redis/v9"
+)
+
+// recordingHook answers Redis commands without a server, recording the keys deleted and
+// the values set. GETs miss.
+type recordingHook struct {
+	mu      sync.Mutex
+	deleted []string
+	set     []string
+}
+
+func (h *recordingHook) DialHook(next redis.DialHook) redis.DialHook { return next }
+
+func (h *recordingHook) ProcessHook(next redis.ProcessHook) redis.ProcessHook {
+	return func(ctx context.Context, cmd redis.Cmder) error {
+		h.mu.Lock()
+		switch cmd.Name() {
+		case "del":
+			for _, arg := range cmd.Args()[1:] {
+				h.deleted = append(h.deleted, arg.(string))
+			}
+		case "set":
+			h.set = append(h.set, string(cmd.Args()[2].([]byte)))
+		}
+		h.mu.Unlock()
+		cmd.SetErr(redis.Nil)
+		return nil
+	}
+}
+
+func (h *recordingHook) ProcessPipelineHook(next redis.ProcessPipelineHook) redis.ProcessPipelineHook {
+	return next
+}
+
+func (h *recordingHook) Deleted() []string {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	return append([]string(nil), h.deleted...)
+}
+
+func newRecordingClient(t *testing.T) (*redis.Client, *recordingHook) {
+	t.Helper()
+	client := redis.NewClient(&redis.Options{
+		Dialer: func(ctx context.Context, network, addr string) (net.Conn, error) {
+			t.Fatal("unexpected dial")
+			return nil, nil
+		},
+	})
+	hook := &recordingHook{}
+	client.AddHook(hook)
+	t.Cleanup(func() { client.Close() })
+	return client, hook
+}
+
+func TestCachedGetByID(t *testing.T) {
+	const hash = "$2a$10$abcdefghijklmnopqrstuv"
+
+	tests := []struct {
+		name      string
+		ctx       context.Context
+		wantCache bool
+	}{
+		{name: "cache miss", ctx: context.Background(), wantCache: true},
+		{name: "primary read", ctx: database.WithPrimary(context.Background())},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client, hook := newRecordingClient(t)
+			next := &mocks.MockUserRepository{
+				GetByIDFunc: func(ctx context.Context, id string) (*model.User, error) {
+					return &model.User{ID: id, Email: "jane@example.com", Password: hash}, nil
+				},
+			}
+			repo := repository.NewCachedUserRepository(next, client, time.Minute)
+
+			user, err := repo.GetByID(tt.ctx, "user-1")
+			if err != nil {
+				t.Fatalf("GetByID: %v", err)
+			}
+			if user.Password != hash {
+				t.Errorf("password = %q, want the hash read from the database", user.Password)
+			}
+
+			hook.mu.Lock()
+			set := append([]string(nil), hook.set...)
+			hook.mu.Unlock()
+			if got := len(set) == 1; got != tt.wantCache {
+				t.Fatalf("cached %d times, want cached = %v", len(set), tt.wantCache)
+			}
+			for _, payload := range set {
+				if strings.Contains(payload, hash) || strings.Contains(payload, "password") {
+					t.Errorf("cached payload %s contains the password", payload)
+				}
+			}
+		})
+	}
+}
+
+func TestCachedWithTxDefersInvalidation(t *testing.T) {
+	tests := []struct {
+		name   string
+		nested bool
+		fnErr  bool
+	}{
+		{name: "commit"},
+		{name: "rollback", fnErr: true},
+		{name: "nested transaction", nested: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client, hook := newRecordingClient(t)
+			next := &mocks.MockUserRepository{
+				UpdateFunc: func(ctx context.Context, user *model.User, fields []string) error { return nil },
+			}
+			repo := repository.NewCachedUserRepository(next, client, 0)
+
+			update := func(txRepo repository.UserRepository) error {
+				if err := txRepo.Update(context.Background(), &model.User{ID: "user-1"}, []string{"first_name"}); err != nil {
+					return err
+				}
+				if deleted := hook.Deleted(); len(deleted) != 0 {
+					t.Errorf("invalidated %v before the transaction ended", deleted)
+				}
+				if tt.fnErr {
+					return context.Canceled
+				}
+				return nil
+			}
+			_ = repo.WithTx(context.Background(), func(txRepo repository.UserRepository) error {
+				if tt.nested {
+					return txRepo.WithTx(context.Background(), update)
+				}
+				return update(txRepo)
+			})
+
+			// Rolled back writes are invalidated too, since they may have been applied
+			deleted := hook.Deleted()
+			if len(deleted) != 1 || !strings.HasSuffix(deleted[0], "user-1") {
+				t.Errorf("deleted = %v, want the user-1 entry once", deleted)
+			}
+		})
+	}
+}
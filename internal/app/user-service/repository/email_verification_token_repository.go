@@ -0,0 +1,86 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/golang-standards/project-layout/internal/app/user-service/model"
+	"github.com/golang-standards/project-layout/internal/pkg/database"
+	"gorm.io/gorm"
+)
+
+// EmailVerificationTokenRepository defines the interface for email verification token storage
+type EmailVerificationTokenRepository interface {
+	Create(ctx context.Context, token *model.EmailVerificationToken) error
+	GetByHash(ctx context.Context, tokenHash string) (*model.EmailVerificationToken, error)
+	GetLatestByUserID(ctx context.Context, userID string) (*model.EmailVerificationToken, error)
+	MarkUsed(ctx context.Context, id string) error
+}
+
+type emailVerificationTokenRepository struct {
+	db *gorm.DB
+}
+
+// NewEmailVerificationTokenRepository creates a new instance of EmailVerificationTokenRepository
+func NewEmailVerificationTokenRepository(db *gorm.DB) EmailVerificationTokenRepository {
+	return &emailVerificationTokenRepository{db: db}
+}
+
+// Create stores a new email verification token
+func (r *emailVerificationTokenRepository) Create(ctx context.Context, token *model.EmailVerificationToken) error {
+	if err := r.db.WithContext(ctx).Create(token).Error; err != nil {
+		return fmt.Errorf("failed to create email verification token: %w", err)
+	}
+	database.MarkWrite(ctx)
+
+	return nil
+}
+
+// GetByHash retrieves a token by its hash
+func (r *emailVerificationTokenRepository) GetByHash(ctx context.Context, tokenHash string) (*model.EmailVerificationToken, error) {
+	var token model.EmailVerificationToken
+	if err := database.Reader(ctx, r.db).Where("token_hash = ?", tokenHash).First(&token).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrTokenNotFound
+		}
+		return nil, fmt.Errorf("failed to get email verification token: %w", err)
+	}
+
+	return &token, nil
+}
+
+// GetLatestByUserID retrieves the most recently issued token for a user
+func (r *emailVerificationTokenRepository) GetLatestByUserID(ctx context.Context, userID string) (*model.EmailVerificationToken, error) {
+	var token model.EmailVerificationToken
+	err := database.Reader(ctx, r.db).
+		Where("user_id = ?", userID).
+		Order("created_at DESC").
+		First(&token).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrTokenNotFound
+		}
+		return nil, fmt.Errorf("failed to get email verification token: %w", err)
+	}
+
+	return &token, nil
+}
+
+// MarkUsed atomically consumes an unused token, returning ErrTokenNotFound if it was already used
+func (r *emailVerificationTokenRepository) MarkUsed(ctx context.Context, id string) error {
+	result := r.db.WithContext(ctx).Model(&model.EmailVerificationToken{}).
+		Where("id = ? AND used_at IS NULL", id).
+		Update("used_at", time.Now().UTC())
+	if result.Error != nil {
+		return fmt.Errorf("failed to mark email verification token used: %w", result.Error)
+	}
+	database.MarkWrite(ctx)
+
+	if result.RowsAffected == 0 {
+		return ErrTokenNotFound
+	}
+
+	return nil
+}
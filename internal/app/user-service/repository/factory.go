@@ -0,0 +1,61 @@
+package repository
+
+import (
+	"fmt"
+
+	"github.com/golang-standards/project-layout/internal/app/user-service/model"
+	"github.com/golang-standards/project-layout/internal/pkg/config"
+	"github.com/golang-standards/project-layout/internal/pkg/database"
+	"github.com/golang-standards/project-layout/internal/pkg/events"
+	"gorm.io/gorm"
+)
+
+// Store holds the repositories for the configured storage backend
+type Store struct {
+	Users UserRepository
+	// ResetTokens and VerificationTokens are nil when the backend does not support them
+	ResetTokens        PasswordResetTokenRepository
+	VerificationTokens EmailVerificationTokenRepository
+	// Outbox holds events awaiting relay; nil when the backend has no outbox table
+	Outbox events.Outbox
+	// DB is the SQL connection backing the repositories, or nil for the memory driver
+	DB *gorm.DB
+}
+
+// Models returns the models stored in SQL tables, for checking the schema against them
+func Models() []interface{} {
+	return []interface{}{
+		&model.User{},
+		&model.PasswordResetToken{},
+		&model.EmailVerificationToken{},
+		&model.OutboxEvent{},
+		&model.AuditRecord{},
+	}
+}
+
+// New creates the repositories for the storage backend selected by cfg.Driver
+func New(cfg config.DatabaseConfig) (*Store, error) {
+	var db *gorm.DB
+	var err error
+	switch cfg.Driver {
+	case config.DriverPostgres:
+		db, err = database.NewPostgresDB(cfg)
+	case config.DriverSQLite:
+		db, err = database.NewSQLiteDB(cfg)
+	case config.DriverMemory:
+		return &Store{Users: NewInMemoryUserRepository()}, nil
+	default:
+		return nil, fmt.Errorf("unsupported database driver %q", cfg.Driver)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return &Store{
+		Users:              NewUserRepository(db, database.NewRetryPolicy(cfg.Retry)),
+		ResetTokens:        NewPasswordResetTokenRepository(db),
+		VerificationTokens: NewEmailVerificationTokenRepository(db),
+		Outbox:             NewOutboxRepository(db),
+		DB:                 db,
+	}, nil
+}
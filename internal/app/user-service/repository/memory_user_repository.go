@@ -0,0 +1,569 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/golang-standards/project-layout/internal/app/user-service/model"
+	"github.com/golang-standards/project-layout/internal/pkg/audit"
+	"github.com/golang-standards/project-layout/internal/pkg/events"
+	"github.com/golang-standards/project-layout/internal/pkg/validation"
+	"github.com/golang-standards/project-layout/pkg/pagination"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// inMemoryUserRepository is a UserRepository backed by a map, for tests and local demos.
+// It mirrors the Postgres constraints: emails and canonical emails are unique across all
+// users, including soft-deleted ones.
+type inMemoryUserRepository struct {
+	mu    *sync.RWMutex
+	users map[string]*model.User
+	audit []audit.Event
+}
+
+// NewInMemoryUserRepository creates an empty in-memory UserRepository
+func NewInMemoryUserRepository() UserRepository {
+	return &inMemoryUserRepository{
+		mu:    &sync.RWMutex{},
+		users: make(map[string]*model.User),
+	}
+}
+
+// WithTx runs fn against a copy of the data that replaces the original only if fn
+// succeeds. Other operations block until the transaction finishes.
+func (r *inMemoryUserRepository) WithTx(ctx context.Context, fn func(txRepo UserRepository) error) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	tx := &inMemoryUserRepository{
+		mu:    &sync.RWMutex{},
+		users: make(map[string]*model.User, len(r.users)),
+		audit: append([]audit.Event(nil), r.audit...),
+	}
+	for id, user := range r.users {
+		tx.users[id] = cloneUser(user)
+	}
+
+	if err := fn(tx); err != nil {
+		return err
+	}
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	r.users = tx.users
+	r.audit = tx.audit
+	return nil
+}
+
+// Create creates a new user, assigning an ID when it has none
+func (r *inMemoryUserRepository) Create(ctx context.Context, user *model.User) error {
+	if user == nil {
+		return ErrInvalidUserData
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return r.insert(user, time.Now().UTC())
+}
+
+// CreateBatch inserts users atomically; either all are created or none
+func (r *inMemoryUserRepository) CreateBatch(ctx context.Context, users []*model.User) error {
+	if len(users) == 0 {
+		return nil
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	now := time.Now().UTC()
+	inserted := make([]string, 0, len(users))
+	for _, user := range users {
+		if user == nil {
+			r.remove(inserted)
+			return ErrInvalidUserData
+		}
+		if err := r.insert(user, now); err != nil {
+			r.remove(inserted)
+			return err
+		}
+		inserted = append(inserted, user.ID)
+	}
+
+	return nil
+}
+
+// GetByID retrieves a user by ID
+func (r *inMemoryUserRepository) GetByID(ctx context.Context, id string) (*model.User, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	user, ok := r.users[id]
+	if !ok || user.DeletedAt.Valid {
+		return nil, ErrUserNotFound
+	}
+	return cloneUser(user), nil
+}
+
+// GetByIDs retrieves the users matching ids, in no particular order
+func (r *inMemoryUserRepository) GetByIDs(ctx context.Context, ids []string) ([]*model.User, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	users := make([]*model.User, 0, len(ids))
+	seen := make(map[string]bool, len(ids))
+	for _, id := range ids {
+		user, ok := r.users[id]
+		if !ok || user.DeletedAt.Valid || seen[id] {
+			continue
+		}
+		seen[id] = true
+		users = append(users, cloneUser(user))
+	}
+	return users, nil
+}
+
+// GetByEmail retrieves a user by email, normalized as on write so lookups are case-insensitive
+func (r *inMemoryUserRepository) GetByEmail(ctx context.Context, email string) (*model.User, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	email = validation.NormalizeEmail(email)
+	for _, user := range r.users {
+		if user.Email == email && !user.DeletedAt.Valid {
+			return cloneUser(user), nil
+		}
+	}
+	return nil, ErrUserNotFound
+}
+
+// Update writes the given columns of user if the stored version still matches user.Version,
+// which is then incremented; otherwise it returns ErrConflict
+func (r *inMemoryUserRepository) Update(ctx context.Context, user *model.User, fields []string) error {
+	if user == nil || user.ID == "" || len(fields) == 0 {
+		return ErrInvalidUserData
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	stored, ok := r.users[user.ID]
+	if !ok || stored.DeletedAt.Valid {
+		return ErrUserNotFound
+	}
+	if stored.Version != user.Version {
+		return ErrConflict
+	}
+
+	updated := cloneUser(stored)
+	for _, field := range fields {
+		if err := copyColumn(updated, user, field); err != nil {
+			return err
+		}
+	}
+	if err := r.checkUnique(updated); err != nil {
+		return err
+	}
+
+	updated.Version++
+	updated.UpdatedAt = time.Now().UTC()
+	r.users[user.ID] = updated
+
+	user.Version = updated.Version
+	user.UpdatedAt = updated.UpdatedAt
+	return nil
+}
+
+// Delete soft-deletes a user, recording the optional reason
+func (r *inMemoryUserRepository) Delete(ctx context.Context, id, reason string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	user, ok := r.users[id]
+	if !ok || user.DeletedAt.Valid {
+		return ErrUserNotFound
+	}
+	user.DeletedAt = gorm.DeletedAt{Time: time.Now().UTC(), Valid: true}
+	user.DeletedReason = reason
+	return nil
+}
+
+// Purge permanently removes a user, soft-deleted or not
+func (r *inMemoryUserRepository) Purge(ctx context.Context, id string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.users[id]; !ok {
+		return ErrUserNotFound
+	}
+	delete(r.users, id)
+	return nil
+}
+
+// AddOutboxEvents fails; the in-memory backend has no outbox
+func (r *inMemoryUserRepository) AddOutboxEvents(ctx context.Context, evts []events.Event) error {
+	return ErrOutboxUnsupported
+}
+
+// AddAuditEvent keeps the audit event with the users
+func (r *inMemoryUserRepository) AddAuditEvent(ctx context.Context, event audit.Event) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.audit = append(r.audit, event)
+	return nil
+}
+
+// MarkResetTokenUsed fails; the in-memory backend stores no reset tokens
+func (r *inMemoryUserRepository) MarkResetTokenUsed(ctx context.Context, id string) error {
+	return ErrTokenNotFound
+}
+
+// Restore brings back a soft-deleted user.
+// It refuses when an active user now holds the same email.
+func (r *inMemoryUserRepository) Restore(ctx context.Context, id string) (*model.User, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	user, ok := r.users[id]
+	if !ok || !user.DeletedAt.Valid {
+		return nil, ErrUserNotFound
+	}
+	for _, other := range r.users {
+		if other.ID != id && !other.DeletedAt.Valid &&
+			(other.Email == user.Email || other.CanonicalEmail == user.CanonicalEmail) {
+			return nil, ErrUserAlreadyExists
+		}
+	}
+
+	user.DeletedAt = gorm.DeletedAt{}
+	user.DeletedReason = ""
+	return cloneUser(user), nil
+}
+
+// RecordFailedLogin counts a failed login and returns the number of failures since windowStart
+func (r *inMemoryUserRepository) RecordFailedLogin(ctx context.Context, id string, now, windowStart time.Time) (int, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	user, ok := r.users[id]
+	if !ok || user.DeletedAt.Valid {
+		return 0, ErrUserNotFound
+	}
+	if user.FailedLoginWindowStart != nil && user.FailedLoginWindowStart.After(windowStart) {
+		user.FailedLoginAttempts++
+	} else {
+		user.FailedLoginAttempts = 1
+		user.FailedLoginWindowStart = &now
+	}
+	return user.FailedLoginAttempts, nil
+}
+
+// Lock prevents a user from logging in until the given time
+func (r *inMemoryUserRepository) Lock(ctx context.Context, id string, until time.Time) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	user, ok := r.users[id]
+	if !ok || user.DeletedAt.Valid {
+		return ErrUserNotFound
+	}
+	user.LockedUntil = &until
+	return nil
+}
+
+// ResetFailedLogins clears the failed login count and any lockout
+func (r *inMemoryUserRepository) ResetFailedLogins(ctx context.Context, id string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	user, ok := r.users[id]
+	if !ok || user.DeletedAt.Valid {
+		return ErrUserNotFound
+	}
+	user.FailedLoginAttempts = 0
+	user.FailedLoginWindowStart = nil
+	user.LockedUntil = nil
+	return nil
+}
+
+// List retrieves a paginated list of users ordered by the given sort keys
+func (r *inMemoryUserRepository) List(ctx context.Context, params pagination.Params, filter ListFilter, sort []SortKey) ([]*model.User, int64, error) {
+	less, err := sortLess(sort)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	users := r.matching(filter, less)
+	total := int64(len(users))
+
+	offset := params.Offset()
+	if offset < 0 {
+		offset = 0
+	}
+	if offset >= len(users) {
+		return []*model.User{}, total, nil
+	}
+	end := offset + params.Limit()
+	if end > len(users) {
+		end = len(users)
+	}
+
+	return users[offset:end], total, nil
+}
+
+// Count returns the number of users matching filter
+func (r *inMemoryUserRepository) Count(ctx context.Context, filter ListFilter) (int64, error) {
+	return int64(len(r.matching(filter, creationLess))), nil
+}
+
+// ListCursor retrieves users after the given cursor, ordered by creation time and ID
+func (r *inMemoryUserRepository) ListCursor(ctx context.Context, cursor string, limit int, filter ListFilter) ([]*model.User, string, error) {
+	users := r.matching(filter, creationLess)
+
+	if cursor != "" {
+		after, err := decodeCursor(cursor)
+		if err != nil {
+			return nil, "", err
+		}
+		start := sort.Search(len(users), func(i int) bool {
+			u := users[i]
+			return u.CreatedAt.After(after.CreatedAt) || (u.CreatedAt.Equal(after.CreatedAt) && u.ID > after.ID)
+		})
+		users = users[start:]
+	}
+
+	if len(users) <= limit {
+		return users, "", nil
+	}
+
+	users = users[:limit]
+	last := users[len(users)-1]
+	return users, encodeCursor(userCursor{CreatedAt: last.CreatedAt, ID: last.ID}), nil
+}
+
+// Stream reads all users matching filter in batches of batchSize, calling fn for each batch
+func (r *inMemoryUserRepository) Stream(ctx context.Context, filter ListFilter, batchSize int, fn func([]*model.User) error) error {
+	users := r.matching(filter, creationLess)
+	for start := 0; start < len(users); start += batchSize {
+		if err := ctx.Err(); err != nil {
+			return fmt.Errorf("failed to stream users: %w", err)
+		}
+		end := start + batchSize
+		if end > len(users) {
+			end = len(users)
+		}
+		if err := fn(users[start:end]); err != nil {
+			return fmt.Errorf("failed to stream users: %w", err)
+		}
+	}
+	return nil
+}
+
+// insert stores a copy of user after checking uniqueness. The caller must hold the write lock.
+func (r *inMemoryUserRepository) insert(user *model.User, now time.Time) error {
+	if user.ID == "" {
+		user.ID = uuid.NewString()
+	}
+	if _, ok := r.users[user.ID]; ok {
+		return ErrUserAlreadyExists
+	}
+	if user.Status == "" {
+		user.Status = model.UserStatusActive
+	}
+	if user.Role == "" {
+		user.Role = model.UserRoleUser
+	}
+	if user.CanonicalEmail == "" {
+		user.CanonicalEmail = user.Email
+	}
+	if user.Version == 0 {
+		user.Version = 1
+	}
+	if user.CreatedAt.IsZero() {
+		user.CreatedAt = now
+	}
+	if user.UpdatedAt.IsZero() {
+		user.UpdatedAt = now
+	}
+	if err := r.checkUnique(user); err != nil {
+		return err
+	}
+
+	r.users[user.ID] = cloneUser(user)
+	return nil
+}
+
+// remove deletes the given users; CreateBatch uses it to undo a partial insert
+func (r *inMemoryUserRepository) remove(ids []string) {
+	for _, id := range ids {
+		delete(r.users, id)
+	}
+}
+
+// checkUnique reports whether user would violate a unique email or tenant name constraint
+// held by another user
+func (r *inMemoryUserRepository) checkUnique(user *model.User) error {
+	for _, other := range r.users {
+		if other.ID == user.ID {
+			continue
+		}
+		if other.Email == user.Email || other.CanonicalEmail == user.CanonicalEmail {
+			return ErrUserAlreadyExists
+		}
+		if sameTenantName(other, user) {
+			return ErrDuplicateName
+		}
+	}
+	return nil
+}
+
+// sameTenantName mirrors the tenant name index: live users of the same organization
+// whose names match ignoring case
+func sameTenantName(a, b *model.User) bool {
+	if a.OrgID == nil || b.OrgID == nil || *a.OrgID != *b.OrgID || a.DeletedAt.Valid || b.DeletedAt.Valid {
+		return false
+	}
+	return strings.EqualFold(a.FirstName, b.FirstName) && strings.EqualFold(a.LastName, b.LastName)
+}
+
+// matching returns copies of the users matching filter, ordered by less
+func (r *inMemoryUserRepository) matching(filter ListFilter, less func(a, b *model.User) bool) []*model.User {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	query := strings.ToLower(filter.Query)
+	users := make([]*model.User, 0, len(r.users))
+	for _, user := range r.users {
+		if user.DeletedAt.Valid && !filter.IncludeDeleted {
+			continue
+		}
+		if filter.Status != "" && user.Status != filter.Status {
+			continue
+		}
+		if !inRange(user.CreatedAt, filter.CreatedAfter, filter.CreatedBefore) ||
+			!inRange(user.UpdatedAt, filter.UpdatedAfter, filter.UpdatedBefore) {
+			continue
+		}
+		if query != "" &&
+			!strings.Contains(strings.ToLower(user.FirstName), query) &&
+			!strings.Contains(strings.ToLower(user.LastName), query) &&
+			!strings.Contains(strings.ToLower(user.Email), query) {
+			continue
+		}
+		users = append(users, cloneUser(user))
+	}
+
+	sort.Slice(users, func(i, j int) bool { return less(users[i], users[j]) })
+	return users
+}
+
+// inRange reports whether t is in [after, before), treating zero bounds as unbounded
+func inRange(t, after, before time.Time) bool {
+	if !after.IsZero() && t.Before(after) {
+		return false
+	}
+	return before.IsZero() || t.Before(before)
+}
+
+// creationLess orders users by creation time and ID, like ListCursor and Stream
+func creationLess(a, b *model.User) bool {
+	if !a.CreatedAt.Equal(b.CreatedAt) {
+		return a.CreatedAt.Before(b.CreatedAt)
+	}
+	return a.ID < b.ID
+}
+
+// sortLess builds a comparator equivalent to orderClause, including the id tiebreaker
+func sortLess(keys []SortKey) (func(a, b *model.User) bool, error) {
+	if _, err := orderClause(keys); err != nil {
+		return nil, err
+	}
+	if len(keys) == 0 {
+		keys = []SortKey{{Field: "created_at"}}
+	}
+
+	return func(a, b *model.User) bool {
+		for _, key := range keys {
+			c := compareColumn(a, b, strings.ToLower(key.Field))
+			if c == 0 {
+				continue
+			}
+			if strings.EqualFold(key.Order, "asc") {
+				return c < 0
+			}
+			return c > 0
+		}
+		return a.ID < b.ID
+	}, nil
+}
+
+// compareColumn compares a sortable column of two users
+func compareColumn(a, b *model.User, column string) int {
+	switch column {
+	case "created_at":
+		return a.CreatedAt.Compare(b.CreatedAt)
+	case "updated_at":
+		return a.UpdatedAt.Compare(b.UpdatedAt)
+	case "email":
+		return strings.Compare(a.Email, b.Email)
+	case "last_name":
+		return strings.Compare(a.LastName, b.LastName)
+	case "status":
+		return strings.Compare(string(a.Status), string(b.Status))
+	}
+	return 0
+}
+
+// copyColumn copies the named column from src to dst
+func copyColumn(dst, src *model.User, column string) error {
+	switch column {
+	case "email":
+		dst.Email = src.Email
+	case "canonical_email":
+		dst.CanonicalEmail = src.CanonicalEmail
+	case "org_id":
+		dst.OrgID = src.OrgID
+	case "password":
+		dst.Password = src.Password
+	case "first_name":
+		dst.FirstName = src.FirstName
+	case "last_name":
+		dst.LastName = src.LastName
+	case "phone":
+		dst.Phone = src.Phone
+	case "status":
+		dst.Status = src.Status
+	case "role":
+		dst.Role = src.Role
+	case "email_verified":
+		dst.EmailVerified = src.EmailVerified
+	default:
+		return fmt.Errorf("%w: unknown field %q", ErrInvalidUserData, column)
+	}
+	return nil
+}
+
+// cloneUser returns a deep copy of user so callers cannot mutate stored data
+func cloneUser(user *model.User) *model.User {
+	clone := *user
+	if user.OrgID != nil {
+		orgID := *user.OrgID
+		clone.OrgID = &orgID
+	}
+	if user.FailedLoginWindowStart != nil {
+		start := *user.FailedLoginWindowStart
+		clone.FailedLoginWindowStart = &start
+	}
+	if user.LockedUntil != nil {
+		until := *user.LockedUntil
+		clone.LockedUntil = &until
+	}
+	return &clone
+}
@@ -0,0 +1,78 @@
+package mocks
+
+import (
+	"context"
+
+	"github.com/golang-standards/project-layout/internal/app/user-service/model"
+	"github.com/golang-standards/project-layout/internal/app/user-service/repository"
+)
+
+// MockEmailVerificationTokenRepository is a configurable repository.EmailVerificationTokenRepository;
+// unset methods return ErrNotMocked
+type MockEmailVerificationTokenRepository struct {
+	CreateFunc            func(ctx context.Context, token *model.EmailVerificationToken) error
+	GetByHashFunc         func(ctx context.Context, tokenHash string) (*model.EmailVerificationToken, error)
+	GetLatestByUserIDFunc func(ctx context.Context, userID string) (*model.EmailVerificationToken, error)
+	MarkUsedFunc          func(ctx context.Context, id string) error
+}
+
+var _ repository.EmailVerificationTokenRepository = (*MockEmailVerificationTokenRepository)(nil)
+
+func (m *MockEmailVerificationTokenRepository) Create(ctx context.Context, token *model.EmailVerificationToken) error {
+	if m.CreateFunc == nil {
+		return ErrNotMocked
+	}
+	return m.CreateFunc(ctx, token)
+}
+
+func (m *MockEmailVerificationTokenRepository) GetByHash(ctx context.Context, tokenHash string) (*model.EmailVerificationToken, error) {
+	if m.GetByHashFunc == nil {
+		return nil, ErrNotMocked
+	}
+	return m.GetByHashFunc(ctx, tokenHash)
+}
+
+func (m *MockEmailVerificationTokenRepository) GetLatestByUserID(ctx context.Context, userID string) (*model.EmailVerificationToken, error) {
+	if m.GetLatestByUserIDFunc == nil {
+		return nil, ErrNotMocked
+	}
+	return m.GetLatestByUserIDFunc(ctx, userID)
+}
+
+func (m *MockEmailVerificationTokenRepository) MarkUsed(ctx context.Context, id string) error {
+	if m.MarkUsedFunc == nil {
+		return ErrNotMocked
+	}
+	return m.MarkUsedFunc(ctx, id)
+}
+
+// MockPasswordResetTokenRepository is a configurable repository.PasswordResetTokenRepository;
+// unset methods return ErrNotMocked
+type MockPasswordResetTokenRepository struct {
+	CreateFunc    func(ctx context.Context, token *model.PasswordResetToken) error
+	GetByHashFunc func(ctx context.Context, tokenHash string) (*model.PasswordResetToken, error)
+	MarkUsedFunc  func(ctx context.Context, id string) error
+}
+
+var _ repository.PasswordResetTokenRepository = (*MockPasswordResetTokenRepository)(nil)
+
+func (m *MockPasswordResetTokenRepository) Create(ctx context.Context, token *model.PasswordResetToken) error {
+	if m.CreateFunc == nil {
+		return ErrNotMocked
+	}
+	return m.CreateFunc(ctx, token)
+}
+
+func (m *MockPasswordResetTokenRepository) GetByHash(ctx context.Context, tokenHash string) (*model.PasswordResetToken, error) {
+	if m.GetByHashFunc == nil {
+		return nil, ErrNotMocked
+	}
+	return m.GetByHashFunc(ctx, tokenHash)
+}
+
+func (m *MockPasswordResetTokenRepository) MarkUsed(ctx context.Context, id string) error {
+	if m.MarkUsedFunc == nil {
+		return ErrNotMocked
+	}
+	return m.MarkUsedFunc(ctx, id)
+}
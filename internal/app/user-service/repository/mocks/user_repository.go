@@ -0,0 +1,242 @@
+// Package mocks provides test doubles for the user service repositories.
+package mocks
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/golang-standards/project-layout/internal/app/user-service/model"
+	"github.com/golang-standards/project-layout/internal/app/user-service/repository"
+	"github.com/golang-standards/project-layout/internal/pkg/audit"
+	"github.com/golang-standards/project-layout/internal/pkg/events"
+	"github.com/golang-standards/project-layout/pkg/pagination"
+)
+
+// ErrNotMocked is returned by MockUserRepository methods whose function is not set
+var ErrNotMocked = errors.New("mock: method not configured")
+
+// Call records one invocation of a MockUserRepository method
+type Call struct {
+	Method string
+	Args   []interface{}
+}
+
+// MockUserRepository is a configurable repository.UserRepository. Set the XxxFunc
+// field for each method a test needs; unset methods return ErrNotMocked.
+// WithTx defaults to calling fn with the mock itself and AddAuditEvent to storing
+// nothing. Calls are recorded in order.
+type MockUserRepository struct {
+	CreateFunc             func(ctx context.Context, user *model.User) error
+	CreateBatchFunc        func(ctx context.Context, users []*model.User) error
+	GetByIDFunc            func(ctx context.Context, id string) (*model.User, error)
+	GetByIDsFunc           func(ctx context.Context, ids []string) ([]*model.User, error)
+	GetByEmailFunc         func(ctx context.Context, email string) (*model.User, error)
+	UpdateFunc             func(ctx context.Context, user *model.User, fields []string) error
+	DeleteFunc             func(ctx context.Context, id, reason string) error
+	RestoreFunc            func(ctx context.Context, id string) (*model.User, error)
+	PurgeFunc              func(ctx context.Context, id string) error
+	RecordFailedLoginFunc  func(ctx context.Context, id string, now, windowStart time.Time) (int, error)
+	LockFunc               func(ctx context.Context, id string, until time.Time) error
+	ResetFailedLoginsFunc  func(ctx context.Context, id string) error
+	ListFunc               func(ctx context.Context, params pagination.Params, filter repository.ListFilter, sort []repository.SortKey) ([]*model.User, int64, error)
+	ListCursorFunc         func(ctx context.Context, cursor string, limit int, filter repository.ListFilter) ([]*model.User, string, error)
+	CountFunc              func(ctx context.Context, filter repository.ListFilter) (int64, error)
+	StreamFunc             func(ctx context.Context, filter repository.ListFilter, batchSize int, fn func([]*model.User) error) error
+	AddOutboxEventsFunc    func(ctx context.Context, evts []events.Event) error
+	AddAuditEventFunc      func(ctx context.Context, event audit.Event) error
+	MarkResetTokenUsedFunc func(ctx context.Context, id string) error
+	WithTxFunc             func(ctx context.Context, fn func(txRepo repository.UserRepository) error) error
+
+	mu    sync.Mutex
+	calls []Call
+}
+
+var _ repository.UserRepository = (*MockUserRepository)(nil)
+
+// Calls returns the recorded invocations
+func (m *MockUserRepository) Calls() []Call {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return append([]Call(nil), m.calls...)
+}
+
+// CallCount returns how many times method was invoked
+func (m *MockUserRepository) CallCount(method string) int {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	var n int
+	for _, c := range m.calls {
+		if c.Method == method {
+			n++
+		}
+	}
+	return n
+}
+
+func (m *MockUserRepository) record(method string, args ...interface{}) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.calls = append(m.calls, Call{Method: method, Args: args})
+}
+
+func (m *MockUserRepository) Create(ctx context.Context, user *model.User) error {
+	m.record("Create", user)
+	if m.CreateFunc == nil {
+		return ErrNotMocked
+	}
+	return m.CreateFunc(ctx, user)
+}
+
+func (m *MockUserRepository) CreateBatch(ctx context.Context, users []*model.User) error {
+	m.record("CreateBatch", users)
+	if m.CreateBatchFunc == nil {
+		return ErrNotMocked
+	}
+	return m.CreateBatchFunc(ctx, users)
+}
+
+func (m *MockUserRepository) GetByID(ctx context.Context, id string) (*model.User, error) {
+	m.record("GetByID", id)
+	if m.GetByIDFunc == nil {
+		return nil, ErrNotMocked
+	}
+	return m.GetByIDFunc(ctx, id)
+}
+
+func (m *MockUserRepository) GetByIDs(ctx context.Context, ids []string) ([]*model.User, error) {
+	m.record("GetByIDs", ids)
+	if m.GetByIDsFunc == nil {
+		return nil, ErrNotMocked
+	}
+	return m.GetByIDsFunc(ctx, ids)
+}
+
+func (m *MockUserRepository) GetByEmail(ctx context.Context, email string) (*model.User, error) {
+	m.record("GetByEmail", email)
+	if m.GetByEmailFunc == nil {
+		return nil, ErrNotMocked
+	}
+	return m.GetByEmailFunc(ctx, email)
+}
+
+func (m *MockUserRepository) Update(ctx context.Context, user *model.User, fields []string) error {
+	m.record("Update", user, fields)
+	if m.UpdateFunc == nil {
+		return ErrNotMocked
+	}
+	return m.UpdateFunc(ctx, user, fields)
+}
+
+func (m *MockUserRepository) Delete(ctx context.Context, id, reason string) error {
+	m.record("Delete", id, reason)
+	if m.DeleteFunc == nil {
+		return ErrNotMocked
+	}
+	return m.DeleteFunc(ctx, id, reason)
+}
+
+func (m *MockUserRepository) Purge(ctx context.Context, id string) error {
+	m.record("Purge", id)
+	if m.PurgeFunc == nil {
+		return ErrNotMocked
+	}
+	return m.PurgeFunc(ctx, id)
+}
+
+func (m *MockUserRepository) AddOutboxEvents(ctx context.Context, evts []events.Event) error {
+	m.record("AddOutboxEvents", evts)
+	if m.AddOutboxEventsFunc == nil {
+		return ErrNotMocked
+	}
+	return m.AddOutboxEventsFunc(ctx, evts)
+}
+
+func (m *MockUserRepository) AddAuditEvent(ctx context.Context, event audit.Event) error {
+	m.record("AddAuditEvent", event)
+	if m.AddAuditEventFunc == nil {
+		return nil
+	}
+	return m.AddAuditEventFunc(ctx, event)
+}
+
+func (m *MockUserRepository) MarkResetTokenUsed(ctx context.Context, id string) error {
+	m.record("MarkResetTokenUsed", id)
+	if m.MarkResetTokenUsedFunc == nil {
+		return ErrNotMocked
+	}
+	return m.MarkResetTokenUsedFunc(ctx, id)
+}
+
+func (m *MockUserRepository) Restore(ctx context.Context, id string) (*model.User, error) {
+	m.record("Restore", id)
+	if m.RestoreFunc == nil {
+		return nil, ErrNotMocked
+	}
+	return m.RestoreFunc(ctx, id)
+}
+
+func (m *MockUserRepository) RecordFailedLogin(ctx context.Context, id string, now, windowStart time.Time) (int, error) {
+	m.record("RecordFailedLogin", id, now, windowStart)
+	if m.RecordFailedLoginFunc == nil {
+		return 0, ErrNotMocked
+	}
+	return m.RecordFailedLoginFunc(ctx, id, now, windowStart)
+}
+
+func (m *MockUserRepository) Lock(ctx context.Context, id string, until time.Time) error {
+	m.record("Lock", id, until)
+	if m.LockFunc == nil {
+		return ErrNotMocked
+	}
+	return m.LockFunc(ctx, id, until)
+}
+
+func (m *MockUserRepository) ResetFailedLogins(ctx context.Context, id string) error {
+	m.record("ResetFailedLogins", id)
+	if m.ResetFailedLoginsFunc == nil {
+		return ErrNotMocked
+	}
+	return m.ResetFailedLoginsFunc(ctx, id)
+}
+
+func (m *MockUserRepository) List(ctx context.Context, params pagination.Params, filter repository.ListFilter, sort []repository.SortKey) ([]*model.User, int64, error) {
+	m.record("List", params, filter, sort)
+	if m.ListFunc == nil {
+		return nil, 0, ErrNotMocked
+	}
+	return m.ListFunc(ctx, params, filter, sort)
+}
+
+func (m *MockUserRepository) ListCursor(ctx context.Context, cursor string, limit int, filter repository.ListFilter) ([]*model.User, string, error) {
+	m.record("ListCursor", cursor, limit, filter)
+	if m.ListCursorFunc == nil {
+		return nil, "", ErrNotMocked
+	}
+	return m.ListCursorFunc(ctx, cursor, limit, filter)
+}
+
+func (m *MockUserRepository) Count(ctx context.Context, filter repository.ListFilter) (int64, error) {
+	m.record("Count", filter)
+	if m.CountFunc == nil {
+		return 0, ErrNotMocked
+	}
+	return m.CountFunc(ctx, filter)
+}
+
+func (m *MockUserRepository) Stream(ctx context.Context, filter repository.ListFilter, batchSize int, fn func([]*model.User) error) error {
+	m.record("Stream", filter, batchSize)
+	if m.StreamFunc == nil {
+		return ErrNotMocked
+	}
+	return m.StreamFunc(ctx, filter, batchSize, fn)
+}
+
+func (m *MockUserRepository) WithTx(ctx context.Context, fn func(txRepo repository.UserRepository) error) error {
+	m.record("WithTx")
+	if m.WithTxFunc == nil {
+		return fn(m)
+	}
+	return m.WithTxFunc(ctx, fn)
+}
@@ -0,0 +1,135 @@
+package repository
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/golang-standards/project-layout/internal/app/user-service/model"
+	"github.com/golang-standards/project-layout/internal/pkg/database"
+	"github.com/golang-standards/project-layout/internal/pkg/events"
+	"github.com/golang-standards/project-layout/internal/pkg/logger"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// ErrOutboxUnsupported is returned when events are added to a backend without an outbox table
+var ErrOutboxUnsupported = errors.New("the outbox requires a SQL database")
+
+// AddOutboxEvents stores events in the outbox. Call it on the repository passed to a
+// WithTx callback so the events commit or roll back with the change they describe.
+func (r *userRepository) AddOutboxEvents(ctx context.Context, evts []events.Event) error {
+	if len(evts) == 0 {
+		return nil
+	}
+
+	rows := make([]*model.OutboxEvent, len(evts))
+	for i, event := range evts {
+		payload, err := json.Marshal(event)
+		if err != nil {
+			return fmt.Errorf("failed to encode outbox event: %w", err)
+		}
+		rows[i] = &model.OutboxEvent{
+			EventType: string(event.Type),
+			UserID:    event.UserID,
+			Payload:   string(payload),
+		}
+	}
+
+	if err := r.db.WithContext(ctx).CreateInBatches(rows, createBatchSize).Error; err != nil {
+		return fmt.Errorf("failed to add outbox events: %w", err)
+	}
+	database.MarkWrite(ctx)
+
+	return nil
+}
+
+type outboxRepository struct {
+	db *gorm.DB
+}
+
+// NewOutboxRepository creates an events.Outbox backed by the outbox table
+func NewOutboxRepository(db *gorm.DB) events.Outbox {
+	return &outboxRepository{db: db}
+}
+
+// Claim locks up to limit unpublished events with FOR UPDATE SKIP LOCKED, so concurrent
+// relays claim disjoint events, and keeps them locked until publish returns and the
+// published ones are marked. It runs on the primary, which a transaction always uses.
+func (r *outboxRepository) Claim(ctx context.Context, limit int, publish func(ctx context.Context, records []events.OutboxRecord) ([]string, error)) (int, error) {
+	claimed := 0
+	var publishErr error
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var rows []model.OutboxEvent
+		err := tx.Clauses(clause.Locking{Strength: "UPDATE", Options: "SKIP LOCKED"}).
+			Where("published_at IS NULL AND failed_at IS NULL").
+			Order("created_at, id").
+			Limit(limit).
+			Find(&rows).Error
+		if err != nil {
+			return fmt.Errorf("failed to claim outbox events: %w", err)
+		}
+		claimed = len(rows)
+
+		records := make([]events.OutboxRecord, 0, len(rows))
+		for _, row := range rows {
+			var event events.Event
+			if err := json.Unmarshal([]byte(row.Payload), &event); err != nil {
+				// An undecodable event would block every event behind it, so set it aside
+				// for an operator to inspect
+				logger.FromContext(ctx).Error("Flagging undecodable outbox event", "error", err, "event_id", row.ID)
+				if err := markOutboxFailed(tx, row.ID, err); err != nil {
+					return err
+				}
+				continue
+			}
+			records = append(records, events.OutboxRecord{ID: row.ID, Event: event})
+		}
+		if len(records) == 0 {
+			return nil
+		}
+
+		var published []string
+		published, publishErr = publish(ctx, records)
+		if len(published) == 0 {
+			return nil
+		}
+		err = tx.Model(&model.OutboxEvent{}).
+			Where("id IN ?", published).
+			Update("published_at", time.Now().UTC()).Error
+		if err != nil {
+			return fmt.Errorf("failed to mark outbox events published: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return claimed, err
+	}
+
+	return claimed, publishErr
+}
+
+// markOutboxFailed flags an event so the relay skips it
+func markOutboxFailed(tx *gorm.DB, id string, cause error) error {
+	err := tx.Model(&model.OutboxEvent{}).
+		Where("id = ?", id).
+		Updates(map[string]interface{}{"failed_at": time.Now().UTC(), "last_error": cause.Error()}).Error
+	if err != nil {
+		return fmt.Errorf("failed to flag outbox event %s: %w", id, err)
+	}
+	return nil
+}
+
+// Prune deletes events published before cutoff
+func (r *outboxRepository) Prune(ctx context.Context, cutoff time.Time) (int64, error) {
+	result := r.db.WithContext(ctx).
+		Where("published_at IS NOT NULL AND published_at < ?", cutoff.UTC()).
+		Delete(&model.OutboxEvent{})
+	if result.Error != nil {
+		return 0, fmt.Errorf("failed to prune outbox events: %w", result.Error)
+	}
+
+	return result.RowsAffected, nil
+}
@@ -0,0 +1,174 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/golang-standards/project-layout/internal/app/user-service/model"
+	"github.com/golang-standards/project-layout/internal/pkg/config"
+	"github.com/golang-standards/project-layout/internal/pkg/database"
+	"github.com/golang-standards/project-layout/internal/pkg/events"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// newTestSQLiteDB opens a migrated SQLite database in a temporary directory
+func newTestSQLiteDB(t *testing.T) *gorm.DB {
+	t.Helper()
+	db, err := database.NewSQLiteDB(config.DatabaseConfig{SQLitePath: filepath.Join(t.TempDir(), "test.db"), MaxOpenConns: 1})
+	if err != nil {
+		t.Fatalf("open sqlite: %v", err)
+	}
+	t.Cleanup(func() { database.Close(db) })
+
+	if err := database.RunMigrations(db); err != nil {
+		t.Fatalf("RunMigrations: %v", err)
+	}
+	return db
+}
+
+// addOutboxRows stores one outbox row per payload, a second apart, returning their IDs
+func addOutboxRows(t *testing.T, db *gorm.DB, payloads ...string) []string {
+	t.Helper()
+	base := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	ids := make([]string, len(payloads))
+	for i, payload := range payloads {
+		row := &model.OutboxEvent{
+			EventType: string(events.UserCreated),
+			UserID:    uuid.NewString(),
+			Payload:   payload,
+			CreatedAt: base.Add(time.Duration(i) * time.Second),
+		}
+		if err := db.Create(row).Error; err != nil {
+			t.Fatalf("create outbox row: %v", err)
+		}
+		ids[i] = row.ID
+	}
+	return ids
+}
+
+func TestOutboxClaim(t *testing.T) {
+	const event = `{"type":"user.created","user_id":"u1","time":"2024-01-01T12:00:00Z"}`
+	errBroker := errors.New("broker unavailable")
+
+	tests := []struct {
+		name          string
+		payloads      []string
+		failAt        int // publish fails on this record, or -1
+		wantClaimed   int
+		wantPassed    int
+		wantPublished []int
+		wantFailed    []int
+		wantErr       error
+	}{
+		{
+			name:          "publishes every event",
+			payloads:      []string{event, event, event},
+			failAt:        -1,
+			wantClaimed:   3,
+			wantPassed:    3,
+			wantPublished: []int{0, 1, 2},
+		},
+		{
+			name:          "keeps events after a failure",
+			payloads:      []string{event, event, event},
+			failAt:        1,
+			wantClaimed:   3,
+			wantPassed:    3,
+			wantPublished: []int{0},
+			wantErr:       errBroker,
+		},
+		{
+			name:          "flags undecodable events",
+			payloads:      []string{event, "{not json", event},
+			failAt:        -1,
+			wantClaimed:   3,
+			wantPassed:    2,
+			wantPublished: []int{0, 2},
+			wantFailed:    []int{1},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db := newTestSQLiteDB(t)
+			ids := addOutboxRows(t, db, tt.payloads...)
+			outbox := NewOutboxRepository(db)
+
+			passed := 0
+			claimed, err := outbox.Claim(context.Background(), 10, func(ctx context.Context, records []events.OutboxRecord) ([]string, error) {
+				passed = len(records)
+				var published []string
+				for i, record := range records {
+					if i == tt.failAt {
+						return published, errBroker
+					}
+					published = append(published, record.ID)
+				}
+				return published, nil
+			})
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("err = %v, want %v", err, tt.wantErr)
+			}
+			if claimed != tt.wantClaimed || passed != tt.wantPassed {
+				t.Errorf("claimed %d and passed %d, want %d and %d", claimed, passed, tt.wantClaimed, tt.wantPassed)
+			}
+
+			var rows []model.OutboxEvent
+			if err := db.Find(&rows).Error; err != nil {
+				t.Fatalf("list outbox: %v", err)
+			}
+			byID := make(map[string]model.OutboxEvent, len(rows))
+			for _, row := range rows {
+				byID[row.ID] = row
+			}
+			for i, id := range ids {
+				row := byID[id]
+				if got, want := row.PublishedAt != nil, contains(tt.wantPublished, i); got != want {
+					t.Errorf("event %d published = %v, want %v", i, got, want)
+				}
+				if got, want := row.FailedAt != nil && row.LastError != "", contains(tt.wantFailed, i); got != want {
+					t.Errorf("event %d flagged = %v, want %v", i, got, want)
+				}
+			}
+		})
+	}
+}
+
+func TestOutboxPrune(t *testing.T) {
+	db := newTestSQLiteDB(t)
+	ids := addOutboxRows(t, db, "{}", "{}", "{}")
+	now := time.Date(2024, 2, 1, 0, 0, 0, 0, time.UTC)
+
+	// Published long ago, published recently, and never published
+	db.Model(&model.OutboxEvent{}).Where("id = ?", ids[0]).Update("published_at", now.Add(-30*24*time.Hour))
+	db.Model(&model.OutboxEvent{}).Where("id = ?", ids[1]).Update("published_at", now.Add(-time.Hour))
+
+	deleted, err := NewOutboxRepository(db).Prune(context.Background(), now.Add(-7*24*time.Hour))
+	if err != nil {
+		t.Fatalf("Prune: %v", err)
+	}
+	if deleted != 1 {
+		t.Errorf("deleted = %d, want 1", deleted)
+	}
+
+	var remaining []string
+	if err := db.Model(&model.OutboxEvent{}).Order("created_at").Pluck("id", &remaining).Error; err != nil {
+		t.Fatalf("list outbox: %v", err)
+	}
+	if len(remaining) != 2 || remaining[0] != ids[1] || remaining[1] != ids[2] {
+		t.Errorf("remaining = %v, want %v", remaining, ids[1:])
+	}
+}
+
+func contains(values []int, v int) bool {
+	for _, value := range values {
+		if value == v {
+			return true
+		}
+	}
+	return false
+}
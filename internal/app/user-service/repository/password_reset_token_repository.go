@@ -0,0 +1,76 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/golang-standards/project-layout/internal/app/user-service/model"
+	"github.com/golang-standards/project-layout/internal/pkg/database"
+	"gorm.io/gorm"
+)
+
+var ErrTokenNotFound = errors.New("token not found")
+
+// PasswordResetTokenRepository defines the interface for password reset token storage
+type PasswordResetTokenRepository interface {
+	Create(ctx context.Context, token *model.PasswordResetToken) error
+	GetByHash(ctx context.Context, tokenHash string) (*model.PasswordResetToken, error)
+	MarkUsed(ctx context.Context, id string) error
+}
+
+type passwordResetTokenRepository struct {
+	db *gorm.DB
+}
+
+// NewPasswordResetTokenRepository creates a new instance of PasswordResetTokenRepository
+func NewPasswordResetTokenRepository(db *gorm.DB) PasswordResetTokenRepository {
+	return &passwordResetTokenRepository{db: db}
+}
+
+// Create stores a new password reset token
+func (r *passwordResetTokenRepository) Create(ctx context.Context, token *model.PasswordResetToken) error {
+	if err := r.db.WithContext(ctx).Create(token).Error; err != nil {
+		return fmt.Errorf("failed to create password reset token: %w", err)
+	}
+	database.MarkWrite(ctx)
+
+	return nil
+}
+
+// GetByHash retrieves a token by its hash
+func (r *passwordResetTokenRepository) GetByHash(ctx context.Context, tokenHash string) (*model.PasswordResetToken, error) {
+	var token model.PasswordResetToken
+	if err := database.Reader(ctx, r.db).Where("token_hash = ?", tokenHash).First(&token).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrTokenNotFound
+		}
+		return nil, fmt.Errorf("failed to get password reset token: %w", err)
+	}
+
+	return &token, nil
+}
+
+// MarkUsed atomically consumes an unused token, returning ErrTokenNotFound if it was already used
+func (r *passwordResetTokenRepository) MarkUsed(ctx context.Context, id string) error {
+	result := r.db.WithContext(ctx).Model(&model.PasswordResetToken{}).
+		Where("id = ? AND used_at IS NULL", id).
+		Update("used_at", time.Now().UTC())
+	if result.Error != nil {
+		return fmt.Errorf("failed to mark password reset token used: %w", result.Error)
+	}
+	database.MarkWrite(ctx)
+
+	if result.RowsAffected == 0 {
+		return ErrTokenNotFound
+	}
+
+	return nil
+}
+
+// MarkResetTokenUsed consumes a password reset token. Call it on the repository passed to a
+// WithTx callback so the token is only consumed if the password change commits.
+func (r *userRepository) MarkResetTokenUsed(ctx context.Context, id string) error {
+	return NewPasswordResetTokenRepository(r.db).MarkUsed(ctx, id)
+}
@@ -2,36 +2,107 @@ package repository
 
 import (
 	"context"
+	"encoding/base64"
+	"encoding/json"
 	"errors"
 	"fmt"
+	"strings"
+	"time"
 
 	"github.com/golang-standards/project-layout/internal/app/user-service/model"
+	"github.com/golang-standards/project-layout/internal/pkg/apperror"
+	"github.com/golang-standards/project-layout/internal/pkg/audit"
+	"github.com/golang-standards/project-layout/internal/pkg/database"
+	"github.com/golang-standards/project-layout/internal/pkg/events"
+	"github.com/golang-standards/project-layout/internal/pkg/logger"
+	"github.com/golang-standards/project-layout/internal/pkg/validation"
+	"github.com/golang-standards/project-layout/pkg/pagination"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 var (
-	ErrUserNotFound      = errors.New("user not found")
-	ErrUserAlreadyExists = errors.New("user already exists")
+	ErrUserNotFound      = apperror.New(apperror.CodeNotFound, "user not found")
+	ErrUserAlreadyExists = apperror.New(apperror.CodeAlreadyExists, "user already exists")
 	ErrInvalidUserData   = errors.New("invalid user data")
+	ErrInvalidCursor     = apperror.New(apperror.CodeInvalidArgument, "invalid cursor")
+	ErrInvalidSort       = apperror.New(apperror.CodeInvalidArgument, "invalid sort")
+	ErrDuplicateName     = apperror.New(apperror.CodeAlreadyExists, "a user with this name already exists in the organization")
+	ErrConflict          = apperror.New(apperror.CodeConflict, "user was modified concurrently, re-read and retry")
 )
 
+// sortableColumns whitelists the columns List can order by
+var sortableColumns = map[string]bool{
+	"created_at": true,
+	"updated_at": true,
+	"email":      true,
+	"last_name":  true,
+	"status":     true,
+}
+
+// ListFilter restricts which users are listed
+type ListFilter struct {
+	// Query matches a substring of the first name, last name, or email
+	Query string
+	// Status, when set, only matches users with that status
+	Status model.UserStatus
+	// Date ranges; After is inclusive, Before exclusive, and zero values are unbounded
+	CreatedAfter  time.Time
+	CreatedBefore time.Time
+	UpdatedAfter  time.Time
+	UpdatedBefore time.Time
+	// IncludeDeleted also returns soft-deleted users
+	IncludeDeleted bool
+}
+
+// SortKey is a single column in a list ordering
+type SortKey struct {
+	Field string
+	// Order is "asc" or "desc"; empty defaults to "desc"
+	Order string
+}
+
 // UserRepository defines the interface for user data operations
 type UserRepository interface {
 	Create(ctx context.Context, user *model.User) error
+	CreateBatch(ctx context.Context, users []*model.User) error
 	GetByID(ctx context.Context, id string) (*model.User, error)
+	GetByIDs(ctx context.Context, ids []string) ([]*model.User, error)
 	GetByEmail(ctx context.Context, email string) (*model.User, error)
-	Update(ctx context.Context, user *model.User) error
-	Delete(ctx context.Context, id string) error
-	List(ctx context.Context, page, pageSize int, filter string) ([]*model.User, int64, error)
+	Update(ctx context.Context, user *model.User, fields []string) error
+	Delete(ctx context.Context, id, reason string) error
+	Restore(ctx context.Context, id string) (*model.User, error)
+	Purge(ctx context.Context, id string) error
+	RecordFailedLogin(ctx context.Context, id string, now, windowStart time.Time) (int, error)
+	Lock(ctx context.Context, id string, until time.Time) error
+	ResetFailedLogins(ctx context.Context, id string) error
+	List(ctx context.Context, params pagination.Params, filter ListFilter, sort []SortKey) ([]*model.User, int64, error)
+	ListCursor(ctx context.Context, cursor string, limit int, filter ListFilter) ([]*model.User, string, error)
+	Count(ctx context.Context, filter ListFilter) (int64, error)
+	Stream(ctx context.Context, filter ListFilter, batchSize int, fn func([]*model.User) error) error
+	AddOutboxEvents(ctx context.Context, evts []events.Event) error
+	AddAuditEvent(ctx context.Context, event audit.Event) error
+	MarkResetTokenUsed(ctx context.Context, id string) error
+	WithTx(ctx context.Context, fn func(txRepo UserRepository) error) error
 }
 
 type userRepository struct {
-	db *gorm.DB
+	db    *gorm.DB
+	retry database.RetryPolicy
+}
+
+// NewUserRepository creates a new instance of UserRepository.
+// Writes that fail with an error classified as retryable by the policy are retried.
+func NewUserRepository(db *gorm.DB, retry database.RetryPolicy) UserRepository {
+	return &userRepository{db: db, retry: retry}
 }
 
-// NewUserRepository creates a new instance of UserRepository
-func NewUserRepository(db *gorm.DB) UserRepository {
-	return &userRepository{db: db}
+// WithTx runs fn in a database transaction with a repository bound to it. The transaction
+// commits if fn returns nil and rolls back if fn fails or ctx is cancelled.
+func (r *userRepository) WithTx(ctx context.Context, fn func(txRepo UserRepository) error) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		return fn(&userRepository{db: tx, retry: r.retry})
+	})
 }
 
 // Create creates a new user
@@ -40,23 +111,59 @@ func (r *userRepository) Create(ctx context.Context, user *model.User) error {
 		return ErrInvalidUserData
 	}
 
-	// Check if user already exists
-	var existingUser model.User
-	if err := r.db.WithContext(ctx).Where("email = ?", user.Email).First(&existingUser).Error; err == nil {
-		return ErrUserAlreadyExists
+	// The unique indexes on email and canonical_email reject duplicates atomically,
+	// including aliases of an existing address
+	err := database.Retry(ctx, r.retry, func() error {
+		return r.db.WithContext(ctx).Create(user).Error
+	})
+	if err != nil {
+		if database.IsUniqueViolation(err, database.TenantNameIndex) {
+			return ErrDuplicateName
+		}
+		if database.IsUniqueViolation(err, "") {
+			return ErrUserAlreadyExists
+		}
+		logger.FromContext(ctx).Debug("Insert into users failed", "error", err)
+		return fmt.Errorf("failed to create user: %w", err)
 	}
+	database.MarkWrite(ctx)
 
-	if err := r.db.WithContext(ctx).Create(user).Error; err != nil {
-		return fmt.Errorf("failed to create user: %w", err)
+	return nil
+}
+
+// createBatchSize is the number of rows per INSERT in CreateBatch
+const createBatchSize = 100
+
+// CreateBatch inserts users in a single transaction; either all are created or none
+func (r *userRepository) CreateBatch(ctx context.Context, users []*model.User) error {
+	if len(users) == 0 {
+		return nil
 	}
 
+	err := database.Retry(ctx, r.retry, func() error {
+		return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+			return tx.CreateInBatches(users, createBatchSize).Error
+		})
+	})
+	if err != nil {
+		if database.IsUniqueViolation(err, database.TenantNameIndex) {
+			return ErrDuplicateName
+		}
+		if database.IsUniqueViolation(err, "") {
+			return ErrUserAlreadyExists
+		}
+		logger.FromContext(ctx).Debug("Batch insert into users failed", "error", err, "count", len(users))
+		return fmt.Errorf("failed to create users: %w", err)
+	}
+	database.MarkWrite(ctx)
+
 	return nil
 }
 
 // GetByID retrieves a user by ID
 func (r *userRepository) GetByID(ctx context.Context, id string) (*model.User, error) {
 	var user model.User
-	if err := r.db.WithContext(ctx).Where("id = ?", id).First(&user).Error; err != nil {
+	if err := database.Reader(ctx, r.db).Where("id = ?", id).First(&user).Error; err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
 			return nil, ErrUserNotFound
 		}
@@ -66,10 +173,24 @@ func (r *userRepository) GetByID(ctx context.Context, id string) (*model.User, e
 	return &user, nil
 }
 
-// GetByEmail retrieves a user by email
+// GetByIDs retrieves the users matching ids in a single query, in no particular order
+func (r *userRepository) GetByIDs(ctx context.Context, ids []string) ([]*model.User, error) {
+	var users []*model.User
+	if len(ids) == 0 {
+		return users, nil
+	}
+
+	if err := database.Reader(ctx, r.db).Where("id IN ?", ids).Find(&users).Error; err != nil {
+		return nil, fmt.Errorf("failed to get users: %w", err)
+	}
+
+	return users, nil
+}
+
+// GetByEmail retrieves a user by email, normalized as on write so lookups are case-insensitive
 func (r *userRepository) GetByEmail(ctx context.Context, email string) (*model.User, error) {
 	var user model.User
-	if err := r.db.WithContext(ctx).Where("email = ?", email).First(&user).Error; err != nil {
+	if err := database.Reader(ctx, r.db).Where("email = ?", validation.NormalizeEmail(email)).First(&user).Error; err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
 			return nil, ErrUserNotFound
 		}
@@ -79,30 +200,71 @@ func (r *userRepository) GetByEmail(ctx context.Context, email string) (*model.U
 	return &user, nil
 }
 
-// Update updates a user
-func (r *userRepository) Update(ctx context.Context, user *model.User) error {
-	if user == nil || user.ID == "" {
+// Update writes the given columns of user, including zero values so fields can be cleared.
+// Columns not listed are left untouched. The write only applies if the stored version still
+// matches user.Version, which is then incremented; otherwise it returns ErrConflict.
+func (r *userRepository) Update(ctx context.Context, user *model.User, fields []string) error {
+	if user == nil || user.ID == "" || len(fields) == 0 {
 		return ErrInvalidUserData
 	}
 
-	result := r.db.WithContext(ctx).Model(user).Updates(user)
-	if result.Error != nil {
-		return fmt.Errorf("failed to update user: %w", result.Error)
+	expected := user.Version
+	user.Version = expected + 1
+	columns := append(append([]string(nil), fields...), "version")
+
+	var result *gorm.DB
+	err := database.Retry(ctx, r.retry, func() error {
+		result = r.db.WithContext(ctx).Model(user).
+			Where("version = ?", expected).
+			Select(columns).
+			Updates(user)
+		return result.Error
+	})
+	if err != nil || result.RowsAffected == 0 {
+		user.Version = expected
+	}
+	if err != nil {
+		if database.IsUniqueViolation(err, database.TenantNameIndex) {
+			return ErrDuplicateName
+		}
+		if database.IsUniqueViolation(err, "") {
+			return ErrUserAlreadyExists
+		}
+		logger.FromContext(ctx).Debug("Update of users failed", "error", err, "user_id", user.ID)
+		return fmt.Errorf("failed to update user: %w", err)
 	}
+	database.MarkWrite(ctx)
 
 	if result.RowsAffected == 0 {
+		var count int64
+		if err := database.Primary(ctx, r.db).Model(&model.User{}).Where("id = ?", user.ID).Count(&count).Error; err != nil {
+			return fmt.Errorf("failed to check user: %w", err)
+		}
+		if count > 0 {
+			return ErrConflict
+		}
 		return ErrUserNotFound
 	}
 
 	return nil
 }
 
-// Delete deletes a user (soft delete)
-func (r *userRepository) Delete(ctx context.Context, id string) error {
-	result := r.db.WithContext(ctx).Where("id = ?", id).Delete(&model.User{})
-	if result.Error != nil {
-		return fmt.Errorf("failed to delete user: %w", result.Error)
+// Delete soft-deletes a user, recording the optional reason
+func (r *userRepository) Delete(ctx context.Context, id, reason string) error {
+	var result *gorm.DB
+	err := database.Retry(ctx, r.retry, func() error {
+		// Updates keeps GORM's "deleted_at IS NULL" scope, so already deleted users are not found
+		result = r.db.WithContext(ctx).Model(&model.User{}).Where("id = ?", id).Updates(map[string]interface{}{
+			"deleted_at":     time.Now().UTC(),
+			"deleted_reason": reason,
+		})
+		return result.Error
+	})
+	if err != nil {
+		logger.FromContext(ctx).Debug("Delete from users failed", "error", err, "user_id", id)
+		return fmt.Errorf("failed to delete user: %w", err)
 	}
+	database.MarkWrite(ctx)
 
 	if result.RowsAffected == 0 {
 		return ErrUserNotFound
@@ -111,17 +273,167 @@ func (r *userRepository) Delete(ctx context.Context, id string) error {
 	return nil
 }
 
-// List retrieves a paginated list of users
-func (r *userRepository) List(ctx context.Context, page, pageSize int, filter string) ([]*model.User, int64, error) {
+// Purge permanently removes a user, soft-deleted or not, together with its password
+// reset and email verification tokens
+func (r *userRepository) Purge(ctx context.Context, id string) error {
+	var rowsAffected int64
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("user_id = ?", id).Delete(&model.PasswordResetToken{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("user_id = ?", id).Delete(&model.EmailVerificationToken{}).Error; err != nil {
+			return err
+		}
+		result := tx.Unscoped().Where("id = ?", id).Delete(&model.User{})
+		rowsAffected = result.RowsAffected
+		return result.Error
+	})
+	if err != nil {
+		logger.FromContext(ctx).Debug("Purge of user failed", "error", err, "user_id", id)
+		return fmt.Errorf("failed to purge user: %w", err)
+	}
+	database.MarkWrite(ctx)
+
+	if rowsAffected == 0 {
+		return ErrUserNotFound
+	}
+
+	return nil
+}
+
+// Restore brings back a soft-deleted user.
+// It refuses when an active user now holds the same email.
+func (r *userRepository) Restore(ctx context.Context, id string) (*model.User, error) {
+	var user model.User
+	if err := database.Primary(ctx, r.db).Unscoped().
+		Where("id = ? AND deleted_at IS NOT NULL", id).
+		First(&user).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrUserNotFound
+		}
+		return nil, fmt.Errorf("failed to get deleted user: %w", err)
+	}
+
+	var existingUser model.User
+	if err := database.Primary(ctx, r.db).
+		Where("(email = ? OR canonical_email = ?) AND id <> ?", user.Email, user.CanonicalEmail, id).
+		First(&existingUser).Error; err == nil {
+		return nil, ErrUserAlreadyExists
+	}
+
+	err := database.Retry(ctx, r.retry, func() error {
+		return r.db.WithContext(ctx).Unscoped().Model(&model.User{}).
+			Where("id = ?", id).
+			Updates(map[string]interface{}{"deleted_at": nil, "deleted_reason": ""}).Error
+	})
+	if err != nil {
+		logger.FromContext(ctx).Debug("Restore of users failed", "error", err, "user_id", id)
+		return nil, fmt.Errorf("failed to restore user: %w", err)
+	}
+	database.MarkWrite(ctx)
+
+	user.DeletedAt = gorm.DeletedAt{}
+	user.DeletedReason = ""
+	return &user, nil
+}
+
+// RecordFailedLogin atomically counts a failed login and returns the number of failures
+// since windowStart. Failures older than windowStart are forgotten and the count restarts at 1.
+func (r *userRepository) RecordFailedLogin(ctx context.Context, id string, now, windowStart time.Time) (int, error) {
+	var user model.User
+	var result *gorm.DB
+	err := database.Retry(ctx, r.retry, func() error {
+		// UpdateColumns leaves updated_at alone; a failed login is not a profile change
+		result = r.db.WithContext(ctx).Model(&user).
+			Clauses(clause.Returning{Columns: []clause.Column{{Name: "failed_login_attempts"}}}).
+			Where("id = ?", id).
+			UpdateColumns(map[string]interface{}{
+				"failed_login_attempts": gorm.Expr(
+					"CASE WHEN failed_login_window_start > ? THEN failed_login_attempts + 1 ELSE 1 END", windowStart),
+				"failed_login_window_start": gorm.Expr(
+					"CASE WHEN failed_login_window_start > ? THEN failed_login_window_start ELSE ? END", windowStart, now),
+			})
+		return result.Error
+	})
+	if err != nil {
+		logger.FromContext(ctx).Debug("Recording failed login failed", "error", err, "user_id", id)
+		return 0, fmt.Errorf("failed to record failed login: %w", err)
+	}
+	database.MarkWrite(ctx)
+
+	if result.RowsAffected == 0 {
+		return 0, ErrUserNotFound
+	}
+
+	return user.FailedLoginAttempts, nil
+}
+
+// Lock prevents a user from logging in until the given time
+func (r *userRepository) Lock(ctx context.Context, id string, until time.Time) error {
+	var result *gorm.DB
+	err := database.Retry(ctx, r.retry, func() error {
+		result = r.db.WithContext(ctx).Model(&model.User{}).
+			Where("id = ?", id).
+			UpdateColumn("locked_until", until)
+		return result.Error
+	})
+	if err != nil {
+		logger.FromContext(ctx).Debug("Lock of users failed", "error", err, "user_id", id)
+		return fmt.Errorf("failed to lock user: %w", err)
+	}
+	database.MarkWrite(ctx)
+
+	if result.RowsAffected == 0 {
+		return ErrUserNotFound
+	}
+
+	return nil
+}
+
+// ResetFailedLogins clears the failed login count and any lockout
+func (r *userRepository) ResetFailedLogins(ctx context.Context, id string) error {
+	var result *gorm.DB
+	err := database.Retry(ctx, r.retry, func() error {
+		result = r.db.WithContext(ctx).Model(&model.User{}).
+			Where("id = ?", id).
+			UpdateColumns(map[string]interface{}{
+				"failed_login_attempts":     0,
+				"failed_login_window_start": nil,
+				"locked_until":              nil,
+			})
+		return result.Error
+	})
+	if err != nil {
+		logger.FromContext(ctx).Debug("Reset of failed logins failed", "error", err, "user_id", id)
+		return fmt.Errorf("failed to reset failed logins: %w", err)
+	}
+	database.MarkWrite(ctx)
+
+	if result.RowsAffected == 0 {
+		return ErrUserNotFound
+	}
+
+	return nil
+}
+
+// List retrieves a paginated list of users ordered by the given sort keys
+func (r *userRepository) List(ctx context.Context, params pagination.Params, filter ListFilter, sort []SortKey) ([]*model.User, int64, error) {
 	var users []*model.User
 	var total int64
 
-	query := r.db.WithContext(ctx).Model(&model.User{})
+	order, err := orderClause(sort)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	query := database.Reader(ctx, r.db).Model(&model.User{})
 
 	// Apply filter if provided
-	if filter != "" {
-		query = query.Where("first_name LIKE ? OR last_name LIKE ? OR email LIKE ?",
-			"%"+filter+"%", "%"+filter+"%", "%"+filter+"%")
+	query = applyFilter(query, filter)
+
+	// Skip queries once the caller has gone away
+	if err := ctx.Err(); err != nil {
+		return nil, 0, err
 	}
 
 	// Count total records
@@ -129,11 +441,170 @@ func (r *userRepository) List(ctx context.Context, page, pageSize int, filter st
 		return nil, 0, fmt.Errorf("failed to count users: %w", err)
 	}
 
+	if err := ctx.Err(); err != nil {
+		return nil, 0, err
+	}
+
 	// Apply pagination
-	offset := (page - 1) * pageSize
-	if err := query.Offset(offset).Limit(pageSize).Find(&users).Error; err != nil {
+	if err := query.Order(order).Offset(params.Offset()).Limit(params.Limit()).Find(&users).Error; err != nil {
 		return nil, 0, fmt.Errorf("failed to list users: %w", err)
 	}
 
 	return users, total, nil
 }
+
+// Count returns the number of users matching filter
+func (r *userRepository) Count(ctx context.Context, filter ListFilter) (int64, error) {
+	var total int64
+	query := applyFilter(database.Reader(ctx, r.db).Model(&model.User{}), filter)
+	if err := query.Count(&total).Error; err != nil {
+		return 0, fmt.Errorf("failed to count users: %w", err)
+	}
+	return total, nil
+}
+
+// ListCursor retrieves users after the given cursor, ordered by creation time and ID.
+// It returns the cursor for the next page, or an empty string when there are no more users.
+func (r *userRepository) ListCursor(ctx context.Context, cursor string, limit int, filter ListFilter) ([]*model.User, string, error) {
+	query := applyFilter(database.Reader(ctx, r.db).Model(&model.User{}), filter)
+
+	if cursor != "" {
+		after, err := decodeCursor(cursor)
+		if err != nil {
+			return nil, "", err
+		}
+		query = query.Where("(created_at, id) > (?, ?)", after.CreatedAt, after.ID)
+	}
+
+	if err := ctx.Err(); err != nil {
+		return nil, "", err
+	}
+
+	// Fetch one extra row to know whether another page exists
+	var users []*model.User
+	if err := query.Order("created_at ASC, id ASC").Limit(limit + 1).Find(&users).Error; err != nil {
+		return nil, "", fmt.Errorf("failed to list users: %w", err)
+	}
+
+	if len(users) <= limit {
+		return users, "", nil
+	}
+
+	users = users[:limit]
+	last := users[len(users)-1]
+	return users, encodeCursor(userCursor{CreatedAt: last.CreatedAt, ID: last.ID}), nil
+}
+
+// likeEscaper escapes LIKE/ILIKE metacharacters so they match literally
+var likeEscaper = strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`)
+
+// Stream reads all users matching filter in batches of batchSize, calling fn for each batch.
+// It stops at the first error from fn or when ctx is cancelled.
+func (r *userRepository) Stream(ctx context.Context, filter ListFilter, batchSize int, fn func([]*model.User) error) error {
+	var users []*model.User
+	query := applyFilter(database.Reader(ctx, r.db).Model(&model.User{}), filter)
+	result := query.FindInBatches(&users, batchSize, func(tx *gorm.DB, batch int) error {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		return fn(users)
+	})
+	if result.Error != nil {
+		return fmt.Errorf("failed to stream users: %w", result.Error)
+	}
+
+	return nil
+}
+
+// applyFilter restricts the query to users matching filter; the text query matches
+// literally and case-insensitively. SQLite has no ILIKE, and its LIKE only folds ASCII case.
+func applyFilter(query *gorm.DB, filter ListFilter) *gorm.DB {
+	if filter.IncludeDeleted {
+		query = query.Unscoped()
+	}
+	if filter.Status != "" {
+		query = query.Where("status = ?", filter.Status)
+	}
+	if !filter.CreatedAfter.IsZero() {
+		query = query.Where("created_at >= ?", filter.CreatedAfter)
+	}
+	if !filter.CreatedBefore.IsZero() {
+		query = query.Where("created_at < ?", filter.CreatedBefore)
+	}
+	if !filter.UpdatedAfter.IsZero() {
+		query = query.Where("updated_at >= ?", filter.UpdatedAfter)
+	}
+	if !filter.UpdatedBefore.IsZero() {
+		query = query.Where("updated_at < ?", filter.UpdatedBefore)
+	}
+	if filter.Query == "" {
+		return query
+	}
+	like := "ILIKE"
+	if database.IsSQLite(query) {
+		like = "LIKE"
+	}
+	pattern := "%" + likeEscaper.Replace(filter.Query) + "%"
+	return query.Where(fmt.Sprintf(`(first_name %[1]s ? ESCAPE '\' OR last_name %[1]s ? ESCAPE '\' OR email %[1]s ? ESCAPE '\')`, like),
+		pattern, pattern, pattern)
+}
+
+// orderClause builds a whitelisted multi-column ORDER BY clause with an id tiebreaker.
+// No keys defaults to created_at DESC.
+func orderClause(sort []SortKey) (string, error) {
+	if len(sort) == 0 {
+		sort = []SortKey{{Field: "created_at"}}
+	}
+
+	seen := make(map[string]bool, len(sort))
+	parts := make([]string, 0, len(sort)+1)
+	for _, key := range sort {
+		column := strings.ToLower(key.Field)
+		if !sortableColumns[column] {
+			return "", fmt.Errorf("%w: unsupported sort field %q", ErrInvalidSort, key.Field)
+		}
+		if seen[column] {
+			return "", fmt.Errorf("%w: duplicate sort field %q", ErrInvalidSort, key.Field)
+		}
+		seen[column] = true
+
+		direction := strings.ToUpper(key.Order)
+		switch direction {
+		case "":
+			direction = "DESC"
+		case "ASC", "DESC":
+		default:
+			return "", fmt.Errorf("%w: unsupported sort order %q", ErrInvalidSort, key.Order)
+		}
+
+		parts = append(parts, column+" "+direction)
+	}
+	parts = append(parts, "id ASC")
+
+	return strings.Join(parts, ", "), nil
+}
+
+// userCursor is the position of the last user returned in a keyset page
+type userCursor struct {
+	CreatedAt time.Time `json:"c"`
+	ID        string    `json:"i"`
+}
+
+// encodeCursor serializes a cursor into an opaque string
+func encodeCursor(c userCursor) string {
+	data, _ := json.Marshal(c)
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+// decodeCursor parses an opaque cursor string
+func decodeCursor(cursor string) (userCursor, error) {
+	var c userCursor
+	data, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return c, ErrInvalidCursor
+	}
+	if err := json.Unmarshal(data, &c); err != nil || c.ID == "" || c.CreatedAt.IsZero() {
+		return c, ErrInvalidCursor
+	}
+	return c, nil
+}
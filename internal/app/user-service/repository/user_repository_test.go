@@ -0,0 +1,630 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/golang-standards/project-layout/internal/app/user-service/model"
+	"github.com/golang-standards/project-layout/internal/pkg/database"
+	"github.com/golang-standards/project-layout/pkg/pagination"
+	"github.com/jackc/pgx/v5/pgconn"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+)
+
+// backends returns a fresh repository per storage backend, so each test runs against
+// both the SQLite schema built by the migrations and the in-memory repository
+func backends(t *testing.T) map[string]func() UserRepository {
+	t.Helper()
+	return map[string]func() UserRepository{
+		"sqlite": func() UserRepository { return NewUserRepository(newTestSQLiteDB(t), database.RetryPolicy{}) },
+		"memory": NewInMemoryUserRepository,
+	}
+}
+
+// testUser returns a user with the given email, name and organization
+func testUser(email, firstName, lastName string, orgID *string) *model.User {
+	return &model.User{
+		Email:          email,
+		CanonicalEmail: email,
+		Password:       "hash",
+		FirstName:      firstName,
+		LastName:       lastName,
+		OrgID:          orgID,
+		Status:         model.UserStatusActive,
+		Role:           model.UserRoleUser,
+	}
+}
+
+func TestCreateTenantNames(t *testing.T) {
+	orgA := "7f0b3c1e-9a53-4d7e-8f54-3c1d2b9a0a01"
+	orgB := "7f0b3c1e-9a53-4d7e-8f54-3c1d2b9a0a02"
+
+	tests := []struct {
+		name    string
+		first   *string
+		second  *string
+		last    string
+		wantErr error
+	}{
+		{name: "same organization, name differs in case", first: &orgA, second: &orgA, last: "DOE", wantErr: ErrDuplicateName},
+		{name: "different organizations", first: &orgA, second: &orgB, last: "Doe"},
+		{name: "no organization", last: "Doe"},
+		{name: "same organization, different name", first: &orgA, second: &orgA, last: "Roe"},
+	}
+
+	for backend, newRepo := range backends(t) {
+		for _, tt := range tests {
+			t.Run(backend+"/"+tt.name, func(t *testing.T) {
+				repo := newRepo()
+				ctx := context.Background()
+
+				if err := repo.Create(ctx, testUser("jane@example.com", "Jane", "Doe", tt.first)); err != nil {
+					t.Fatalf("first Create: %v", err)
+				}
+				err := repo.Create(ctx, testUser("other@example.com", "jane", tt.last, tt.second))
+				if !errors.Is(err, tt.wantErr) {
+					t.Errorf("second Create = %v, want %v", err, tt.wantErr)
+				}
+			})
+		}
+	}
+}
+
+func TestCreateDuplicateEmail(t *testing.T) {
+	tests := []struct {
+		name      string
+		email     string
+		canonical string
+		wantErr   error
+	}{
+		{name: "same email", email: "jane@example.com", canonical: "jane2@example.com", wantErr: ErrUserAlreadyExists},
+		{name: "same canonical email", email: "jane+x@example.com", canonical: "jane@example.com", wantErr: ErrUserAlreadyExists},
+		{name: "different email", email: "john@example.com", canonical: "john@example.com"},
+	}
+
+	for backend, newRepo := range backends(t) {
+		for _, tt := range tests {
+			t.Run(backend+"/"+tt.name, func(t *testing.T) {
+				repo := newRepo()
+				ctx := context.Background()
+
+				if err := repo.Create(ctx, testUser("jane@example.com", "Jane", "Doe", nil)); err != nil {
+					t.Fatalf("first Create: %v", err)
+				}
+				user := testUser(tt.email, "Other", "User", nil)
+				user.CanonicalEmail = tt.canonical
+				if err := repo.Create(ctx, user); !errors.Is(err, tt.wantErr) {
+					t.Errorf("second Create = %v, want %v", err, tt.wantErr)
+				}
+			})
+		}
+	}
+}
+
+func TestDeleteAndRestore(t *testing.T) {
+	for backend, newRepo := range backends(t) {
+		t.Run(backend, func(t *testing.T) {
+			repo := newRepo()
+			ctx := context.Background()
+
+			user := testUser("jane@example.com", "Jane", "Doe", nil)
+			if err := repo.Create(ctx, user); err != nil {
+				t.Fatalf("Create: %v", err)
+			}
+			if err := repo.Delete(ctx, user.ID, "requested"); err != nil {
+				t.Fatalf("Delete: %v", err)
+			}
+
+			if _, err := repo.GetByID(ctx, user.ID); !errors.Is(err, ErrUserNotFound) {
+				t.Errorf("GetByID after Delete = %v, want %v", err, ErrUserNotFound)
+			}
+			if err := repo.Delete(ctx, user.ID, "again"); !errors.Is(err, ErrUserNotFound) {
+				t.Errorf("second Delete = %v, want %v", err, ErrUserNotFound)
+			}
+			for _, includeDeleted := range []bool{false, true} {
+				_, total, err := repo.List(ctx, pagination.NewParams(1, 10), ListFilter{IncludeDeleted: includeDeleted}, nil)
+				if err != nil {
+					t.Fatalf("List: %v", err)
+				}
+				if want := map[bool]int64{false: 0, true: 1}[includeDeleted]; total != want {
+					t.Errorf("List total with IncludeDeleted=%v = %d, want %d", includeDeleted, total, want)
+				}
+			}
+
+			restored, err := repo.Restore(ctx, user.ID)
+			if err != nil {
+				t.Fatalf("Restore: %v", err)
+			}
+			if restored.ID != user.ID || restored.DeletedAt.Valid {
+				t.Errorf("Restore = %+v, want the live user %s", restored, user.ID)
+			}
+			if _, err := repo.Restore(ctx, user.ID); !errors.Is(err, ErrUserNotFound) {
+				t.Errorf("second Restore = %v, want %v", err, ErrUserNotFound)
+			}
+		})
+	}
+}
+
+func TestListQueryIgnoresCase(t *testing.T) {
+	tests := []struct {
+		query string
+		want  int64
+	}{
+		{query: "jane", want: 1},
+		{query: "DOE", want: 2},
+		{query: "EXAMPLE.COM", want: 2},
+		{query: "nobody", want: 0},
+	}
+
+	for backend, newRepo := range backends(t) {
+		repo := newRepo()
+		ctx := context.Background()
+		for _, user := range []*model.User{
+			testUser("jane@example.com", "Jane", "Doe", nil),
+			testUser("john@example.com", "John", "Doe", nil),
+		} {
+			if err := repo.Create(ctx, user); err != nil {
+				t.Fatalf("Create: %v", err)
+			}
+		}
+
+		for _, tt := range tests {
+			t.Run(backend+"/"+tt.query, func(t *testing.T) {
+				_, total, err := repo.List(ctx, pagination.NewParams(1, 10), ListFilter{Query: tt.query}, nil)
+				if err != nil {
+					t.Fatalf("List: %v", err)
+				}
+				if total != tt.want {
+					t.Errorf("List(%q) total = %d, want %d", tt.query, total, tt.want)
+				}
+			})
+		}
+	}
+}
+
+func TestListStopsWhenCancelled(t *testing.T) {
+	tests := []struct {
+		name        string
+		cancelAfter int // queries to allow before cancelling; -1 cancels up front
+		list        func(ctx context.Context, repo UserRepository) error
+		wantQueries int
+	}{
+		{
+			name:        "List cancelled before counting",
+			cancelAfter: -1,
+			list: func(ctx context.Context, repo UserRepository) error {
+				_, _, err := repo.List(ctx, pagination.NewParams(1, 10), ListFilter{}, nil)
+				return err
+			},
+		},
+		{
+			name:        "List cancelled after counting",
+			cancelAfter: 1,
+			list: func(ctx context.Context, repo UserRepository) error {
+				_, _, err := repo.List(ctx, pagination.NewParams(1, 10), ListFilter{}, nil)
+				return err
+			},
+			wantQueries: 1,
+		},
+		{
+			name:        "ListCursor cancelled",
+			cancelAfter: -1,
+			list: func(ctx context.Context, repo UserRepository) error {
+				_, _, err := repo.ListCursor(ctx, "", 10, ListFilter{})
+				return err
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db := newTestSQLiteDB(t)
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+			if tt.cancelAfter < 0 {
+				cancel()
+			}
+
+			queries := 0
+			err := db.Callback().Query().After("gorm:query").Register("test:count_queries", func(*gorm.DB) {
+				queries++
+				if queries == tt.cancelAfter {
+					cancel()
+				}
+			})
+			if err != nil {
+				t.Fatalf("register callback: %v", err)
+			}
+
+			err = tt.list(ctx, NewUserRepository(db, database.RetryPolicy{}))
+			if !errors.Is(err, context.Canceled) {
+				t.Errorf("err = %v, want %v", err, context.Canceled)
+			}
+			if queries != tt.wantQueries {
+				t.Errorf("ran %d queries, want %d", queries, tt.wantQueries)
+			}
+		})
+	}
+}
+
+func TestListStatusAndQuery(t *testing.T) {
+	tests := []struct {
+		name   string
+		filter ListFilter
+		want   []string
+	}{
+		{name: "status only", filter: ListFilter{Status: model.UserStatusSuspended}, want: []string{"jane.doe@example.com", "john.roe@example.com"}},
+		{name: "query only", filter: ListFilter{Query: "doe"}, want: []string{"jane.doe@example.com", "mary.doe@example.com"}},
+		{name: "status and query", filter: ListFilter{Status: model.UserStatusSuspended, Query: "doe"}, want: []string{"jane.doe@example.com"}},
+		{name: "status and query, no match", filter: ListFilter{Status: model.UserStatusInactive, Query: "doe"}},
+	}
+
+	for backend, newRepo := range backends(t) {
+		repo := newRepo()
+		ctx := context.Background()
+		for _, u := range []struct {
+			email, first, last string
+			status             model.UserStatus
+		}{
+			{"jane.doe@example.com", "Jane", "Doe", model.UserStatusSuspended},
+			{"mary.doe@example.com", "Mary", "Doe", model.UserStatusActive},
+			{"john.roe@example.com", "John", "Roe", model.UserStatusSuspended},
+		} {
+			user := testUser(u.email, u.first, u.last, nil)
+			user.Status = u.status
+			if err := repo.Create(ctx, user); err != nil {
+				t.Fatalf("Create: %v", err)
+			}
+		}
+
+		for _, tt := range tests {
+			t.Run(backend+"/"+tt.name, func(t *testing.T) {
+				users, total, err := repo.List(ctx, pagination.NewParams(1, 10), tt.filter, []SortKey{{Field: "email", Order: "asc"}})
+				if err != nil {
+					t.Fatalf("List: %v", err)
+				}
+				got := make([]string, len(users))
+				for i, user := range users {
+					got[i] = user.Email
+				}
+				if total != int64(len(tt.want)) || strings.Join(got, ",") != strings.Join(tt.want, ",") {
+					t.Errorf("List = %v (total %d), want %v", got, total, tt.want)
+				}
+			})
+		}
+	}
+}
+
+func TestGetByEmailIgnoresCase(t *testing.T) {
+	tests := []struct {
+		lookup  string
+		wantErr error
+	}{
+		{lookup: "foo@bar.com"},
+		{lookup: "Foo@Bar.com"},
+		{lookup: "  FOO@BAR.COM "},
+		{lookup: "foo@bar.org", wantErr: ErrUserNotFound},
+	}
+
+	for backend, newRepo := range backends(t) {
+		repo := newRepo()
+		ctx := context.Background()
+		stored := testUser("foo@bar.com", "Foo", "Bar", nil)
+		if err := repo.Create(ctx, stored); err != nil {
+			t.Fatalf("Create: %v", err)
+		}
+
+		for _, tt := range tests {
+			t.Run(backend+"/"+tt.lookup, func(t *testing.T) {
+				user, err := repo.GetByEmail(ctx, tt.lookup)
+				if !errors.Is(err, tt.wantErr) {
+					t.Fatalf("GetByEmail(%q) = %v, want %v", tt.lookup, err, tt.wantErr)
+				}
+				if tt.wantErr == nil && user.ID != stored.ID {
+					t.Errorf("GetByEmail(%q) = %s, want %s", tt.lookup, user.ID, stored.ID)
+				}
+			})
+		}
+	}
+}
+
+func TestListQueryMatchesLiterally(t *testing.T) {
+	tests := []struct {
+		query string
+		want  []string
+	}{
+		{query: "JOHN", want: []string{"john@example.com"}},
+		{query: "50%", want: []string{"sale50%@example.com"}},
+		{query: "%", want: []string{"sale50%@example.com"}},
+		{query: "a_b", want: []string{"a_b@example.com"}},
+		{query: `back\`, want: []string{`back\slash@example.com`}},
+	}
+
+	for backend, newRepo := range backends(t) {
+		repo := newRepo()
+		ctx := context.Background()
+		// 500@ and axb@ would match 50% and a_b if the metacharacters were not escaped
+		for _, email := range []string{"john@example.com", "sale50%@example.com", "sale500@example.com", "a_b@example.com", "axb@example.com", `back\slash@example.com`, "backslash@example.com"} {
+			if err := repo.Create(ctx, testUser(email, "", "", nil)); err != nil {
+				t.Fatalf("Create(%s): %v", email, err)
+			}
+		}
+
+		for _, tt := range tests {
+			t.Run(backend+"/"+tt.query, func(t *testing.T) {
+				users, _, err := repo.List(ctx, pagination.NewParams(1, 10), ListFilter{Query: tt.query}, []SortKey{{Field: "email", Order: "asc"}})
+				if err != nil {
+					t.Fatalf("List: %v", err)
+				}
+				got := make([]string, len(users))
+				for i, user := range users {
+					got[i] = user.Email
+				}
+				if strings.Join(got, ",") != strings.Join(tt.want, ",") {
+					t.Errorf("List(%q) = %v, want %v", tt.query, got, tt.want)
+				}
+			})
+		}
+	}
+}
+
+func TestApplyFilterPostgres(t *testing.T) {
+	db, err := gorm.Open(postgres.New(postgres.Config{DSN: "host=localhost"}), &gorm.Config{DryRun: true, DisableAutomaticPing: true})
+	if err != nil {
+		t.Fatalf("open: %v", err)
+	}
+
+	tests := []struct {
+		query       string
+		wantPattern string
+	}{
+		{query: "JOHN", wantPattern: "%JOHN%"},
+		{query: "50%", wantPattern: `%50\%%`},
+		{query: `a_b\`, wantPattern: `%a\_b\\%`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.query, func(t *testing.T) {
+			var users []*model.User
+			stmt := applyFilter(db.Model(&model.User{}), ListFilter{Query: tt.query}).Find(&users).Statement
+
+			if sql := stmt.SQL.String(); !strings.Contains(sql, `email ILIKE $3 ESCAPE '\'`) {
+				t.Errorf("SQL = %s, want a case-insensitive ILIKE with an escape character", sql)
+			}
+			if len(stmt.Vars) != 3 || stmt.Vars[0] != tt.wantPattern {
+				t.Errorf("vars = %v, want the pattern %q three times", stmt.Vars, tt.wantPattern)
+			}
+		})
+	}
+}
+
+func TestCreateDuplicateInsert(t *testing.T) {
+	tests := []struct {
+		name      string
+		insertErr error
+		wantErr   error
+	}{
+		{name: "postgres unique violation", insertErr: &pgconn.PgError{Code: "23505", ConstraintName: "idx_users_email"}, wantErr: ErrUserAlreadyExists},
+		{name: "postgres tenant name violation", insertErr: &pgconn.PgError{Code: "23505", ConstraintName: database.TenantNameIndex}, wantErr: ErrDuplicateName},
+		{name: "other postgres error", insertErr: &pgconn.PgError{Code: "23502"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db := newTestSQLiteDB(t)
+			queries := 0
+			if err := db.Callback().Query().Before("gorm:query").Register("test:count_queries", func(*gorm.DB) { queries++ }); err != nil {
+				t.Fatalf("register callback: %v", err)
+			}
+			// Fail the insert the way Postgres reports it, as if a concurrent create won
+			if err := db.Callback().Create().Before("gorm:create").Register("test:fail_insert", func(tx *gorm.DB) {
+				_ = tx.AddError(tt.insertErr)
+			}); err != nil {
+				t.Fatalf("register callback: %v", err)
+			}
+
+			err := NewUserRepository(db, database.RetryPolicy{}).Create(context.Background(), testUser("jane@example.com", "Jane", "Doe", nil))
+			if tt.wantErr != nil && err != tt.wantErr {
+				t.Errorf("Create = %v, want exactly %v", err, tt.wantErr)
+			}
+			if tt.wantErr == nil && (err == nil || errors.Is(err, ErrUserAlreadyExists) || errors.Is(err, ErrDuplicateName)) {
+				t.Errorf("Create = %v, want a wrapped database error", err)
+			}
+			if queries != 0 {
+				t.Errorf("ran %d SELECTs before inserting, want none", queries)
+			}
+		})
+	}
+}
+
+func TestCreateConcurrentDuplicates(t *testing.T) {
+	for backend, newRepo := range backends(t) {
+		t.Run(backend, func(t *testing.T) {
+			repo := newRepo()
+
+			const creators = 5
+			errs := make(chan error, creators)
+			for i := 0; i < creators; i++ {
+				go func() {
+					errs <- repo.Create(context.Background(), testUser("jane@example.com", "Jane", "Doe", nil))
+				}()
+			}
+
+			created := 0
+			for i := 0; i < creators; i++ {
+				switch err := <-errs; {
+				case err == nil:
+					created++
+				case !errors.Is(err, ErrUserAlreadyExists):
+					t.Errorf("Create = %v, want nil or %v", err, ErrUserAlreadyExists)
+				}
+			}
+			if created != 1 {
+				t.Errorf("created %d users, want 1", created)
+			}
+		})
+	}
+}
+
+func TestDeleteReason(t *testing.T) {
+	tests := []struct {
+		name   string
+		reason string
+	}{
+		{name: "with reason", reason: "requested by the user"},
+		{name: "without reason", reason: ""},
+	}
+
+	for backend, newRepo := range backends(t) {
+		for _, tt := range tests {
+			t.Run(backend+"/"+tt.name, func(t *testing.T) {
+				repo := newRepo()
+				ctx := context.Background()
+
+				user := testUser("jane@example.com", "Jane", "Doe", nil)
+				if err := repo.Create(ctx, user); err != nil {
+					t.Fatalf("Create: %v", err)
+				}
+				if err := repo.Delete(ctx, user.ID, tt.reason); err != nil {
+					t.Fatalf("Delete: %v", err)
+				}
+
+				users, _, err := repo.List(ctx, pagination.NewParams(1, 10), ListFilter{IncludeDeleted: true}, nil)
+				if err != nil {
+					t.Fatalf("List: %v", err)
+				}
+				if len(users) != 1 || users[0].DeletedReason != tt.reason {
+					t.Fatalf("List with IncludeDeleted = %+v, want one user with reason %q", users, tt.reason)
+				}
+
+				restored, err := repo.Restore(ctx, user.ID)
+				if err != nil {
+					t.Fatalf("Restore: %v", err)
+				}
+				if restored.DeletedReason != "" {
+					t.Errorf("DeletedReason after Restore = %q, want empty", restored.DeletedReason)
+				}
+			})
+		}
+	}
+}
+
+func TestUpdateWritesZeroValues(t *testing.T) {
+	tests := []struct {
+		name   string
+		fields []string
+		change func(u *model.User)
+		check  func(t *testing.T, got *model.User)
+	}{
+		{
+			name:   "clear phone",
+			fields: []string{"phone"},
+			change: func(u *model.User) { u.Phone = "" },
+			check: func(t *testing.T, got *model.User) {
+				if got.Phone != "" {
+					t.Errorf("phone = %q, want empty", got.Phone)
+				}
+			},
+		},
+		{
+			name:   "deactivate",
+			fields: []string{"status"},
+			change: func(u *model.User) { u.Status = model.UserStatusInactive },
+			check: func(t *testing.T, got *model.User) {
+				if got.Status != model.UserStatusInactive {
+					t.Errorf("status = %q, want %q", got.Status, model.UserStatusInactive)
+				}
+			},
+		},
+		{
+			name:   "unverify email",
+			fields: []string{"email_verified"},
+			change: func(u *model.User) { u.EmailVerified = false },
+			check: func(t *testing.T, got *model.User) {
+				if got.EmailVerified {
+					t.Error("email_verified = true, want false")
+				}
+			},
+		},
+		{
+			name:   "unlisted fields are kept",
+			fields: []string{"first_name"},
+			change: func(u *model.User) { u.FirstName = "Janet"; u.Phone = ""; u.LastName = "" },
+			check: func(t *testing.T, got *model.User) {
+				if got.FirstName != "Janet" || got.LastName != "Doe" || got.Phone != "+15555550100" {
+					t.Errorf("name/phone = %q %q %q, want %q %q %q", got.FirstName, got.LastName, got.Phone, "Janet", "Doe", "+15555550100")
+				}
+			},
+		},
+	}
+
+	for backend, newRepo := range backends(t) {
+		for _, tt := range tests {
+			t.Run(backend+"/"+tt.name, func(t *testing.T) {
+				repo := newRepo()
+				ctx := context.Background()
+
+				user := testUser("jane@example.com", "Jane", "Doe", nil)
+				user.Phone = "+15555550100"
+				user.EmailVerified = true
+				if err := repo.Create(ctx, user); err != nil {
+					t.Fatalf("Create: %v", err)
+				}
+
+				tt.change(user)
+				if err := repo.Update(ctx, user, tt.fields); err != nil {
+					t.Fatalf("Update: %v", err)
+				}
+
+				got, err := repo.GetByID(ctx, user.ID)
+				if err != nil {
+					t.Fatalf("GetByID: %v", err)
+				}
+				tt.check(t, got)
+			})
+		}
+	}
+}
+
+func TestWithTxKeepsRetryPolicy(t *testing.T) {
+	errTransient := errors.New("transient")
+
+	tests := []struct {
+		name     string
+		failures int
+		wantErr  error
+	}{
+		{name: "retried insert succeeds", failures: 2},
+		{name: "attempts exhausted", failures: 3, wantErr: errTransient},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db := newTestSQLiteDB(t)
+			inserts := 0
+			if err := db.Callback().Create().Before("gorm:create").Register("test:fail_insert", func(tx *gorm.DB) {
+				inserts++
+				if inserts <= tt.failures {
+					_ = tx.AddError(errTransient)
+				}
+			}); err != nil {
+				t.Fatalf("register callback: %v", err)
+			}
+			policy := database.RetryPolicy{MaxAttempts: 3, IsRetryable: func(err error) bool { return errors.Is(err, errTransient) }}
+			repo := NewUserRepository(db, policy)
+
+			err := repo.WithTx(context.Background(), func(txRepo UserRepository) error {
+				return txRepo.Create(context.Background(), testUser("jane@example.com", "Jane", "Doe", nil))
+			})
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("WithTx = %v, want %v", err, tt.wantErr)
+			}
+			if want := min(tt.failures+1, policy.MaxAttempts); inserts != want {
+				t.Errorf("insert attempts = %d, want %d", inserts, want)
+			}
+		})
+	}
+}
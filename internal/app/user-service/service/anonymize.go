@@ -0,0 +1,62 @@
+package service
+
+import (
+	"context"
+
+	"github.com/golang-standards/project-layout/internal/app/user-service/model"
+	"github.com/golang-standards/project-layout/internal/app/user-service/repository"
+	"github.com/golang-standards/project-layout/internal/pkg/audit"
+	"github.com/golang-standards/project-layout/internal/pkg/database"
+	"github.com/golang-standards/project-layout/internal/pkg/events"
+)
+
+// anonymizedFields are the columns AnonymizeUser overwrites
+var anonymizedFields = []string{
+	"email", "canonical_email", "first_name", "last_name", "phone", "password", "status", "email_verified",
+}
+
+// AnonymizeUser replaces a user's personal data with tombstone values, clears the password
+// and deactivates the account, keeping the row so references to it stay valid. Anonymizing
+// an anonymized user is a no-op. Soft-deleted users must be restored first.
+func (s *userService) AnonymizeUser(ctx context.Context, id string) (*model.User, error) {
+	ctx, span := tracer.Start(ctx, "UserService.AnonymizeUser")
+	defer span.End()
+
+	s.log(ctx).Info("Anonymizing user", "user_id", id)
+
+	user, err := s.repo.GetByID(database.WithPrimary(ctx), id)
+	if err != nil {
+		s.log(ctx).Error("Failed to get user for anonymization", "error", err, "user_id", id)
+		return nil, err
+	}
+	if user.IsAnonymized() {
+		return user, nil
+	}
+
+	tombstone := model.AnonymizedEmail(user.ID)
+	user.Email = tombstone
+	user.CanonicalEmail = tombstone
+	user.FirstName = ""
+	user.LastName = ""
+	user.Phone = ""
+	// An empty hash never matches, so the account can't be logged into again
+	user.Password = ""
+	user.Status = model.UserStatusInactive
+	user.EmailVerified = false
+	err = s.write(ctx, func(repo repository.UserRepository) ([]events.Event, error) {
+		if err := repo.Update(ctx, user, anonymizedFields); err != nil {
+			return nil, err
+		}
+		return []events.Event{events.New(events.UserUpdated, id, anonymizedFields)}, nil
+	})
+	if err != nil {
+		s.log(ctx).Error("Failed to anonymize user", "error", err, "user_id", id)
+		return nil, err
+	}
+
+	// The event identifies the user by ID only; the replaced values are not recorded
+	s.recordAudit(ctx, audit.NewEvent(ctx, "user.anonymized", id, nil))
+
+	s.log(ctx).Info("User anonymized successfully", "user_id", id)
+	return user, nil
+}
@@ -0,0 +1,55 @@
+package service_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/golang-standards/project-layout/internal/app/user-service/repository/mocks"
+	"github.com/golang-standards/project-layout/internal/app/user-service/service"
+	"github.com/golang-standards/project-layout/internal/pkg/auth"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+)
+
+func TestAuditRecordsImpersonator(t *testing.T) {
+	const method = "/user.v1.UserService/DeleteUser"
+	admin := auth.Principal{UserID: "admin-1", Role: auth.RoleAdmin}
+
+	tests := []struct {
+		name             string
+		impersonate      string
+		wantActor        string
+		wantImpersonator string
+	}{
+		{name: "admin acting as themselves", wantActor: "admin-1"},
+		{name: "admin impersonating a user", impersonate: "user-1", wantActor: "user-1", wantImpersonator: "admin-1"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			log := &auditLog{}
+			repo := &mocks.MockUserRepository{DeleteFunc: func(ctx context.Context, id, reason string) error { return nil }}
+			svc := newTestService(repo, service.Options{Audit: log})
+
+			ctx := auth.WithPrincipal(context.Background(), admin)
+			if tt.impersonate != "" {
+				ctx = metadata.NewIncomingContext(ctx, metadata.Pairs(auth.ImpersonateMetadataKey, tt.impersonate))
+			}
+			interceptor := auth.ImpersonationInterceptor(auth.NewImpersonator(true, []string{method}))
+			_, err := interceptor(ctx, nil, &grpc.UnaryServerInfo{FullMethod: method}, func(ctx context.Context, req interface{}) (interface{}, error) {
+				return nil, svc.DeleteUser(ctx, "user-1", "closed by support")
+			})
+			if err != nil {
+				t.Fatalf("DeleteUser: %v", err)
+			}
+
+			if len(log.events) != 1 {
+				t.Fatalf("audit events = %+v, want one", log.events)
+			}
+			event := log.events[0]
+			if event.ActorID != tt.wantActor || event.ImpersonatorID != tt.wantImpersonator {
+				t.Errorf("actor = %q, impersonator = %q, want %q, %q", event.ActorID, event.ImpersonatorID, tt.wantActor, tt.wantImpersonator)
+			}
+		})
+	}
+}
@@ -0,0 +1,176 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"runtime"
+	"strings"
+	"sync"
+
+	"github.com/golang-standards/project-layout/internal/app/user-service/model"
+	"github.com/golang-standards/project-layout/internal/app/user-service/repository"
+	"github.com/golang-standards/project-layout/internal/pkg/apperror"
+	"github.com/golang-standards/project-layout/internal/pkg/events"
+	"github.com/golang-standards/project-layout/internal/pkg/validation"
+	"golang.org/x/crypto/bcrypt"
+)
+
+// MaxCreateBatchSize caps the number of users accepted by CreateUsersBatch. Every row is
+// hashed with bcrypt, so the cap bounds the CPU one request can take.
+const MaxCreateBatchSize = 100
+
+var ErrBatchTooLarge = apperror.New(apperror.CodeInvalidArgument, "too many users in batch")
+
+// CreateUserInput is a single user to create in CreateUsersBatch
+type CreateUserInput struct {
+	Email     string
+	Password  string
+	FirstName string
+	LastName  string
+	Phone     string
+}
+
+// RowError is a validation failure for one input row
+type RowError struct {
+	Index int
+	Err   error
+}
+
+// BatchValidationError lists every invalid row of a batch; nothing is created when it is returned
+type BatchValidationError struct {
+	Rows []RowError
+}
+
+func (e *BatchValidationError) Error() string {
+	msgs := make([]string, len(e.Rows))
+	for i, row := range e.Rows {
+		msgs[i] = fmt.Sprintf("row %d: %v", row.Index, row.Err)
+	}
+	return "invalid batch: " + strings.Join(msgs, "; ")
+}
+
+// CreateUsersBatch validates every input, hashes passwords concurrently, and creates all users
+// in one transaction. If any row is invalid it returns a *BatchValidationError and creates nothing.
+func (s *userService) CreateUsersBatch(ctx context.Context, inputs []CreateUserInput) ([]*model.User, error) {
+	ctx, span := tracer.Start(ctx, "UserService.CreateUsersBatch")
+	defer span.End()
+
+	s.log(ctx).Info("Creating users in batch", "count", len(inputs))
+
+	if len(inputs) > MaxCreateBatchSize {
+		return nil, fmt.Errorf("%w: got %d, maximum is %d", ErrBatchTooLarge, len(inputs), MaxCreateBatchSize)
+	}
+
+	if err := s.validateBatch(inputs); err != nil {
+		return nil, err
+	}
+
+	hashes, err := hashPasswords(ctx, inputs, s.bcryptCost)
+	if err != nil {
+		s.log(ctx).Error("Failed to hash passwords", "error", err)
+		return nil, err
+	}
+
+	users := make([]*model.User, len(inputs))
+	for i, in := range inputs {
+		users[i] = s.newUser(ctx, in.Email, hashes[i], in.FirstName, in.LastName, in.Phone)
+	}
+
+	err = s.write(ctx, func(repo repository.UserRepository) ([]events.Event, error) {
+		if err := repo.CreateBatch(ctx, users); err != nil {
+			return nil, err
+		}
+		evts := make([]events.Event, len(users))
+		for i, user := range users {
+			evts[i] = events.New(events.UserCreated, user.ID, nil)
+		}
+		return evts, nil
+	})
+	if err != nil {
+		s.log(ctx).Error("Failed to create users in batch", "error", err, "count", len(users))
+		return nil, err
+	}
+
+	// A failed send is not fatal; users can ask for the verification email again
+	if s.verificationTokens != nil {
+		for _, user := range users {
+			if err := s.sendVerification(ctx, user); err != nil {
+				s.log(ctx).Warn("Failed to send verification email", "error", err, "user_id", user.ID)
+			}
+		}
+	}
+
+	s.log(ctx).Info("Users created successfully", "count", len(users))
+	return users, nil
+}
+
+// validateBatch normalizes emails in place and checks every row, including duplicates within the batch
+func (s *userService) validateBatch(inputs []CreateUserInput) error {
+	var rows []RowError
+	seen := make(map[string]int, len(inputs))
+	for i := range inputs {
+		in := &inputs[i]
+		in.Email = validation.NormalizeEmail(in.Email)
+		if err := validation.ValidateEmail(in.Email); err != nil {
+			rows = append(rows, RowError{Index: i, Err: ErrInvalidEmail})
+			continue
+		}
+		canonical := s.emailNormalizer.Canonical(in.Email)
+		if first, ok := seen[canonical]; ok {
+			rows = append(rows, RowError{Index: i, Err: fmt.Errorf("duplicate of row %d", first)})
+			continue
+		}
+		seen[canonical] = i
+		if err := normalizeProfile(&in.FirstName, &in.LastName, &in.Phone); err != nil {
+			rows = append(rows, RowError{Index: i, Err: err})
+			continue
+		}
+		if err := s.checkPassword(in.Password, in.Email, in.FirstName, in.LastName); err != nil {
+			rows = append(rows, RowError{Index: i, Err: err})
+		}
+	}
+
+	if len(rows) > 0 {
+		return &BatchValidationError{Rows: rows}
+	}
+	return nil
+}
+
+// hashPasswords bcrypt-hashes every input password at cost using a worker per CPU
+func hashPasswords(ctx context.Context, inputs []CreateUserInput, cost int) ([]string, error) {
+	hashes := make([]string, len(inputs))
+	errs := make([]error, len(inputs))
+
+	jobs := make(chan int)
+	var wg sync.WaitGroup
+	for w := 0; w < min(runtime.NumCPU(), len(inputs)); w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range jobs {
+				hash, err := bcrypt.GenerateFromPassword([]byte(inputs[i].Password), cost)
+				hashes[i], errs[i] = string(hash), err
+			}
+		}()
+	}
+
+feed:
+	for i := range inputs {
+		select {
+		case jobs <- i:
+		case <-ctx.Done():
+			break feed
+		}
+	}
+	close(jobs)
+	wg.Wait()
+
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	if err := errors.Join(errs...); err != nil {
+		return nil, fmt.Errorf("failed to hash password: %w", err)
+	}
+	return hashes, nil
+}
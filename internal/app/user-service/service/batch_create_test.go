@@ -0,0 +1,50 @@
+package service_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/golang-standards/project-layout/internal/app/user-service/model"
+	"github.com/golang-standards/project-layout/internal/app/user-service/repository/mocks"
+	"github.com/golang-standards/project-layout/internal/app/user-service/service"
+)
+
+func TestCreateUsersBatchSize(t *testing.T) {
+	tests := []struct {
+		name    string
+		size    int
+		wantErr error
+	}{
+		{name: "at the cap", size: service.MaxCreateBatchSize},
+		{name: "over the cap", size: service.MaxCreateBatchSize + 1, wantErr: service.ErrBatchTooLarge},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			inserted := 0
+			repo := &mocks.MockUserRepository{
+				CreateBatchFunc: func(ctx context.Context, users []*model.User) error {
+					inserted = len(users)
+					return nil
+				},
+			}
+			inputs := make([]service.CreateUserInput, tt.size)
+			for i := range inputs {
+				inputs[i] = service.CreateUserInput{Email: fmt.Sprintf("user%d@example.com", i), Password: "Correct-Horse-Battery-42"}
+			}
+
+			_, err := newTestService(repo, service.Options{}).CreateUsersBatch(context.Background(), inputs)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("err = %v, want %v", err, tt.wantErr)
+			}
+			if tt.wantErr == nil && inserted != tt.size {
+				t.Errorf("inserted %d users, want %d", inserted, tt.size)
+			}
+			if tt.wantErr != nil && inserted != 0 {
+				t.Errorf("inserted %d users from a rejected batch", inserted)
+			}
+		})
+	}
+}
@@ -0,0 +1,150 @@
+package service_test
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/golang-standards/project-layout/internal/app/user-service/model"
+	"github.com/golang-standards/project-layout/internal/app/user-service/repository"
+	"github.com/golang-standards/project-layout/internal/app/user-service/repository/mocks"
+	"github.com/golang-standards/project-layout/internal/app/user-service/service"
+)
+
+func TestBatchGetUsers(t *testing.T) {
+	errBatch := errors.New("connection reset")
+	errLookup := errors.New("statement timeout")
+
+	users := map[string]*model.User{"u1": {ID: "u1"}, "u3": {ID: "u3"}}
+
+	tests := []struct {
+		name         string
+		partial      bool
+		batchErr     error
+		lookupErrs   map[string]error
+		wantErr      error
+		wantUsers    []string
+		wantNotFound []string
+		wantFailed   map[string]error
+	}{
+		{name: "found and missing", wantUsers: []string{"u1", "u3"}, wantNotFound: []string{"u2"}},
+		{name: "fail fast", batchErr: errBatch, wantErr: errBatch},
+		{
+			name:         "partial falls back to per-ID lookups",
+			partial:      true,
+			batchErr:     errBatch,
+			wantUsers:    []string{"u1", "u3"},
+			wantNotFound: []string{"u2"},
+		},
+		{
+			name:         "partial reports failed IDs",
+			partial:      true,
+			batchErr:     errBatch,
+			lookupErrs:   map[string]error{"u3": errLookup},
+			wantUsers:    []string{"u1"},
+			wantNotFound: []string{"u2"},
+			wantFailed:   map[string]error{"u3": errLookup},
+		},
+		{name: "partial with a healthy batch", partial: true, wantUsers: []string{"u1", "u3"}, wantNotFound: []string{"u2"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := &mocks.MockUserRepository{
+				GetByIDsFunc: func(ctx context.Context, ids []string) ([]*model.User, error) {
+					if tt.batchErr != nil {
+						return nil, tt.batchErr
+					}
+					var found []*model.User
+					for _, id := range ids {
+						if user, ok := users[id]; ok {
+							found = append(found, user)
+						}
+					}
+					return found, nil
+				},
+				GetByIDFunc: func(ctx context.Context, id string) (*model.User, error) {
+					if err := tt.lookupErrs[id]; err != nil {
+						return nil, err
+					}
+					if user, ok := users[id]; ok {
+						return user, nil
+					}
+					return nil, repository.ErrUserNotFound
+				},
+			}
+			svc := newTestService(repo, service.Options{BatchGetPartialResults: tt.partial})
+
+			result, err := svc.BatchGetUsers(context.Background(), []string{"u1", "u2", "u3"})
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Fatalf("err = %v, want %v", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("BatchGetUsers: %v", err)
+			}
+
+			var gotUsers []string
+			for _, user := range result.Users {
+				gotUsers = append(gotUsers, user.ID)
+			}
+			if !reflect.DeepEqual(gotUsers, tt.wantUsers) {
+				t.Errorf("users = %v, want %v", gotUsers, tt.wantUsers)
+			}
+			if !reflect.DeepEqual(result.NotFound, tt.wantNotFound) {
+				t.Errorf("not found = %v, want %v", result.NotFound, tt.wantNotFound)
+			}
+			if len(result.Failed) != len(tt.wantFailed) {
+				t.Fatalf("failed = %v, want %v", result.Failed, tt.wantFailed)
+			}
+			for id, want := range tt.wantFailed {
+				if !errors.Is(result.Failed[id], want) {
+					t.Errorf("failed[%s] = %v, want %v", id, result.Failed[id], want)
+				}
+			}
+		})
+	}
+}
+
+func TestBatchGetUsersStopsWhenCancelled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	var lookups int
+	repo := &mocks.MockUserRepository{
+		GetByIDsFunc: func(ctx context.Context, ids []string) ([]*model.User, error) {
+			return nil, errors.New("connection reset")
+		},
+		GetByIDFunc: func(ctx context.Context, id string) (*model.User, error) {
+			lookups++
+			cancel()
+			return &model.User{ID: id}, nil
+		},
+	}
+	svc := newTestService(repo, service.Options{BatchGetPartialResults: true})
+
+	result, err := svc.BatchGetUsers(ctx, []string{"u1", "u2", "u3"})
+	if err != nil {
+		t.Fatalf("BatchGetUsers: %v", err)
+	}
+	if lookups != 1 {
+		t.Errorf("lookups = %d, want 1", lookups)
+	}
+	for _, id := range []string{"u2", "u3"} {
+		if !errors.Is(result.Failed[id], context.Canceled) {
+			t.Errorf("failed[%s] = %v, want %v", id, result.Failed[id], context.Canceled)
+		}
+	}
+}
+
+func TestBatchGetUsersTooManyIDs(t *testing.T) {
+	svc := newTestService(&mocks.MockUserRepository{}, service.Options{})
+
+	ids := make([]string, service.MaxBatchGetIDs+1)
+	if _, err := svc.BatchGetUsers(context.Background(), ids); !errors.Is(err, service.ErrTooManyIDs) {
+		t.Errorf("err = %v, want %v", err, service.ErrTooManyIDs)
+	}
+}
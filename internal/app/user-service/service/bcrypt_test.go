@@ -0,0 +1,58 @@
+package service_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/golang-standards/project-layout/internal/app/user-service/repository"
+	"github.com/golang-standards/project-layout/internal/app/user-service/service"
+	"golang.org/x/crypto/bcrypt"
+)
+
+func TestBcryptCost(t *testing.T) {
+	tests := []struct {
+		name       string
+		createCost int
+		loginCost  int
+		wantCost   int
+	}{
+		{name: "created at the configured cost", createCost: bcrypt.MinCost + 1, loginCost: bcrypt.MinCost + 1, wantCost: bcrypt.MinCost + 1},
+		{name: "rehashed when the cost is raised", createCost: bcrypt.MinCost, loginCost: bcrypt.MinCost + 2, wantCost: bcrypt.MinCost + 2},
+		{name: "kept when the cost is lowered", createCost: bcrypt.MinCost + 2, loginCost: bcrypt.MinCost, wantCost: bcrypt.MinCost + 2},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			repo := repository.NewInMemoryUserRepository()
+
+			user, err := newTestService(repo, service.Options{BcryptCost: tt.createCost}).
+				CreateUser(ctx, "jane@example.com", testPassword, "Jane", "Doe", "")
+			if err != nil {
+				t.Fatalf("CreateUser: %v", err)
+			}
+			stored, err := repo.GetByID(ctx, user.ID)
+			if err != nil {
+				t.Fatalf("GetByID: %v", err)
+			}
+			if cost, _ := bcrypt.Cost([]byte(stored.Password)); cost != tt.createCost {
+				t.Fatalf("created with cost %d, want %d", cost, tt.createCost)
+			}
+
+			svc := newTestService(repo, service.Options{BcryptCost: tt.loginCost})
+			if _, err := svc.ValidatePassword(ctx, "jane@example.com", testPassword); err != nil {
+				t.Fatalf("ValidatePassword: %v", err)
+			}
+			stored, err = repo.GetByID(ctx, user.ID)
+			if err != nil {
+				t.Fatalf("GetByID: %v", err)
+			}
+			if cost, _ := bcrypt.Cost([]byte(stored.Password)); cost != tt.wantCost {
+				t.Errorf("cost after login = %d, want %d", cost, tt.wantCost)
+			}
+			if _, err := svc.ValidatePassword(ctx, "jane@example.com", testPassword); err != nil {
+				t.Errorf("ValidatePassword after login: %v", err)
+			}
+		})
+	}
+}
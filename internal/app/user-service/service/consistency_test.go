@@ -0,0 +1,79 @@
+package service_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/golang-standards/project-layout/internal/app/user-service/model"
+	"github.com/golang-standards/project-layout/internal/app/user-service/repository"
+	"github.com/golang-standards/project-layout/internal/app/user-service/repository/mocks"
+	"github.com/golang-standards/project-layout/internal/app/user-service/service"
+	"github.com/golang-standards/project-layout/internal/pkg/database"
+)
+
+func TestReadsThatFeedWritesUsePrimary(t *testing.T) {
+	tests := []struct {
+		name string
+		call func(svc service.UserService) error
+	}{
+		{
+			name: "UpdateUser",
+			call: func(svc service.UserService) error {
+				_, err := svc.UpdateUser(context.Background(), "user-1", map[string]interface{}{"first_name": "Jane"})
+				return err
+			},
+		},
+		{
+			name: "ChangePassword",
+			call: func(svc service.UserService) error {
+				return svc.ChangePassword(context.Background(), "user-1", "old", "Correct-Horse-Battery-42")
+			},
+		},
+		{
+			name: "DeactivateUser",
+			call: func(svc service.UserService) error {
+				_, err := svc.DeactivateUser(context.Background(), "user-1")
+				return err
+			},
+		},
+		{
+			name: "ReactivateUser",
+			call: func(svc service.UserService) error {
+				_, err := svc.ReactivateUser(context.Background(), "user-1")
+				return err
+			},
+		},
+		{
+			name: "AnonymizeUser",
+			call: func(svc service.UserService) error {
+				_, err := svc.AnonymizeUser(context.Background(), "user-1")
+				return err
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var reads, replicaReads int
+			repo := &mocks.MockUserRepository{
+				GetByIDFunc: func(ctx context.Context, id string) (*model.User, error) {
+					reads++
+					if !database.ReadFromPrimary(ctx) {
+						replicaReads++
+					}
+					return nil, repository.ErrUserNotFound
+				},
+			}
+
+			if err := tt.call(newTestService(repo, service.Options{})); err == nil {
+				t.Fatal("expected the missing user to fail the call")
+			}
+			if reads == 0 {
+				t.Fatal("user was never read")
+			}
+			if replicaReads != 0 {
+				t.Errorf("%d of %d reads could go to a replica", replicaReads, reads)
+			}
+		})
+	}
+}
@@ -0,0 +1,64 @@
+package service_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/golang-standards/project-layout/internal/app/user-service/repository"
+	"github.com/golang-standards/project-layout/internal/app/user-service/repository/mocks"
+	"github.com/golang-standards/project-layout/internal/app/user-service/service"
+	"github.com/golang-standards/project-layout/internal/pkg/audit"
+)
+
+// auditLog is an audit.Recorder that keeps the events it records
+type auditLog struct {
+	events []audit.Event
+}
+
+func (l *auditLog) Record(ctx context.Context, event audit.Event) error {
+	l.events = append(l.events, event)
+	return nil
+}
+
+func TestDeleteUserRecordsReason(t *testing.T) {
+	tests := []struct {
+		name      string
+		reason    string
+		deleteErr error
+		wantAudit bool
+	}{
+		{name: "with reason", reason: "fraud", wantAudit: true},
+		{name: "without reason", reason: "", wantAudit: true},
+		{name: "not found", reason: "fraud", deleteErr: repository.ErrUserNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var gotReason string
+			repo := &mocks.MockUserRepository{DeleteFunc: func(ctx context.Context, id, reason string) error {
+				gotReason = reason
+				return tt.deleteErr
+			}}
+			log := &auditLog{}
+			svc := newTestService(repo, service.Options{Audit: log})
+
+			err := svc.DeleteUser(context.Background(), "user-1", tt.reason)
+			if !errors.Is(err, tt.deleteErr) {
+				t.Fatalf("DeleteUser = %v, want %v", err, tt.deleteErr)
+			}
+			if gotReason != tt.reason {
+				t.Errorf("repository reason = %q, want %q", gotReason, tt.reason)
+			}
+			if !tt.wantAudit {
+				if len(log.events) != 0 {
+					t.Errorf("audit events = %+v, want none", log.events)
+				}
+				return
+			}
+			if len(log.events) != 1 || log.events[0].Action != "user.deleted" || log.events[0].Details["reason"] != tt.reason {
+				t.Errorf("audit events = %+v, want one user.deleted with reason %q", log.events, tt.reason)
+			}
+		})
+	}
+}
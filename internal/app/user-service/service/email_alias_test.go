@@ -0,0 +1,58 @@
+package service_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/golang-standards/project-layout/internal/app/user-service/repository"
+	"github.com/golang-standards/project-layout/internal/app/user-service/service"
+	"github.com/golang-standards/project-layout/internal/pkg/config"
+	"github.com/golang-standards/project-layout/internal/pkg/emailnorm"
+)
+
+func TestCreateUserEmailAliases(t *testing.T) {
+	gmail := config.EmailProviderRule{
+		Domains:         []string{"gmail.com", "googlemail.com"},
+		StripDots:       true,
+		StripPlus:       true,
+		CanonicalDomain: "gmail.com",
+	}
+
+	tests := []struct {
+		name    string
+		enabled bool
+		alias   string
+		wantErr error
+	}{
+		{name: "dots", enabled: true, alias: "j.doe@gmail.com", wantErr: repository.ErrUserAlreadyExists},
+		{name: "plus tag", enabled: true, alias: "jdoe+shop@gmail.com", wantErr: repository.ErrUserAlreadyExists},
+		{name: "alias domain", enabled: true, alias: "J.Doe+x@googlemail.com", wantErr: repository.ErrUserAlreadyExists},
+		{name: "other provider", enabled: true, alias: "j.doe@example.com"},
+		{name: "disabled", enabled: false, alias: "j.doe+shop@gmail.com"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			normalizer := emailnorm.New(config.EmailConfig{CanonicalizeAliases: tt.enabled, Providers: []config.EmailProviderRule{gmail}})
+			svc := newTestService(repository.NewInMemoryUserRepository(), service.Options{EmailNormalizer: normalizer})
+
+			original, err := svc.CreateUser(ctx, "j.doe+news@gmail.com", testPassword, "Jane", "Doe", "")
+			if err != nil {
+				t.Fatalf("CreateUser: %v", err)
+			}
+			// The address as given is kept for display and delivery
+			if original.Email != "j.doe+news@gmail.com" {
+				t.Errorf("email = %q, want the address as given", original.Email)
+			}
+			if want := normalizer.Canonical("j.doe+news@gmail.com"); original.CanonicalEmail != want {
+				t.Errorf("canonical email = %q, want %q", original.CanonicalEmail, want)
+			}
+
+			if _, err := svc.CreateUser(ctx, tt.alias, testPassword, "Jane", "Doe", ""); !errors.Is(err, tt.wantErr) {
+				t.Errorf("CreateUser(%q) = %v, want %v", tt.alias, err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,146 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/golang-standards/project-layout/internal/app/user-service/model"
+	"github.com/golang-standards/project-layout/internal/app/user-service/repository"
+	"github.com/golang-standards/project-layout/internal/pkg/apperror"
+	"github.com/golang-standards/project-layout/internal/pkg/database"
+	"github.com/golang-standards/project-layout/internal/pkg/events"
+	"github.com/golang-standards/project-layout/internal/pkg/validation"
+)
+
+var (
+	ErrEmailNotVerified         = apperror.New(apperror.CodeFailedPrecondition, "email address is not verified")
+	ErrInvalidVerificationToken = apperror.New(apperror.CodeInvalidArgument, "invalid or expired email verification token")
+	ErrVerificationNotEnabled   = apperror.New(apperror.CodeNotEnabled, "email verification is not enabled")
+)
+
+// VerifyEmail consumes a verification token and marks the user's email as verified.
+// Users held inactive pending verification are activated.
+func (s *userService) VerifyEmail(ctx context.Context, token string) (*model.User, error) {
+	ctx, span := tracer.Start(ctx, "UserService.VerifyEmail")
+	defer span.End()
+
+	if s.verificationTokens == nil {
+		return nil, ErrVerificationNotEnabled
+	}
+
+	verificationToken, err := s.verificationTokens.GetByHash(ctx, hashToken(token))
+	if err != nil {
+		if errors.Is(err, repository.ErrTokenNotFound) {
+			return nil, ErrInvalidVerificationToken
+		}
+		return nil, err
+	}
+	if verificationToken.UsedAt != nil || !s.now().Before(verificationToken.ExpiresAt) {
+		return nil, ErrInvalidVerificationToken
+	}
+
+	user, err := s.repo.GetByID(database.WithPrimary(ctx), verificationToken.UserID)
+	if err != nil {
+		if errors.Is(err, repository.ErrUserNotFound) {
+			return nil, ErrInvalidVerificationToken
+		}
+		return nil, err
+	}
+
+	if err := s.verificationTokens.MarkUsed(ctx, verificationToken.ID); err != nil {
+		if errors.Is(err, repository.ErrTokenNotFound) {
+			return nil, ErrInvalidVerificationToken
+		}
+		return nil, err
+	}
+
+	if user.EmailVerified {
+		return user, nil
+	}
+
+	user.EmailVerified = true
+	if s.requireEmailVerification && user.Status == model.UserStatusInactive {
+		user.Status = model.UserStatusActive
+	}
+	fields := []string{"email_verified", "status"}
+	err = s.write(ctx, func(repo repository.UserRepository) ([]events.Event, error) {
+		if err := repo.Update(ctx, user, fields); err != nil {
+			return nil, err
+		}
+		return []events.Event{events.New(events.UserUpdated, user.ID, fields)}, nil
+	})
+	if err != nil {
+		s.log(ctx).Error("Failed to mark email verified", "error", err, "user_id", user.ID)
+		return nil, err
+	}
+
+	s.log(ctx).Info("User email verified", "user_id", user.ID)
+	return user, nil
+}
+
+// ResendVerification sends a new verification token to an unverified user.
+// Unknown, already verified and recently sent addresses all succeed silently, so callers
+// cannot probe for accounts.
+func (s *userService) ResendVerification(ctx context.Context, email string) error {
+	ctx, span := tracer.Start(ctx, "UserService.ResendVerification")
+	defer span.End()
+
+	if s.verificationTokens == nil {
+		return ErrVerificationNotEnabled
+	}
+
+	email = validation.NormalizeEmail(email)
+	s.log(ctx).Info("Verification email resend requested")
+
+	user, err := s.repo.GetByEmail(ctx, email)
+	if err != nil {
+		if errors.Is(err, repository.ErrUserNotFound) {
+			return nil
+		}
+		s.log(ctx).Error("Failed to look up user for verification", "error", err)
+		return err
+	}
+	if user.EmailVerified {
+		return nil
+	}
+
+	latest, err := s.verificationTokens.GetLatestByUserID(ctx, user.ID)
+	switch {
+	case err == nil:
+		if s.now().Sub(latest.CreatedAt) < s.verificationResendInterval {
+			s.log(ctx).Debug("Skipped verification resend within the resend interval", "user_id", user.ID)
+			return nil
+		}
+	case !errors.Is(err, repository.ErrTokenNotFound):
+		return err
+	}
+
+	return s.sendVerification(ctx, user)
+}
+
+// sendVerification issues a verification token for user and delivers it
+func (s *userService) sendVerification(ctx context.Context, user *model.User) error {
+	token, err := generateToken()
+	if err != nil {
+		return fmt.Errorf("failed to generate verification token: %w", err)
+	}
+
+	verificationToken := &model.EmailVerificationToken{
+		UserID:    user.ID,
+		TokenHash: hashToken(token),
+		ExpiresAt: s.now().Add(s.verificationTokenTTL).UTC(),
+	}
+	if err := s.verificationTokens.Create(ctx, verificationToken); err != nil {
+		s.log(ctx).Error("Failed to store email verification token", "error", err, "user_id", user.ID)
+		return err
+	}
+
+	if err := s.notifier.SendEmailVerification(ctx, user, token); err != nil {
+		s.log(ctx).Error("Failed to send email verification token", "error", err, "user_id", user.ID)
+		return err
+	}
+
+	s.log(ctx).Info("Email verification token issued", "user_id", user.ID)
+	return nil
+}
@@ -0,0 +1,84 @@
+package service_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/golang-standards/project-layout/internal/app/user-service/model"
+	"github.com/golang-standards/project-layout/internal/app/user-service/repository"
+	"github.com/golang-standards/project-layout/internal/app/user-service/repository/mocks"
+	"github.com/golang-standards/project-layout/internal/app/user-service/service"
+)
+
+// recordingNotifier counts the tokens it is asked to deliver
+type recordingNotifier struct {
+	resets        int
+	verifications int
+}
+
+func (n *recordingNotifier) SendPasswordReset(ctx context.Context, user *model.User, token string) error {
+	n.resets++
+	return nil
+}
+
+func (n *recordingNotifier) SendEmailVerification(ctx context.Context, user *model.User, token string) error {
+	n.verifications++
+	return nil
+}
+
+func TestResendVerification(t *testing.T) {
+	now := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	unverified := &model.User{ID: "user-1", Email: "jane@example.com"}
+
+	tests := []struct {
+		name      string
+		user      *model.User
+		lastSent  time.Time
+		wantSends int
+	}{
+		{name: "unknown email"},
+		{name: "already verified", user: &model.User{ID: "user-1", Email: "jane@example.com", EmailVerified: true}},
+		{name: "sent recently", user: unverified, lastSent: now.Add(-10 * time.Second)},
+		{name: "never sent", user: unverified, wantSends: 1},
+		{name: "sent long ago", user: unverified, lastSent: now.Add(-time.Hour), wantSends: 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := &mocks.MockUserRepository{
+				GetByEmailFunc: func(ctx context.Context, email string) (*model.User, error) {
+					if tt.user == nil {
+						return nil, repository.ErrUserNotFound
+					}
+					return tt.user, nil
+				},
+			}
+			tokens := &mocks.MockEmailVerificationTokenRepository{
+				GetLatestByUserIDFunc: func(ctx context.Context, userID string) (*model.EmailVerificationToken, error) {
+					if tt.lastSent.IsZero() {
+						return nil, repository.ErrTokenNotFound
+					}
+					return &model.EmailVerificationToken{UserID: userID, CreatedAt: tt.lastSent}, nil
+				},
+				CreateFunc: func(ctx context.Context, token *model.EmailVerificationToken) error { return nil },
+			}
+			notifier := &recordingNotifier{}
+			svc := newTestService(repo, service.Options{
+				VerificationTokens:         tokens,
+				VerificationTokenTTL:       time.Hour,
+				VerificationResendInterval: time.Minute,
+				Notifier:                   notifier,
+				Now:                        func() time.Time { return now },
+			})
+
+			// Every case answers the same so callers cannot tell them apart
+			if err := svc.ResendVerification(context.Background(), "jane@example.com"); err != nil {
+				t.Fatalf("ResendVerification: %v", err)
+			}
+			if notifier.verifications != tt.wantSends {
+				t.Errorf("sent %d verification emails, want %d", notifier.verifications, tt.wantSends)
+			}
+		})
+	}
+}
@@ -0,0 +1,108 @@
+package service_test
+
+import (
+	"context"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/golang-standards/project-layout/internal/app/user-service/model"
+	"github.com/golang-standards/project-layout/internal/app/user-service/repository/mocks"
+	"github.com/golang-standards/project-layout/internal/app/user-service/service"
+	"github.com/golang-standards/project-layout/internal/pkg/events"
+)
+
+func TestTokenAndPasswordUpdatesEmitEvents(t *testing.T) {
+	now := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name       string
+		call       func(svc service.UserService) error
+		wantFields []string
+	}{
+		{
+			name: "verify email",
+			call: func(svc service.UserService) error {
+				_, err := svc.VerifyEmail(context.Background(), "token")
+				return err
+			},
+			wantFields: []string{"email_verified", "status"},
+		},
+		{
+			name: "reset password",
+			call: func(svc service.UserService) error {
+				return svc.ResetPassword(context.Background(), "token", "new-password")
+			},
+			wantFields: []string{"password"},
+		},
+		{
+			name: "change password",
+			call: func(svc service.UserService) error {
+				return svc.ChangePassword(context.Background(), "user-1", "old-password", "new-password")
+			},
+			wantFields: []string{"password"},
+		},
+	}
+
+	for _, outbox := range []bool{false, true} {
+		for _, tt := range tests {
+			name := tt.name
+			if outbox {
+				name += " through the outbox"
+			}
+			t.Run(name, func(t *testing.T) {
+				var stored []events.Event
+				repo := &mocks.MockUserRepository{
+					GetByIDFunc: func(ctx context.Context, id string) (*model.User, error) {
+						return &model.User{ID: id, Email: "jane@example.com", Password: hashPassword(t, "old-password")}, nil
+					},
+					UpdateFunc:             func(ctx context.Context, user *model.User, fields []string) error { return nil },
+					MarkResetTokenUsedFunc: func(ctx context.Context, id string) error { return nil },
+					AddOutboxEventsFunc: func(ctx context.Context, evts []events.Event) error {
+						stored = append(stored, evts...)
+						return nil
+					},
+				}
+				publisher := events.NewChannelPublisher(10)
+				svc := newTestService(repo, service.Options{
+					ResetTokens: &mocks.MockPasswordResetTokenRepository{
+						GetByHashFunc: func(ctx context.Context, tokenHash string) (*model.PasswordResetToken, error) {
+							return &model.PasswordResetToken{ID: "reset-1", UserID: "user-1", ExpiresAt: now.Add(time.Hour)}, nil
+						},
+					},
+					VerificationTokens: &mocks.MockEmailVerificationTokenRepository{
+						GetByHashFunc: func(ctx context.Context, tokenHash string) (*model.EmailVerificationToken, error) {
+							return &model.EmailVerificationToken{ID: "verify-1", UserID: "user-1", ExpiresAt: now.Add(time.Hour)}, nil
+						},
+						MarkUsedFunc: func(ctx context.Context, id string) error { return nil },
+					},
+					Notifier: &recordingNotifier{},
+					Now:      func() time.Time { return now },
+					Events:   publisher,
+					Outbox:   outbox,
+				})
+
+				if err := tt.call(svc); err != nil {
+					t.Fatalf("call: %v", err)
+				}
+
+				got := stored
+			drain:
+				for {
+					select {
+					case event := <-publisher.Events():
+						got = append(got, event)
+					default:
+						break drain
+					}
+				}
+				if len(got) != 1 {
+					t.Fatalf("got %d events, want 1: %+v", len(got), got)
+				}
+				if got[0].Type != events.UserUpdated || got[0].UserID != "user-1" || !reflect.DeepEqual(got[0].ChangedFields, tt.wantFields) {
+					t.Errorf("event = %+v, want %s for user-1 with fields %v", got[0], events.UserUpdated, tt.wantFields)
+				}
+			})
+		}
+	}
+}
@@ -0,0 +1,48 @@
+package service
+
+import (
+	"fmt"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/golang-standards/project-layout/internal/app/user-service/model"
+	"github.com/golang-standards/project-layout/internal/pkg/apperror"
+)
+
+var ErrInvalidField = apperror.New(apperror.CodeInvalidArgument, "invalid field")
+
+// profileFieldLimits are the maximum lengths of the free-text profile fields
+var profileFieldLimits = map[string]int{
+	"first_name": model.MaxFirstNameLength,
+	"last_name":  model.MaxLastNameLength,
+	"phone":      model.MaxPhoneLength,
+}
+
+// normalizeField trims surrounding whitespace from a profile field and checks it fits its column
+func normalizeField(field, value string) (string, error) {
+	value = strings.TrimSpace(value)
+	if limit := profileFieldLimits[field]; limit > 0 && utf8.RuneCountInString(value) > limit {
+		return "", fmt.Errorf("%w: %s must be at most %d characters", ErrInvalidField, field, limit)
+	}
+	return value, nil
+}
+
+// normalizeProfile applies normalizeField to the names and phone of a new user
+func normalizeProfile(firstName, lastName, phone *string) error {
+	fields := []struct {
+		name  string
+		value *string
+	}{
+		{"first_name", firstName},
+		{"last_name", lastName},
+		{"phone", phone},
+	}
+	for _, f := range fields {
+		normalized, err := normalizeField(f.name, *f.value)
+		if err != nil {
+			return err
+		}
+		*f.value = normalized
+	}
+	return nil
+}
@@ -0,0 +1,72 @@
+package service
+
+import (
+	"context"
+	"time"
+
+	"github.com/golang-standards/project-layout/internal/app/user-service/model"
+	"github.com/golang-standards/project-layout/internal/pkg/apperror"
+	"github.com/golang-standards/project-layout/internal/pkg/audit"
+)
+
+var ErrAccountLocked = apperror.New(apperror.CodeFailedPrecondition, "account is temporarily locked after too many failed logins")
+
+// LockoutPolicy locks an account for Duration once Threshold logins fail within Window.
+// A zero Threshold disables lockout.
+type LockoutPolicy struct {
+	Threshold int
+	Window    time.Duration
+	Duration  time.Duration
+}
+
+// UnlockUser clears a user's lockout and failed login count
+func (s *userService) UnlockUser(ctx context.Context, id string) error {
+	ctx, span := tracer.Start(ctx, "UserService.UnlockUser")
+	defer span.End()
+
+	s.log(ctx).Info("Unlocking user", "user_id", id)
+
+	if err := s.repo.ResetFailedLogins(ctx, id); err != nil {
+		s.log(ctx).Error("Failed to unlock user", "error", err, "user_id", id)
+		return err
+	}
+
+	s.recordAudit(ctx, audit.NewEvent(ctx, "user.unlocked", id, nil))
+
+	s.log(ctx).Info("User unlocked successfully", "user_id", id)
+	return nil
+}
+
+// isLocked reports whether user is currently locked out
+func (s *userService) isLocked(user *model.User) bool {
+	return user.LockedUntil != nil && s.now().Before(*user.LockedUntil)
+}
+
+// recordFailedLogin counts a failed login and locks the account once the threshold is reached
+func (s *userService) recordFailedLogin(ctx context.Context, user *model.User) {
+	if s.lockout.Threshold <= 0 {
+		return
+	}
+
+	now := s.now().UTC()
+	// Failures from before an expired lock already cost the user the lockout; counting
+	// them again would re-lock the account on its next mistake
+	windowStart := now.Add(-s.lockout.Window)
+	if user.LockedUntil != nil && user.LockedUntil.After(windowStart) {
+		windowStart = *user.LockedUntil
+	}
+	attempts, err := s.repo.RecordFailedLogin(ctx, user.ID, now, windowStart)
+	if err != nil {
+		s.log(ctx).Error("Failed to record failed login", "error", err, "user_id", user.ID)
+		return
+	}
+	if attempts < s.lockout.Threshold {
+		return
+	}
+
+	if err := s.repo.Lock(ctx, user.ID, now.Add(s.lockout.Duration)); err != nil {
+		s.log(ctx).Error("Failed to lock user", "error", err, "user_id", user.ID)
+		return
+	}
+	s.log(ctx).Warn("User locked after repeated failed logins", "user_id", user.ID, "attempts", attempts)
+}
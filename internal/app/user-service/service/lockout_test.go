@@ -0,0 +1,110 @@
+package service_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/golang-standards/project-layout/internal/app/user-service/model"
+	"github.com/golang-standards/project-layout/internal/app/user-service/repository"
+	"github.com/golang-standards/project-layout/internal/app/user-service/service"
+)
+
+func TestLockout(t *testing.T) {
+	start := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+
+	// Each step is a login attempt after the given time has passed since start
+	type attempt struct {
+		after   time.Duration
+		correct bool
+		wantErr error
+	}
+	tests := []struct {
+		name     string
+		attempts []attempt
+	}{
+		{
+			name: "locks at the threshold",
+			attempts: []attempt{
+				{wantErr: service.ErrInvalidPassword},
+				{after: time.Minute, wantErr: service.ErrInvalidPassword},
+				{after: 2 * time.Minute, wantErr: service.ErrInvalidPassword},
+				{after: 3 * time.Minute, wantErr: service.ErrInvalidPassword},
+				{after: 4 * time.Minute, correct: true, wantErr: service.ErrAccountLocked},
+			},
+		},
+		{
+			name: "failures while locked don't extend the lock",
+			attempts: []attempt{
+				{wantErr: service.ErrInvalidPassword},
+				{after: time.Minute, wantErr: service.ErrInvalidPassword},
+				{after: 2 * time.Minute, wantErr: service.ErrInvalidPassword},
+				{after: 6 * time.Minute, wantErr: service.ErrInvalidPassword},
+				{after: 7 * time.Minute, correct: true},
+			},
+		},
+		{
+			name: "window restarts when the lock expires",
+			attempts: []attempt{
+				{wantErr: service.ErrInvalidPassword},
+				{after: time.Minute, wantErr: service.ErrInvalidPassword},
+				{after: 2 * time.Minute, wantErr: service.ErrInvalidPassword},
+				{after: 8 * time.Minute, wantErr: service.ErrInvalidPassword},
+				{after: 9 * time.Minute, correct: true},
+			},
+		},
+		{
+			name: "failures after the lock expires count again",
+			attempts: []attempt{
+				{wantErr: service.ErrInvalidPassword},
+				{after: time.Minute, wantErr: service.ErrInvalidPassword},
+				{after: 2 * time.Minute, wantErr: service.ErrInvalidPassword},
+				{after: 8 * time.Minute, wantErr: service.ErrInvalidPassword},
+				{after: 9 * time.Minute, wantErr: service.ErrInvalidPassword},
+				{after: 10 * time.Minute, wantErr: service.ErrInvalidPassword},
+				{after: 11 * time.Minute, correct: true, wantErr: service.ErrAccountLocked},
+			},
+		},
+		{
+			name: "success resets the count",
+			attempts: []attempt{
+				{wantErr: service.ErrInvalidPassword},
+				{after: time.Minute, wantErr: service.ErrInvalidPassword},
+				{after: 2 * time.Minute, correct: true},
+				{after: 3 * time.Minute, wantErr: service.ErrInvalidPassword},
+				{after: 4 * time.Minute, correct: true},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			now := start
+			repo := repository.NewInMemoryUserRepository()
+			if err := repo.Create(context.Background(), &model.User{
+				Email:          "jane@example.com",
+				CanonicalEmail: "jane@example.com",
+				Password:       hashPassword(t, testPassword),
+				Status:         model.UserStatusActive,
+			}); err != nil {
+				t.Fatalf("Create: %v", err)
+			}
+			svc := newTestService(repo, service.Options{
+				Lockout: service.LockoutPolicy{Threshold: 3, Window: time.Hour, Duration: 5 * time.Minute},
+				Now:     func() time.Time { return now },
+			})
+
+			for i, a := range tt.attempts {
+				now = start.Add(a.after)
+				password := "wrong-password-1"
+				if a.correct {
+					password = testPassword
+				}
+				if _, err := svc.ValidatePassword(context.Background(), "jane@example.com", password); !errors.Is(err, a.wantErr) {
+					t.Fatalf("attempt %d: err = %v, want %v", i+1, err, a.wantErr)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,48 @@
+package service
+
+import (
+	"context"
+	"net/url"
+	"strings"
+
+	"github.com/golang-standards/project-layout/internal/app/user-service/model"
+)
+
+// Mailer sends a plain text email
+type Mailer interface {
+	Send(ctx context.Context, to, subject, body string) error
+}
+
+// EmailNotifier delivers tokens by email as links. Each link template has {token}
+// replaced with the query-escaped token.
+type EmailNotifier struct {
+	mailer    Mailer
+	resetURL  string
+	verifyURL string
+}
+
+var _ Notifier = (*EmailNotifier)(nil)
+
+// NewEmailNotifier creates a notifier sending through mailer
+func NewEmailNotifier(mailer Mailer, resetURL, verifyURL string) *EmailNotifier {
+	return &EmailNotifier{mailer: mailer, resetURL: resetURL, verifyURL: verifyURL}
+}
+
+func (n *EmailNotifier) SendPasswordReset(ctx context.Context, user *model.User, token string) error {
+	body := "Someone asked to reset the password for your account. Use this link to choose a new one:\n\n" +
+		tokenLink(n.resetURL, token) +
+		"\n\nIf you did not ask for this, you can ignore this email.\n"
+	return n.mailer.Send(ctx, user.Email, "Reset your password", body)
+}
+
+func (n *EmailNotifier) SendEmailVerification(ctx context.Context, user *model.User, token string) error {
+	body := "Confirm your email address with this link:\n\n" +
+		tokenLink(n.verifyURL, token) +
+		"\n\nIf you did not create an account, you can ignore this email.\n"
+	return n.mailer.Send(ctx, user.Email, "Verify your email address", body)
+}
+
+// tokenLink fills token into a link template
+func tokenLink(template, token string) string {
+	return strings.ReplaceAll(template, "{token}", url.QueryEscape(token))
+}
@@ -0,0 +1,59 @@
+package service_test
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/golang-standards/project-layout/internal/app/user-service/model"
+	"github.com/golang-standards/project-layout/internal/app/user-service/service"
+)
+
+// capturingMailer keeps the last message it was asked to send
+type capturingMailer struct {
+	to, subject, body string
+}
+
+func (m *capturingMailer) Send(ctx context.Context, to, subject, body string) error {
+	m.to, m.subject, m.body = to, subject, body
+	return nil
+}
+
+func TestEmailNotifier(t *testing.T) {
+	user := &model.User{ID: "user-1", Email: "jane@example.com"}
+
+	tests := []struct {
+		name     string
+		send     func(n *service.EmailNotifier) error
+		wantLink string
+	}{
+		{
+			name:     "password reset",
+			send:     func(n *service.EmailNotifier) error { return n.SendPasswordReset(context.Background(), user, "a+b/c") },
+			wantLink: "https://app.example.com/reset?token=a%2Bb%2Fc",
+		},
+		{
+			name: "email verification",
+			send: func(n *service.EmailNotifier) error {
+				return n.SendEmailVerification(context.Background(), user, "tok")
+			},
+			wantLink: "https://app.example.com/verify/tok",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := &capturingMailer{}
+			n := service.NewEmailNotifier(m, "https://app.example.com/reset?token={token}", "https://app.example.com/verify/{token}")
+			if err := tt.send(n); err != nil {
+				t.Fatalf("send: %v", err)
+			}
+			if m.to != user.Email {
+				t.Errorf("to = %q, want %q", m.to, user.Email)
+			}
+			if !strings.Contains(m.body, tt.wantLink) {
+				t.Errorf("body %q does not contain %q", m.body, tt.wantLink)
+			}
+		})
+	}
+}
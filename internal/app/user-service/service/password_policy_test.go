@@ -0,0 +1,44 @@
+package service_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/golang-standards/project-layout/internal/app/user-service/repository"
+	"github.com/golang-standards/project-layout/internal/app/user-service/service"
+	"github.com/golang-standards/project-layout/internal/pkg/validation"
+)
+
+func TestCreateUserPersonalInfoPolicy(t *testing.T) {
+	tests := []struct {
+		name     string
+		reject   bool
+		password string
+		wantErr  bool
+	}{
+		{name: "rule off, name in password", password: "jane-horse-42"},
+		{name: "rule on, name in password", reject: true, password: "jane-horse-42", wantErr: true},
+		{name: "rule on, email in password", reject: true, password: "horse-JDOE-42", wantErr: true},
+		{name: "rule on, unrelated password", reject: true, password: testPassword},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			svc := newTestService(repository.NewInMemoryUserRepository(), service.Options{
+				PasswordPolicy: validation.PasswordPolicy{MinLength: 8, RejectPersonalInfo: tt.reject},
+			})
+
+			_, err := svc.CreateUser(context.Background(), "jdoe@example.com", tt.password, "Jane", "Doe", "")
+			if !tt.wantErr {
+				if err != nil {
+					t.Errorf("CreateUser: %v", err)
+				}
+				return
+			}
+			if !errors.Is(err, service.ErrInvalidPassword) || !errors.Is(err, validation.ErrWeakPassword) {
+				t.Errorf("CreateUser = %v, want %v wrapping %v", err, service.ErrInvalidPassword, validation.ErrWeakPassword)
+			}
+		})
+	}
+}
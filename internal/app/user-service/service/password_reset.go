@@ -0,0 +1,156 @@
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+
+	"github.com/golang-standards/project-layout/internal/app/user-service/model"
+	"github.com/golang-standards/project-layout/internal/app/user-service/repository"
+	"github.com/golang-standards/project-layout/internal/pkg/apperror"
+	"github.com/golang-standards/project-layout/internal/pkg/database"
+	"github.com/golang-standards/project-layout/internal/pkg/events"
+	"github.com/golang-standards/project-layout/internal/pkg/validation"
+	"golang.org/x/crypto/bcrypt"
+)
+
+var (
+	ErrInvalidResetToken       = apperror.New(apperror.CodeInvalidArgument, "invalid or expired password reset token")
+	ErrPasswordResetNotEnabled = apperror.New(apperror.CodeNotEnabled, "password reset is not enabled")
+)
+
+// Notifier delivers out-of-band messages to users
+type Notifier interface {
+	SendPasswordReset(ctx context.Context, user *model.User, token string) error
+	SendEmailVerification(ctx context.Context, user *model.User, token string) error
+}
+
+// RequestPasswordReset issues a single-use reset token and sends it to the user.
+// It succeeds whether or not the email belongs to a user so callers cannot probe for accounts.
+func (s *userService) RequestPasswordReset(ctx context.Context, email string) error {
+	ctx, span := tracer.Start(ctx, "UserService.RequestPasswordReset")
+	defer span.End()
+
+	if s.resetTokens == nil {
+		return ErrPasswordResetNotEnabled
+	}
+
+	email = validation.NormalizeEmail(email)
+	s.log(ctx).Info("Password reset requested")
+
+	user, err := s.repo.GetByEmail(ctx, email)
+	if err != nil {
+		if errors.Is(err, repository.ErrUserNotFound) {
+			return nil
+		}
+		s.log(ctx).Error("Failed to look up user for password reset", "error", err)
+		return err
+	}
+
+	token, err := generateToken()
+	if err != nil {
+		return fmt.Errorf("failed to generate reset token: %w", err)
+	}
+
+	resetToken := &model.PasswordResetToken{
+		UserID:    user.ID,
+		TokenHash: hashToken(token),
+		ExpiresAt: s.now().Add(s.resetTokenTTL).UTC(),
+	}
+	if err := s.resetTokens.Create(ctx, resetToken); err != nil {
+		s.log(ctx).Error("Failed to store password reset token", "error", err, "user_id", user.ID)
+		return err
+	}
+
+	if err := s.notifier.SendPasswordReset(ctx, user, token); err != nil {
+		s.log(ctx).Error("Failed to send password reset token", "error", err, "user_id", user.ID)
+		return err
+	}
+
+	s.log(ctx).Info("Password reset token issued", "user_id", user.ID)
+	return nil
+}
+
+// ResetPassword verifies a reset token, enforces the password policy, and sets the new password
+func (s *userService) ResetPassword(ctx context.Context, token, newPassword string) error {
+	ctx, span := tracer.Start(ctx, "UserService.ResetPassword")
+	defer span.End()
+
+	if s.resetTokens == nil {
+		return ErrPasswordResetNotEnabled
+	}
+
+	resetToken, err := s.resetTokens.GetByHash(ctx, hashToken(token))
+	if err != nil {
+		if errors.Is(err, repository.ErrTokenNotFound) {
+			return ErrInvalidResetToken
+		}
+		return err
+	}
+	if resetToken.UsedAt != nil || !s.now().Before(resetToken.ExpiresAt) {
+		return ErrInvalidResetToken
+	}
+
+	user, err := s.repo.GetByID(database.WithPrimary(ctx), resetToken.UserID)
+	if err != nil {
+		if errors.Is(err, repository.ErrUserNotFound) {
+			return ErrInvalidResetToken
+		}
+		return err
+	}
+
+	if err := s.checkPassword(newPassword, user.Email, user.FirstName, user.LastName); err != nil {
+		return err
+	}
+
+	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(newPassword), s.bcryptCost)
+	if err != nil {
+		s.log(ctx).Error("Failed to hash password", "error", err)
+		return fmt.Errorf("failed to hash password: %w", err)
+	}
+
+	// Consume the token in the same transaction as the password change, so it can only be
+	// used once and stays usable if the change fails
+	user.Password = string(hashedPassword)
+	err = s.write(ctx, func(repo repository.UserRepository) ([]events.Event, error) {
+		err := repo.WithTx(ctx, func(txRepo repository.UserRepository) error {
+			if err := txRepo.MarkResetTokenUsed(ctx, resetToken.ID); err != nil {
+				return err
+			}
+			return txRepo.Update(ctx, user, []string{"password"})
+		})
+		if err != nil {
+			return nil, err
+		}
+		return []events.Event{events.New(events.UserUpdated, user.ID, []string{"password"})}, nil
+	})
+	if err != nil {
+		if errors.Is(err, repository.ErrTokenNotFound) {
+			return ErrInvalidResetToken
+		}
+		s.log(ctx).Error("Failed to reset password", "error", err, "user_id", user.ID)
+		return err
+	}
+
+	s.log(ctx).Info("User password reset successfully", "user_id", user.ID)
+	return nil
+}
+
+// generateToken returns a random URL-safe token
+func generateToken() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+// hashToken returns the hex SHA-256 of a token for storage and lookup
+func hashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
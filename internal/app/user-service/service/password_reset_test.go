@@ -0,0 +1,114 @@
+package service_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/golang-standards/project-layout/internal/app/user-service/model"
+	"github.com/golang-standards/project-layout/internal/app/user-service/repository"
+	"github.com/golang-standards/project-layout/internal/app/user-service/repository/mocks"
+	"github.com/golang-standards/project-layout/internal/app/user-service/service"
+)
+
+func TestResetPassword(t *testing.T) {
+	now := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	errDB := errors.New("connection reset")
+
+	tests := []struct {
+		name      string
+		notifier  service.Notifier
+		expiresAt time.Time
+		markErr   error
+		updateErr error
+		wantErr   error
+		wantTx    []string
+	}{
+		{
+			name:      "resets password",
+			notifier:  &recordingNotifier{},
+			expiresAt: now.Add(time.Hour),
+			wantTx:    []string{"MarkResetTokenUsed", "Update"},
+		},
+		{
+			name:      "token consumed concurrently",
+			notifier:  &recordingNotifier{},
+			expiresAt: now.Add(time.Hour),
+			markErr:   repository.ErrTokenNotFound,
+			wantErr:   service.ErrInvalidResetToken,
+			wantTx:    []string{"MarkResetTokenUsed"},
+		},
+		{
+			name:      "update fails",
+			notifier:  &recordingNotifier{},
+			expiresAt: now.Add(time.Hour),
+			updateErr: errDB,
+			wantErr:   errDB,
+			wantTx:    []string{"MarkResetTokenUsed", "Update"},
+		},
+		{
+			name:      "expired token",
+			notifier:  &recordingNotifier{},
+			expiresAt: now.Add(-time.Minute),
+			wantErr:   service.ErrInvalidResetToken,
+		},
+		{
+			name:      "no notifier",
+			expiresAt: now.Add(time.Hour),
+			wantErr:   service.ErrPasswordResetNotEnabled,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			txRepo := &mocks.MockUserRepository{
+				MarkResetTokenUsedFunc: func(ctx context.Context, id string) error { return tt.markErr },
+				UpdateFunc:             func(ctx context.Context, user *model.User, fields []string) error { return tt.updateErr },
+			}
+			repo := &mocks.MockUserRepository{
+				GetByIDFunc: func(ctx context.Context, id string) (*model.User, error) {
+					return &model.User{ID: id, Email: "jane@example.com", Status: model.UserStatusActive}, nil
+				},
+				WithTxFunc: func(ctx context.Context, fn func(txRepo repository.UserRepository) error) error {
+					return fn(txRepo)
+				},
+			}
+			tokens := &mocks.MockPasswordResetTokenRepository{
+				GetByHashFunc: func(ctx context.Context, tokenHash string) (*model.PasswordResetToken, error) {
+					return &model.PasswordResetToken{ID: "token-1", UserID: "user-1", ExpiresAt: tt.expiresAt}, nil
+				},
+			}
+			svc := newTestService(repo, service.Options{
+				ResetTokens:   tokens,
+				ResetTokenTTL: time.Hour,
+				Notifier:      tt.notifier,
+				Now:           func() time.Time { return now },
+			})
+
+			err := svc.ResetPassword(context.Background(), "token", "Correct-Horse-Battery-42")
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("err = %v, want %v", err, tt.wantErr)
+			}
+
+			// The token is consumed and the password written by the transaction only
+			var got []string
+			for _, c := range txRepo.Calls() {
+				got = append(got, c.Method)
+			}
+			if len(got) != len(tt.wantTx) {
+				t.Fatalf("transaction calls = %v, want %v", got, tt.wantTx)
+			}
+			for i := range got {
+				if got[i] != tt.wantTx[i] {
+					t.Fatalf("transaction calls = %v, want %v", got, tt.wantTx)
+				}
+			}
+			for _, c := range repo.Calls() {
+				if c.Method == "MarkResetTokenUsed" || c.Method == "Update" {
+					t.Errorf("%s ran outside the transaction", c.Method)
+				}
+			}
+		})
+	}
+}
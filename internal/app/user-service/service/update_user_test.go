@@ -0,0 +1,131 @@
+package service_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/golang-standards/project-layout/internal/app/user-service/model"
+	"github.com/golang-standards/project-layout/internal/app/user-service/repository"
+	"github.com/golang-standards/project-layout/internal/app/user-service/repository/mocks"
+	"github.com/golang-standards/project-layout/internal/app/user-service/service"
+	"github.com/golang-standards/project-layout/internal/pkg/apperror"
+	"google.golang.org/grpc/codes"
+)
+
+func TestUpdateUserEmail(t *testing.T) {
+	tests := []struct {
+		name      string
+		email     string
+		wantErr   error
+		wantEmail string
+	}{
+		{name: "unchanged", email: "jane@example.com", wantEmail: "jane@example.com"},
+		{name: "unchanged, different case", email: "JANE@example.com", wantEmail: "jane@example.com"},
+		{name: "free email", email: "jane.doe@example.com", wantEmail: "jane.doe@example.com"},
+		{name: "taken by another user", email: "john@example.com", wantErr: repository.ErrUserAlreadyExists},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			repo := repository.NewInMemoryUserRepository()
+			svc := newTestService(repo, service.Options{})
+			jane, err := svc.CreateUser(ctx, "jane@example.com", testPassword, "Jane", "Doe", "")
+			if err != nil {
+				t.Fatalf("CreateUser: %v", err)
+			}
+			if _, err := svc.CreateUser(ctx, "john@example.com", testPassword, "John", "Roe", ""); err != nil {
+				t.Fatalf("CreateUser: %v", err)
+			}
+
+			user, err := svc.UpdateUser(ctx, jane.ID, map[string]interface{}{"email": tt.email})
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Fatalf("err = %v, want %v", err, tt.wantErr)
+				}
+				if got := apperror.ToGRPCStatus(err).Code(); got != codes.AlreadyExists {
+					t.Errorf("code = %v, want %v", got, codes.AlreadyExists)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("UpdateUser: %v", err)
+			}
+			if user.Email != tt.wantEmail {
+				t.Errorf("email = %q, want %q", user.Email, tt.wantEmail)
+			}
+		})
+	}
+}
+
+func TestUpdateUserEmailRace(t *testing.T) {
+	// Another user takes the email between the check and the write; the unique index
+	// rejects the update and the caller still sees AlreadyExists
+	repo := &mocks.MockUserRepository{
+		GetByIDFunc: func(ctx context.Context, id string) (*model.User, error) {
+			return &model.User{ID: id, Email: "jane@example.com", Status: model.UserStatusActive}, nil
+		},
+		GetByEmailFunc: func(ctx context.Context, email string) (*model.User, error) {
+			return nil, repository.ErrUserNotFound
+		},
+		UpdateFunc: func(ctx context.Context, user *model.User, fields []string) error {
+			return repository.ErrUserAlreadyExists
+		},
+	}
+	svc := newTestService(repo, service.Options{})
+
+	_, err := svc.UpdateUser(context.Background(), "user-1", map[string]interface{}{"email": "john@example.com"})
+	if got := apperror.ToGRPCStatus(err).Code(); got != codes.AlreadyExists {
+		t.Errorf("code = %v (err %v), want %v", got, err, codes.AlreadyExists)
+	}
+}
+
+func TestUpdateUserFields(t *testing.T) {
+	tests := []struct {
+		name    string
+		updates map[string]interface{}
+		want    model.User
+	}{
+		{
+			name:    "only named fields change",
+			updates: map[string]interface{}{"first_name": "Janet"},
+			want:    model.User{FirstName: "Janet", LastName: "Doe", Phone: "+15555550100"},
+		},
+		{
+			name:    "empty value clears the field",
+			updates: map[string]interface{}{"phone": "", "last_name": ""},
+			want:    model.User{FirstName: "Jane", LastName: "", Phone: ""},
+		},
+		{
+			name:    "no fields",
+			updates: map[string]interface{}{},
+			want:    model.User{FirstName: "Jane", LastName: "Doe", Phone: "+15555550100"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			repo := repository.NewInMemoryUserRepository()
+			svc := newTestService(repo, service.Options{})
+			jane, err := svc.CreateUser(ctx, "jane@example.com", testPassword, "Jane", "Doe", "+15555550100")
+			if err != nil {
+				t.Fatalf("CreateUser: %v", err)
+			}
+
+			if _, err := svc.UpdateUser(ctx, jane.ID, tt.updates); err != nil {
+				t.Fatalf("UpdateUser: %v", err)
+			}
+
+			got, err := repo.GetByID(ctx, jane.ID)
+			if err != nil {
+				t.Fatalf("GetByID: %v", err)
+			}
+			if got.FirstName != tt.want.FirstName || got.LastName != tt.want.LastName || got.Phone != tt.want.Phone {
+				t.Errorf("stored name/phone = %q %q %q, want %q %q %q",
+					got.FirstName, got.LastName, got.Phone, tt.want.FirstName, tt.want.LastName, tt.want.Phone)
+			}
+		})
+	}
+}
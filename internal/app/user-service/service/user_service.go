@@ -4,99 +4,375 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"time"
 
 	"github.com/golang-standards/project-layout/internal/app/user-service/model"
 	"github.com/golang-standards/project-layout/internal/app/user-service/repository"
+	"github.com/golang-standards/project-layout/internal/pkg/apperror"
+	"github.com/golang-standards/project-layout/internal/pkg/audit"
+	"github.com/golang-standards/project-layout/internal/pkg/auth"
+	"github.com/golang-standards/project-layout/internal/pkg/database"
+	"github.com/golang-standards/project-layout/internal/pkg/emailnorm"
+	"github.com/golang-standards/project-layout/internal/pkg/events"
 	"github.com/golang-standards/project-layout/internal/pkg/logger"
+	"github.com/golang-standards/project-layout/internal/pkg/validation"
+	"github.com/golang-standards/project-layout/pkg/pagination"
+	"github.com/google/uuid"
+	"go.opentelemetry.io/otel"
 	"golang.org/x/crypto/bcrypt"
 )
 
+var tracer = otel.Tracer("github.com/golang-standards/project-layout/internal/app/user-service/service")
+
 var (
-	ErrInvalidPassword = errors.New("invalid password")
-	ErrInvalidEmail    = errors.New("invalid email")
+	ErrInvalidPassword  = apperror.New(apperror.CodeInvalidArgument, "invalid password")
+	ErrInvalidEmail     = apperror.New(apperror.CodeInvalidArgument, "invalid email")
+	ErrPasswordMismatch = apperror.New(apperror.CodePermissionDenied, "current password is incorrect")
+	// ErrInvalidCredentials reports an unknown email or wrong password alike, so callers
+	// cannot probe for accounts
+	ErrInvalidCredentials = apperror.New(apperror.CodeUnauthenticated, "invalid email or password")
+	ErrTooManyIDs         = apperror.New(apperror.CodeInvalidArgument, "too many ids")
+)
+
+// MaxBatchGetIDs caps the number of IDs accepted by BatchGetUsers
+const MaxBatchGetIDs = 100
+
+// List page sizes; sizes outside 1..MaxPageSize fall back to DefaultPageSize
+const (
+	DefaultPageSize = pagination.DefaultPageSize
+	MaxPageSize     = pagination.MaxPageSize
 )
 
+// StreamUsers batch sizes. Larger batches mean fewer queries but more memory per batch;
+// the default suits most exports.
+const (
+	DefaultStreamBatchSize = 500
+	MaxStreamBatchSize     = 5000
+)
+
+// BatchGetResult is the outcome of BatchGetUsers
+type BatchGetResult struct {
+	// Users holds the found users in request order
+	Users []*model.User
+	// NotFound lists requested IDs that did not match a user
+	NotFound []string
+	// Failed maps IDs to lookup errors; only populated in partial-results mode
+	Failed map[string]error
+}
+
 // UserService defines the business logic interface for user operations
 type UserService interface {
 	CreateUser(ctx context.Context, email, password, firstName, lastName, phone string) (*model.User, error)
+	CreateUsersBatch(ctx context.Context, inputs []CreateUserInput) ([]*model.User, error)
 	GetUser(ctx context.Context, id string) (*model.User, error)
+	BatchGetUsers(ctx context.Context, ids []string) (*BatchGetResult, error)
 	GetUserByEmail(ctx context.Context, email string) (*model.User, error)
 	UpdateUser(ctx context.Context, id string, updates map[string]interface{}) (*model.User, error)
-	DeleteUser(ctx context.Context, id string) error
-	ListUsers(ctx context.Context, page, pageSize int, filter string) ([]*model.User, int64, error)
+	DeleteUser(ctx context.Context, id, reason string) error
+	PurgeUser(ctx context.Context, id string) error
+	AnonymizeUser(ctx context.Context, id string) (*model.User, error)
+	RestoreUser(ctx context.Context, id string) (*model.User, error)
+	ListUsers(ctx context.Context, params pagination.Params, filter repository.ListFilter, sort []repository.SortKey) ([]*model.User, int64, error)
+	ListUsersCursor(ctx context.Context, cursor string, limit int, filter repository.ListFilter) ([]*model.User, string, error)
+	CountUsers(ctx context.Context, filter repository.ListFilter) (int64, error)
+	StreamUsers(ctx context.Context, filter repository.ListFilter, batchSize int, fn func(*model.User) error) error
 	ValidatePassword(ctx context.Context, email, password string) (*model.User, error)
+	ChangePassword(ctx context.Context, id, currentPassword, newPassword string) error
+	RequestPasswordReset(ctx context.Context, email string) error
+	ResetPassword(ctx context.Context, token, newPassword string) error
+	VerifyEmail(ctx context.Context, token string) (*model.User, error)
+	ResendVerification(ctx context.Context, email string) error
+	UnlockUser(ctx context.Context, id string) error
+	SetUserStatus(ctx context.Context, id string, status model.UserStatus) (*model.User, error)
+	DeactivateUser(ctx context.Context, id string) (*model.User, error)
+	ReactivateUser(ctx context.Context, id string) (*model.User, error)
+}
+
+// Options holds optional settings for the user service
+type Options struct {
+	// EmailNormalizer computes the canonical email used as a uniqueness key.
+	// A nil normalizer keeps the email unchanged.
+	EmailNormalizer *emailnorm.Normalizer
+	// PasswordPolicy is enforced whenever a password is set
+	PasswordPolicy validation.PasswordPolicy
+	// BatchGetPartialResults makes BatchGetUsers fall back to per-ID lookups when
+	// the batch query fails, reporting failures per ID instead of failing the call
+	BatchGetPartialResults bool
+	// BcryptCost is the work factor for new password hashes; zero means bcrypt.DefaultCost
+	BcryptCost int
+	// MinVerificationTime pads ValidatePassword to at least this duration
+	// regardless of outcome. Zero disables padding.
+	MinVerificationTime time.Duration
+	// ResetTokens stores password reset tokens. Nil disables password reset.
+	ResetTokens repository.PasswordResetTokenRepository
+	// ResetTokenTTL is how long a password reset token stays valid
+	ResetTokenTTL time.Duration
+	// Notifier delivers tokens to users. Without one, password reset and email
+	// verification are disabled, since their tokens could not reach anyone.
+	Notifier Notifier
+	// VerificationTokens stores email verification tokens. Nil disables email verification.
+	VerificationTokens repository.EmailVerificationTokenRepository
+	// VerificationTokenTTL is how long an email verification token stays valid
+	VerificationTokenTTL time.Duration
+	// VerificationResendInterval is the minimum time between verification emails to one user
+	VerificationResendInterval time.Duration
+	// RequireEmailVerification keeps new users inactive and rejects their credentials until verified
+	RequireEmailVerification bool
+	// Lockout locks accounts after repeated failed logins
+	Lockout LockoutPolicy
+	// Audit records auditable actions; defaults to writing them to the service logger
+	Audit audit.Recorder
+	// Events receives user lifecycle events after each change; defaults to discarding them
+	Events events.Publisher
+	// Outbox stores events in the same transaction as each change, for a relay to publish,
+	// instead of publishing them once the change succeeds; it requires a SQL database
+	Outbox bool
+	// Now defaults to time.Now, and Sleep to a sleep that ends early when ctx is done
+	Now   func() time.Time
+	Sleep func(ctx context.Context, d time.Duration)
 }
 
 type userService struct {
-	repo   repository.UserRepository
-	logger logger.Logger
+	repo                       repository.UserRepository
+	logger                     logger.Logger
+	emailNormalizer            *emailnorm.Normalizer
+	passwordPolicy             validation.PasswordPolicy
+	bcryptCost                 int
+	batchGetPartial            bool
+	minVerificationTime        time.Duration
+	resetTokens                repository.PasswordResetTokenRepository
+	resetTokenTTL              time.Duration
+	notifier                   Notifier
+	verificationTokens         repository.EmailVerificationTokenRepository
+	verificationTokenTTL       time.Duration
+	verificationResendInterval time.Duration
+	requireEmailVerification   bool
+	lockout                    LockoutPolicy
+	audit                      audit.Recorder
+	events                     events.Publisher
+	outbox                     bool
+	now                        func() time.Time
+	sleep                      func(ctx context.Context, d time.Duration)
 }
 
 // NewUserService creates a new instance of UserService
-func NewUserService(repo repository.UserRepository, logger logger.Logger) UserService {
-	return &userService{
-		repo:   repo,
-		logger: logger,
+func NewUserService(repo repository.UserRepository, logger logger.Logger, opts Options) UserService {
+	if opts.Now == nil {
+		opts.Now = time.Now
+	}
+	if opts.Sleep == nil {
+		opts.Sleep = sleep
+	}
+	if opts.BcryptCost == 0 {
+		opts.BcryptCost = bcrypt.DefaultCost
+	}
+
+	s := &userService{
+		repo:                       repo,
+		logger:                     logger,
+		emailNormalizer:            opts.EmailNormalizer,
+		passwordPolicy:             opts.PasswordPolicy,
+		bcryptCost:                 opts.BcryptCost,
+		batchGetPartial:            opts.BatchGetPartialResults,
+		minVerificationTime:        opts.MinVerificationTime,
+		resetTokens:                opts.ResetTokens,
+		resetTokenTTL:              opts.ResetTokenTTL,
+		notifier:                   opts.Notifier,
+		verificationTokens:         opts.VerificationTokens,
+		verificationTokenTTL:       opts.VerificationTokenTTL,
+		verificationResendInterval: opts.VerificationResendInterval,
+		requireEmailVerification:   opts.RequireEmailVerification,
+		lockout:                    opts.Lockout,
+		audit:                      opts.Audit,
+		events:                     opts.Events,
+		outbox:                     opts.Outbox,
+		now:                        opts.Now,
+		sleep:                      opts.Sleep,
+	}
+	if s.notifier == nil {
+		s.resetTokens = nil
+		s.verificationTokens = nil
+	}
+	if s.audit == nil {
+		s.audit = audit.NewLogRecorder(logger)
+	}
+	if s.events == nil {
+		s.events = events.NewNoopPublisher()
 	}
+
+	return s
 }
 
 // CreateUser creates a new user with encrypted password
 func (s *userService) CreateUser(ctx context.Context, email, password, firstName, lastName, phone string) (*model.User, error) {
-	s.logger.Info("Creating new user", "email", email)
+	ctx, span := tracer.Start(ctx, "UserService.CreateUser")
+	defer span.End()
+
+	s.log(ctx).Info("Creating new user", "email", email)
 
 	// Validate input
-	if email == "" {
+	email = validation.NormalizeEmail(email)
+	if err := validation.ValidateEmail(email); err != nil {
+		s.log(ctx).Debug("Rejected invalid email", "error", err)
 		return nil, ErrInvalidEmail
 	}
-	if password == "" || len(password) < 8 {
-		return nil, ErrInvalidPassword
+	if err := normalizeProfile(&firstName, &lastName, &phone); err != nil {
+		return nil, err
+	}
+	if err := s.checkPassword(password, email, firstName, lastName); err != nil {
+		return nil, err
 	}
 
 	// Hash password
-	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(password), s.bcryptCost)
 	if err != nil {
-		s.logger.Error("Failed to hash password", "error", err)
+		s.log(ctx).Error("Failed to hash password", "error", err)
 		return nil, fmt.Errorf("failed to hash password: %w", err)
 	}
 
-	user := &model.User{
-		Email:     email,
-		Password:  string(hashedPassword),
-		FirstName: firstName,
-		LastName:  lastName,
-		Phone:     phone,
-		Status:    model.UserStatusActive,
+	// The user and its audit record commit together; a failed audit write undoes the insert
+	user := s.newUser(ctx, email, string(hashedPassword), firstName, lastName, phone)
+	err = s.writeAudited(ctx, audit.NewEvent(ctx, "user.created", user.ID, nil), func(repo repository.UserRepository) ([]events.Event, error) {
+		if err := repo.Create(ctx, user); err != nil {
+			return nil, err
+		}
+		return []events.Event{events.New(events.UserCreated, user.ID, nil)}, nil
+	})
+	if err != nil {
+		s.log(ctx).Error("Failed to create user", "error", err, "email", email)
+		return nil, err
 	}
 
-	if err := s.repo.Create(ctx, user); err != nil {
-		s.logger.Error("Failed to create user", "error", err, "email", email)
-		return nil, err
+	// A failed send is not fatal; the user can ask for the verification email again
+	if s.verificationTokens != nil {
+		if err := s.sendVerification(ctx, user); err != nil {
+			s.log(ctx).Warn("Failed to send verification email", "error", err, "user_id", user.ID)
+		}
 	}
 
-	s.logger.Info("User created successfully", "user_id", user.ID, "email", email)
+	s.log(ctx).Info("User created successfully", "user_id", user.ID, "email", email)
 	return user, nil
 }
 
+// newUser builds a user to insert from validated input and a hashed password.
+// The ID is assigned up front so it is known before the insert completes.
+func (s *userService) newUser(ctx context.Context, email, hashedPassword, firstName, lastName, phone string) *model.User {
+	user := &model.User{
+		ID:             uuid.NewString(),
+		Email:          email,
+		CanonicalEmail: s.emailNormalizer.Canonical(email),
+		Password:       hashedPassword,
+		FirstName:      firstName,
+		LastName:       lastName,
+		Phone:          phone,
+		Status:         model.UserStatusActive,
+		Role:           model.UserRoleUser,
+	}
+	if s.requireEmailVerification {
+		user.Status = model.UserStatusInactive
+	}
+
+	// New users belong to the caller's organization
+	if principal, ok := auth.PrincipalFromContext(ctx); ok && principal.OrgID != "" {
+		orgID := principal.OrgID
+		user.OrgID = &orgID
+	}
+
+	return user
+}
+
 // GetUser retrieves a user by ID
 func (s *userService) GetUser(ctx context.Context, id string) (*model.User, error) {
-	s.logger.Debug("Getting user", "user_id", id)
+	ctx, span := tracer.Start(ctx, "UserService.GetUser")
+	defer span.End()
+
+	s.log(ctx).Debug("Getting user", "user_id", id)
 
 	user, err := s.repo.GetByID(ctx, id)
 	if err != nil {
-		s.logger.Error("Failed to get user", "error", err, "user_id", id)
+		s.log(ctx).Error("Failed to get user", "error", err, "user_id", id)
 		return nil, err
 	}
 
 	return user, nil
 }
 
+// BatchGetUsers retrieves users by ID, returning found users in request order
+// along with the IDs that did not match a user
+func (s *userService) BatchGetUsers(ctx context.Context, ids []string) (*BatchGetResult, error) {
+	ctx, span := tracer.Start(ctx, "UserService.BatchGetUsers")
+	defer span.End()
+
+	s.log(ctx).Debug("Batch getting users", "count", len(ids))
+
+	if len(ids) > MaxBatchGetIDs {
+		return nil, fmt.Errorf("%w: got %d, maximum is %d", ErrTooManyIDs, len(ids), MaxBatchGetIDs)
+	}
+
+	found, err := s.repo.GetByIDs(ctx, ids)
+	if err != nil {
+		if !s.batchGetPartial {
+			s.log(ctx).Error("Failed to batch get users", "error", err)
+			return nil, err
+		}
+		s.log(ctx).Warn("Batch get failed, falling back to per-ID lookups", "error", err)
+		return s.batchGetEach(ctx, ids), nil
+	}
+
+	byID := make(map[string]*model.User, len(found))
+	for _, user := range found {
+		byID[user.ID] = user
+	}
+
+	result := &BatchGetResult{Users: make([]*model.User, 0, len(ids))}
+	for _, id := range ids {
+		if user, ok := byID[id]; ok {
+			result.Users = append(result.Users, user)
+		} else {
+			result.NotFound = append(result.NotFound, id)
+		}
+	}
+
+	return result, nil
+}
+
+// batchGetEach looks up each ID individually, recording per-ID failures
+func (s *userService) batchGetEach(ctx context.Context, ids []string) *BatchGetResult {
+	result := &BatchGetResult{
+		Users:  make([]*model.User, 0, len(ids)),
+		Failed: make(map[string]error),
+	}
+
+	for _, id := range ids {
+		// Once the caller is gone the remaining lookups would fail the same way
+		if err := ctx.Err(); err != nil {
+			result.Failed[id] = err
+			continue
+		}
+		user, err := s.repo.GetByID(ctx, id)
+		switch {
+		case err == nil:
+			result.Users = append(result.Users, user)
+		case errors.Is(err, repository.ErrUserNotFound):
+			result.NotFound = append(result.NotFound, id)
+		default:
+			result.Failed[id] = err
+		}
+	}
+
+	return result
+}
+
 // GetUserByEmail retrieves a user by email
 func (s *userService) GetUserByEmail(ctx context.Context, email string) (*model.User, error) {
-	s.logger.Debug("Getting user by email", "email", email)
+	ctx, span := tracer.Start(ctx, "UserService.GetUserByEmail")
+	defer span.End()
+
+	s.log(ctx).Debug("Getting user by email", "email", email)
 
 	user, err := s.repo.GetByEmail(ctx, email)
 	if err != nil {
-		s.logger.Error("Failed to get user by email", "error", err, "email", email)
+		s.log(ctx).Error("Failed to get user by email", "error", err, "email", email)
 		return nil, err
 	}
 
@@ -105,88 +381,512 @@ func (s *userService) GetUserByEmail(ctx context.Context, email string) (*model.
 
 // UpdateUser updates user information
 func (s *userService) UpdateUser(ctx context.Context, id string, updates map[string]interface{}) (*model.User, error) {
-	s.logger.Info("Updating user", "user_id", id)
+	ctx, span := tracer.Start(ctx, "UserService.UpdateUser")
+	defer span.End()
 
-	// Get existing user
-	user, err := s.repo.GetByID(ctx, id)
+	s.log(ctx).Info("Updating user", "user_id", id)
+
+	// Validate email before touching the repository
+	if email, ok := updates["email"].(string); ok {
+		email = validation.NormalizeEmail(email)
+		if err := validation.ValidateEmail(email); err != nil {
+			s.log(ctx).Debug("Rejected invalid email", "error", err)
+			return nil, ErrInvalidEmail
+		}
+		updates["email"] = email
+	}
+	for _, field := range []string{"first_name", "last_name", "phone"} {
+		if value, ok := updates[field].(string); ok {
+			normalized, err := normalizeField(field, value)
+			if err != nil {
+				return nil, err
+			}
+			updates[field] = normalized
+		}
+	}
+
+	// Read from the primary: the update is decided on what this read returns, and a
+	// lagging replica would apply it over stale data or miss a conflicting email
+	primary := database.WithPrimary(ctx)
+	user, err := s.repo.GetByID(primary, id)
 	if err != nil {
 		return nil, err
 	}
 
-	// Apply updates
+	// A client-supplied version must match, so updates based on stale reads are rejected
+	if version, ok := updates["version"].(int64); ok && version != user.Version {
+		return nil, repository.ErrConflict
+	}
+
+	// Reject taking another user's email up front; the unique index still catches races
+	// and canonical duplicates
+	if email, ok := updates["email"].(string); ok && email != user.Email {
+		other, err := s.repo.GetByEmail(primary, email)
+		switch {
+		case err == nil && other.ID != user.ID:
+			s.log(ctx).Debug("Rejected update to an email in use", "user_id", id)
+			return nil, repository.ErrUserAlreadyExists
+		case err != nil && !errors.Is(err, repository.ErrUserNotFound):
+			return nil, err
+		}
+	}
+
+	// Apply updates, tracking the columns to write so empty values clear the field
+	var fields []string
 	if email, ok := updates["email"].(string); ok {
 		user.Email = email
+		user.CanonicalEmail = s.emailNormalizer.Canonical(email)
+		fields = append(fields, "email", "canonical_email")
 	}
 	if firstName, ok := updates["first_name"].(string); ok {
 		user.FirstName = firstName
+		fields = append(fields, "first_name")
 	}
 	if lastName, ok := updates["last_name"].(string); ok {
 		user.LastName = lastName
+		fields = append(fields, "last_name")
 	}
 	if phone, ok := updates["phone"].(string); ok {
 		user.Phone = phone
+		fields = append(fields, "phone")
 	}
 	if status, ok := updates["status"].(model.UserStatus); ok {
 		user.Status = status
+		fields = append(fields, "status")
+	}
+	if len(fields) == 0 {
+		return user, nil
 	}
 
 	// Update in repository
-	if err := s.repo.Update(ctx, user); err != nil {
-		s.logger.Error("Failed to update user", "error", err, "user_id", id)
+	err = s.write(ctx, func(repo repository.UserRepository) ([]events.Event, error) {
+		if err := repo.Update(ctx, user, fields); err != nil {
+			return nil, err
+		}
+		return []events.Event{events.New(events.UserUpdated, id, fields)}, nil
+	})
+	if err != nil {
+		s.log(ctx).Error("Failed to update user", "error", err, "user_id", id)
 		return nil, err
 	}
 
-	s.logger.Info("User updated successfully", "user_id", id)
+	s.log(ctx).Info("User updated successfully", "user_id", id)
 	return user, nil
 }
 
-// DeleteUser deletes a user
-func (s *userService) DeleteUser(ctx context.Context, id string) error {
-	s.logger.Info("Deleting user", "user_id", id)
+// DeleteUser soft-deletes a user, recording the optional reason
+func (s *userService) DeleteUser(ctx context.Context, id, reason string) error {
+	ctx, span := tracer.Start(ctx, "UserService.DeleteUser")
+	defer span.End()
+
+	s.log(ctx).Info("Deleting user", "user_id", id)
 
-	if err := s.repo.Delete(ctx, id); err != nil {
-		s.logger.Error("Failed to delete user", "error", err, "user_id", id)
+	err := s.write(ctx, func(repo repository.UserRepository) ([]events.Event, error) {
+		if err := repo.Delete(ctx, id, reason); err != nil {
+			return nil, err
+		}
+		return []events.Event{events.New(events.UserDeleted, id, nil)}, nil
+	})
+	if err != nil {
+		s.log(ctx).Error("Failed to delete user", "error", err, "user_id", id)
 		return err
 	}
 
-	s.logger.Info("User deleted successfully", "user_id", id)
+	s.recordAudit(ctx, audit.NewEvent(ctx, "user.deleted", id, map[string]string{"reason": reason}))
+
+	s.log(ctx).Info("User deleted successfully", "user_id", id)
 	return nil
 }
 
-// ListUsers retrieves a paginated list of users
-func (s *userService) ListUsers(ctx context.Context, page, pageSize int, filter string) ([]*model.User, int64, error) {
-	s.logger.Debug("Listing users", "page", page, "page_size", pageSize, "filter", filter)
+// PurgeUser permanently deletes a user and its tokens, e.g. for an erasure request. The
+// audit entry holds only the user ID, since no personal data may outlive the purge.
+func (s *userService) PurgeUser(ctx context.Context, id string) error {
+	ctx, span := tracer.Start(ctx, "UserService.PurgeUser")
+	defer span.End()
+
+	s.log(ctx).Info("Purging user", "user_id", id)
+
+	err := s.write(ctx, func(repo repository.UserRepository) ([]events.Event, error) {
+		if err := repo.Purge(ctx, id); err != nil {
+			return nil, err
+		}
+		return []events.Event{events.New(events.UserDeleted, id, nil)}, nil
+	})
+	if err != nil {
+		s.log(ctx).Error("Failed to purge user", "error", err, "user_id", id)
+		return err
+	}
+
+	s.recordAudit(ctx, audit.NewEvent(ctx, "user.purged", id, nil))
 
-	// Validate pagination parameters
-	if page < 1 {
-		page = 1
+	s.log(ctx).Info("User purged successfully", "user_id", id)
+	return nil
+}
+
+// RestoreUser restores a soft-deleted user
+func (s *userService) RestoreUser(ctx context.Context, id string) (*model.User, error) {
+	ctx, span := tracer.Start(ctx, "UserService.RestoreUser")
+	defer span.End()
+
+	s.log(ctx).Info("Restoring user", "user_id", id)
+
+	var user *model.User
+	err := s.write(ctx, func(repo repository.UserRepository) ([]events.Event, error) {
+		var err error
+		if user, err = repo.Restore(ctx, id); err != nil {
+			return nil, err
+		}
+		return []events.Event{events.New(events.UserUpdated, id, []string{"deleted_at", "deleted_reason"})}, nil
+	})
+	if err != nil {
+		s.log(ctx).Error("Failed to restore user", "error", err, "user_id", id)
+		return nil, err
 	}
-	if pageSize < 1 || pageSize > 100 {
-		pageSize = 10
+
+	s.log(ctx).Info("User restored successfully", "user_id", id)
+	return user, nil
+}
+
+// ListUsers retrieves a paginated list of users
+func (s *userService) ListUsers(ctx context.Context, params pagination.Params, filter repository.ListFilter, sort []repository.SortKey) ([]*model.User, int64, error) {
+	ctx, span := tracer.Start(ctx, "UserService.ListUsers")
+	defer span.End()
+
+	// Re-clamp in case the caller built Params by hand
+	params = pagination.NewParams(params.Page, params.PageSize)
+	s.log(ctx).Debug("Listing users", "page", params.Page, "page_size", params.PageSize, "filter", filter, "sort", sort)
+
+	if err := checkFilter(filter); err != nil {
+		return nil, 0, err
 	}
 
-	users, total, err := s.repo.List(ctx, page, pageSize, filter)
+	users, total, err := s.repo.List(ctx, params, filter, sort)
 	if err != nil {
-		s.logger.Error("Failed to list users", "error", err)
+		if ctx.Err() != nil {
+			s.log(ctx).Debug("Listing users cancelled", "error", err)
+			return nil, 0, err
+		}
+		s.log(ctx).Error("Failed to list users", "error", err)
 		return nil, 0, err
 	}
 
 	return users, total, nil
 }
 
+// ListUsersCursor retrieves a keyset-paginated list of users
+func (s *userService) ListUsersCursor(ctx context.Context, cursor string, limit int, filter repository.ListFilter) ([]*model.User, string, error) {
+	ctx, span := tracer.Start(ctx, "UserService.ListUsersCursor")
+	defer span.End()
+
+	s.log(ctx).Debug("Listing users by cursor", "limit", limit, "filter", filter)
+
+	if limit < 1 || limit > MaxPageSize {
+		limit = DefaultPageSize
+	}
+	if err := checkFilter(filter); err != nil {
+		return nil, "", err
+	}
+
+	users, nextCursor, err := s.repo.ListCursor(ctx, cursor, limit, filter)
+	if err != nil {
+		if ctx.Err() != nil {
+			s.log(ctx).Debug("Listing users by cursor cancelled", "error", err)
+			return nil, "", err
+		}
+		s.log(ctx).Error("Failed to list users by cursor", "error", err)
+		return nil, "", err
+	}
+
+	return users, nextCursor, nil
+}
+
+// CountUsers returns the number of users matching filter without loading them
+func (s *userService) CountUsers(ctx context.Context, filter repository.ListFilter) (int64, error) {
+	ctx, span := tracer.Start(ctx, "UserService.CountUsers")
+	defer span.End()
+
+	s.log(ctx).Debug("Counting users", "filter", filter)
+
+	if err := checkFilter(filter); err != nil {
+		return 0, err
+	}
+
+	count, err := s.repo.Count(ctx, filter)
+	if err != nil {
+		if ctx.Err() != nil {
+			s.log(ctx).Debug("Counting users cancelled", "error", err)
+			return 0, err
+		}
+		s.log(ctx).Error("Failed to count users", "error", err)
+		return 0, err
+	}
+
+	return count, nil
+}
+
+// StreamUsers calls fn for every user matching filter, reading batchSize users at a time
+func (s *userService) StreamUsers(ctx context.Context, filter repository.ListFilter, batchSize int, fn func(*model.User) error) error {
+	ctx, span := tracer.Start(ctx, "UserService.StreamUsers")
+	defer span.End()
+
+	if batchSize < 1 {
+		batchSize = DefaultStreamBatchSize
+	}
+	if batchSize > MaxStreamBatchSize {
+		batchSize = MaxStreamBatchSize
+	}
+	if err := checkFilter(filter); err != nil {
+		return err
+	}
+
+	s.log(ctx).Info("Streaming users", "batch_size", batchSize, "filter", filter)
+
+	var count int
+	err := s.repo.Stream(ctx, filter, batchSize, func(users []*model.User) error {
+		for _, user := range users {
+			if err := fn(user); err != nil {
+				return err
+			}
+		}
+		count += len(users)
+		return nil
+	})
+	if err != nil {
+		s.log(ctx).Error("Failed to stream users", "error", err, "streamed", count)
+		return err
+	}
+
+	s.log(ctx).Info("Streamed users", "count", count)
+	return nil
+}
+
 // ValidatePassword validates user credentials
 func (s *userService) ValidatePassword(ctx context.Context, email, password string) (*model.User, error) {
-	s.logger.Debug("Validating user password", "email", email)
+	ctx, span := tracer.Start(ctx, "UserService.ValidatePassword")
+	defer span.End()
+
+	s.log(ctx).Debug("Validating user password", "email", email)
+
+	// Pad every outcome to the same minimum duration so timing doesn't leak
+	// whether the user exists or the password matched
+	defer s.padVerification(ctx, s.now())
 
 	user, err := s.repo.GetByEmail(ctx, email)
 	if err != nil {
 		return nil, err
 	}
 
+	// The password is checked first so a locked account costs the same bcrypt comparison
+	// and a wrong password gets the same answer whether or not the account is locked
+	locked := s.isLocked(user)
 	if err := bcrypt.CompareHashAndPassword([]byte(user.Password), []byte(password)); err != nil {
-		s.logger.Warn("Invalid password attempt", "email", email)
+		s.log(ctx).Warn("Invalid password attempt", "email", email)
+		if !locked {
+			s.recordFailedLogin(ctx, user)
+		}
 		return nil, ErrInvalidPassword
 	}
 
+	// A locked account is rejected even with the right password
+	if locked {
+		s.log(ctx).Warn("Rejected login for locked account", "user_id", user.ID)
+		return nil, ErrAccountLocked
+	}
+
+	if user.FailedLoginAttempts > 0 || user.LockedUntil != nil {
+		if err := s.repo.ResetFailedLogins(ctx, user.ID); err != nil {
+			s.log(ctx).Error("Failed to reset failed logins", "error", err, "user_id", user.ID)
+		}
+	}
+
+	if s.requireEmailVerification && !user.EmailVerified {
+		s.log(ctx).Warn("Rejected credentials for unverified email", "user_id", user.ID)
+		return nil, ErrEmailNotVerified
+	}
+
+	// Checked after the password so the status is only revealed to the account holder
+	if user.Status != model.UserStatusActive {
+		s.log(ctx).Warn("Rejected credentials for inactive account", "user_id", user.ID, "status", user.Status)
+		return nil, fmt.Errorf("%w: account is %s", ErrAccountInactive, user.Status)
+	}
+
+	s.rehashIfOutdated(ctx, user, password)
+
 	return user, nil
 }
+
+// rehashIfOutdated upgrades a hash made with a lower cost than configured. It is best-effort:
+// failures are logged and the old hash keeps working.
+func (s *userService) rehashIfOutdated(ctx context.Context, user *model.User, password string) {
+	cost, err := bcrypt.Cost([]byte(user.Password))
+	if err != nil || cost >= s.bcryptCost {
+		return
+	}
+
+	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(password), s.bcryptCost)
+	if err != nil {
+		s.log(ctx).Warn("Failed to rehash password", "error", err, "user_id", user.ID)
+		return
+	}
+
+	oldPassword := user.Password
+	user.Password = string(hashedPassword)
+	if err := s.repo.Update(ctx, user, []string{"password"}); err != nil {
+		user.Password = oldPassword
+		s.log(ctx).Warn("Failed to store rehashed password", "error", err, "user_id", user.ID)
+		return
+	}
+
+	s.log(ctx).Info("Rehashed password with a higher bcrypt cost", "user_id", user.ID, "from", cost, "to", s.bcryptCost)
+}
+
+// ChangePassword replaces a user's password after verifying the current one
+func (s *userService) ChangePassword(ctx context.Context, id, currentPassword, newPassword string) error {
+	ctx, span := tracer.Start(ctx, "UserService.ChangePassword")
+	defer span.End()
+
+	s.log(ctx).Info("Changing user password", "user_id", id)
+
+	// The current password must be checked against the primary, not a lagging replica
+	user, err := s.repo.GetByID(database.WithPrimary(ctx), id)
+	if err != nil {
+		return err
+	}
+
+	if err := bcrypt.CompareHashAndPassword([]byte(user.Password), []byte(currentPassword)); err != nil {
+		s.log(ctx).Warn("Invalid current password on change", "user_id", id)
+		return ErrPasswordMismatch
+	}
+
+	if err := s.checkPassword(newPassword, user.Email, user.FirstName, user.LastName); err != nil {
+		return err
+	}
+
+	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(newPassword), s.bcryptCost)
+	if err != nil {
+		s.log(ctx).Error("Failed to hash password", "error", err)
+		return fmt.Errorf("failed to hash password: %w", err)
+	}
+
+	user.Password = string(hashedPassword)
+	err = s.write(ctx, func(repo repository.UserRepository) ([]events.Event, error) {
+		if err := repo.Update(ctx, user, []string{"password"}); err != nil {
+			return nil, err
+		}
+		return []events.Event{events.New(events.UserUpdated, id, []string{"password"})}, nil
+	})
+	if err != nil {
+		s.log(ctx).Error("Failed to update password", "error", err, "user_id", id)
+		return err
+	}
+
+	s.log(ctx).Info("User password changed successfully", "user_id", id)
+	return nil
+}
+
+// checkPassword enforces the password policy, including the personal information rule when enabled
+func (s *userService) checkPassword(password, email, firstName, lastName string) error {
+	if err := validation.ValidatePassword(password, s.passwordPolicy); err != nil {
+		return fmt.Errorf("%w: %w", ErrInvalidPassword, err)
+	}
+	if s.passwordPolicy.RejectPersonalInfo {
+		if err := validation.ValidatePasswordPersonalInfo(password, email, firstName, lastName); err != nil {
+			return fmt.Errorf("%w: %w", ErrInvalidPassword, err)
+		}
+	}
+	return nil
+}
+
+// padVerification sleeps until at least minVerificationTime has elapsed since start, or
+// until ctx is done; a caller that gave up learns nothing from the timing
+func (s *userService) padVerification(ctx context.Context, start time.Time) {
+	if s.minVerificationTime <= 0 {
+		return
+	}
+	if remaining := s.minVerificationTime - s.now().Sub(start); remaining > 0 {
+		s.sleep(ctx, remaining)
+	}
+}
+
+// sleep waits for d or until ctx is done, whichever comes first
+func sleep(ctx context.Context, d time.Duration) {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+	case <-ctx.Done():
+	}
+}
+
+// recordAudit records an audit event; failures are logged but never fail the operation
+func (s *userService) recordAudit(ctx context.Context, event audit.Event) {
+	if err := s.audit.Record(ctx, event); err != nil {
+		s.log(ctx).Error("Failed to record audit event", "error", err, "action", event.Action)
+	}
+}
+
+// publish delivers a lifecycle event; a failure is logged since the change is already committed
+func (s *userService) publish(ctx context.Context, event events.Event) {
+	if err := s.events.Publish(ctx, event); err != nil {
+		s.log(ctx).Error("Failed to publish user event", "error", err, "type", event.Type, "user_id", event.UserID)
+	}
+}
+
+// write makes a change through fn and emits the events it returns. With the outbox, fn
+// runs in a transaction that also stores the events, so they can't be lost between the
+// commit and publishing; otherwise they are published once fn succeeds.
+func (s *userService) write(ctx context.Context, fn func(repo repository.UserRepository) ([]events.Event, error)) error {
+	if s.outbox {
+		return s.repo.WithTx(ctx, func(txRepo repository.UserRepository) error {
+			evts, err := fn(txRepo)
+			if err != nil {
+				return err
+			}
+			return txRepo.AddOutboxEvents(ctx, evts)
+		})
+	}
+
+	evts, err := fn(s.repo)
+	if err != nil {
+		return err
+	}
+	for _, event := range evts {
+		s.publish(ctx, event)
+	}
+	return nil
+}
+
+// writeAudited is write with event stored in the same transaction as the change, so
+// neither commits without the other. The audit recorder also gets the event once the
+// change commits.
+func (s *userService) writeAudited(ctx context.Context, event audit.Event, fn func(repo repository.UserRepository) ([]events.Event, error)) error {
+	var evts []events.Event
+	err := s.repo.WithTx(ctx, func(txRepo repository.UserRepository) error {
+		var err error
+		if evts, err = fn(txRepo); err != nil {
+			return err
+		}
+		if s.outbox {
+			if err := txRepo.AddOutboxEvents(ctx, evts); err != nil {
+				return err
+			}
+		}
+		return txRepo.AddAuditEvent(ctx, event)
+	})
+	if err != nil {
+		return err
+	}
+
+	if !s.outbox {
+		for _, evt := range evts {
+			s.publish(ctx, evt)
+		}
+	}
+	s.recordAudit(ctx, event)
+	return nil
+}
+
+// log returns the request-scoped logger, falling back to the service logger
+func (s *userService) log(ctx context.Context) logger.Logger {
+	return logger.FromContextOr(ctx, s.logger)
+}
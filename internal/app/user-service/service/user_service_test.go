@@ -0,0 +1,264 @@
+package service_test
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/golang-standards/project-layout/internal/app/user-service/model"
+	"github.com/golang-standards/project-layout/internal/app/user-service/repository"
+	"github.com/golang-standards/project-layout/internal/app/user-service/repository/mocks"
+	"github.com/golang-standards/project-layout/internal/app/user-service/service"
+	"github.com/golang-standards/project-layout/internal/pkg/apperror"
+	"github.com/golang-standards/project-layout/internal/pkg/audit"
+	"github.com/golang-standards/project-layout/internal/pkg/logger"
+	"github.com/golang-standards/project-layout/internal/pkg/validation"
+	"golang.org/x/crypto/bcrypt"
+	"google.golang.org/grpc/codes"
+)
+
+const testPassword = "correct-horse-42"
+
+// newTestService builds a service over repo using the cheapest bcrypt cost
+func newTestService(repo repository.UserRepository, opts service.Options) service.UserService {
+	if opts.BcryptCost == 0 {
+		opts.BcryptCost = bcrypt.MinCost
+	}
+	return service.NewUserService(repo, logger.NewNopLogger(), opts)
+}
+
+// hashPassword returns a bcrypt hash of password at the minimum cost
+func hashPassword(t *testing.T, password string) string {
+	t.Helper()
+	hash, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.MinCost)
+	if err != nil {
+		t.Fatalf("hash password: %v", err)
+	}
+	return string(hash)
+}
+
+func TestCreateUser(t *testing.T) {
+	tests := []struct {
+		name      string
+		email     string
+		password  string
+		createErr error
+		wantErr   error
+		wantCode  codes.Code
+	}{
+		{name: "hashes password", email: "Jane@Example.com", password: testPassword},
+		{name: "invalid email", email: "not-an-email", password: testPassword, wantErr: service.ErrInvalidEmail, wantCode: codes.InvalidArgument},
+		{name: "weak password", email: "jane@example.com", password: "short", wantErr: service.ErrInvalidPassword, wantCode: codes.InvalidArgument},
+		{name: "duplicate email", email: "jane@example.com", password: testPassword, createErr: repository.ErrUserAlreadyExists, wantErr: repository.ErrUserAlreadyExists, wantCode: codes.AlreadyExists},
+		{name: "repository failure", email: "jane@example.com", password: testPassword, createErr: errors.New("connection reset"), wantCode: codes.Internal},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var created *model.User
+			repo := &mocks.MockUserRepository{
+				CreateFunc: func(ctx context.Context, user *model.User) error {
+					created = user
+					return tt.createErr
+				},
+			}
+			svc := newTestService(repo, service.Options{PasswordPolicy: validation.PasswordPolicy{MinLength: 8}})
+
+			user, err := svc.CreateUser(context.Background(), tt.email, tt.password, "Jane", "Doe", "")
+			if tt.wantCode != codes.OK {
+				if err == nil {
+					t.Fatal("expected an error")
+				}
+				if tt.wantErr != nil && !errors.Is(err, tt.wantErr) {
+					t.Errorf("err = %v, want %v", err, tt.wantErr)
+				}
+				if got := apperror.ToGRPCStatus(err).Code(); got != tt.wantCode {
+					t.Errorf("code = %v, want %v", got, tt.wantCode)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("CreateUser: %v", err)
+			}
+
+			if created == nil || created != user {
+				t.Fatal("expected the returned user to be the one created")
+			}
+			if user.Email != "jane@example.com" {
+				t.Errorf("email = %q, want it normalized", user.Email)
+			}
+			if user.Password == tt.password {
+				t.Fatal("password stored in plain text")
+			}
+			if err := bcrypt.CompareHashAndPassword([]byte(user.Password), []byte(tt.password)); err != nil {
+				t.Errorf("stored hash does not match password: %v", err)
+			}
+		})
+	}
+}
+
+func TestValidatePassword(t *testing.T) {
+	stored := &model.User{
+		ID:       "user-1",
+		Email:    "jane@example.com",
+		Password: hashPassword(t, testPassword),
+		Status:   model.UserStatusActive,
+	}
+
+	tests := []struct {
+		name     string
+		password string
+		getErr   error
+		user     *model.User
+		wantErr  error
+		wantCode codes.Code
+	}{
+		{name: "correct password", password: testPassword, user: stored},
+		{name: "wrong password", password: "wrong-password-1", user: stored, wantErr: service.ErrInvalidPassword, wantCode: codes.InvalidArgument},
+		{name: "unknown user", password: testPassword, getErr: repository.ErrUserNotFound, wantErr: repository.ErrUserNotFound, wantCode: codes.NotFound},
+		{name: "inactive account", password: testPassword, user: &model.User{ID: "user-2", Email: "jane@example.com", Password: stored.Password, Status: model.UserStatusSuspended}, wantErr: service.ErrAccountInactive, wantCode: codes.FailedPrecondition},
+		{name: "repository failure", password: testPassword, getErr: errors.New("connection reset"), wantCode: codes.Internal},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := &mocks.MockUserRepository{
+				GetByEmailFunc: func(ctx context.Context, email string) (*model.User, error) {
+					if tt.getErr != nil {
+						return nil, tt.getErr
+					}
+					copied := *tt.user
+					return &copied, nil
+				},
+			}
+			svc := newTestService(repo, service.Options{})
+
+			user, err := svc.ValidatePassword(context.Background(), "jane@example.com", tt.password)
+			if tt.wantCode != codes.OK {
+				if err == nil {
+					t.Fatal("expected an error")
+				}
+				if tt.wantErr != nil && !errors.Is(err, tt.wantErr) {
+					t.Errorf("err = %v, want %v", err, tt.wantErr)
+				}
+				if got := apperror.ToGRPCStatus(err).Code(); got != tt.wantCode {
+					t.Errorf("code = %v, want %v", got, tt.wantCode)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("ValidatePassword: %v", err)
+			}
+			if user.ID != tt.user.ID {
+				t.Errorf("user = %q, want %q", user.ID, tt.user.ID)
+			}
+		})
+	}
+}
+
+func TestValidatePasswordPadding(t *testing.T) {
+	stored := &model.User{ID: "user-1", Email: "jane@example.com", Password: hashPassword(t, testPassword), Status: model.UserStatusActive}
+
+	tests := []struct {
+		name     string
+		user     *model.User
+		password string
+	}{
+		{name: "unknown user", password: testPassword},
+		{name: "wrong password", user: stored, password: "wrong-password-1"},
+		{name: "correct password", user: stored, password: testPassword},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := &mocks.MockUserRepository{
+				GetByEmailFunc: func(ctx context.Context, email string) (*model.User, error) {
+					if tt.user == nil {
+						return nil, repository.ErrUserNotFound
+					}
+					copied := *tt.user
+					return &copied, nil
+				},
+			}
+			now := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+			var slept []time.Duration
+			svc := newTestService(repo, service.Options{
+				MinVerificationTime: 200 * time.Millisecond,
+				Now:                 func() time.Time { return now },
+				Sleep:               func(ctx context.Context, d time.Duration) { slept = append(slept, d) },
+			})
+
+			_, _ = svc.ValidatePassword(context.Background(), "jane@example.com", tt.password)
+			if len(slept) != 1 || slept[0] != 200*time.Millisecond {
+				t.Errorf("slept %v, want [200ms] whatever the outcome", slept)
+			}
+		})
+	}
+}
+
+func TestValidatePasswordPaddingHonorsContext(t *testing.T) {
+	repo := &mocks.MockUserRepository{
+		GetByEmailFunc: func(ctx context.Context, email string) (*model.User, error) {
+			return nil, repository.ErrUserNotFound
+		},
+	}
+	svc := newTestService(repo, service.Options{MinVerificationTime: time.Minute})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	start := time.Now()
+	_, _ = svc.ValidatePassword(ctx, "jane@example.com", testPassword)
+	if elapsed := time.Since(start); elapsed > 5*time.Second {
+		t.Errorf("ValidatePassword took %v after its context expired, want it to stop padding", elapsed)
+	}
+}
+
+func TestCreateUserAuditInTransaction(t *testing.T) {
+	errAudit := errors.New("audit_log unavailable")
+
+	tests := []struct {
+		name      string
+		auditErr  error
+		wantCalls []string
+		wantLog   int
+	}{
+		{name: "audited", wantCalls: []string{"WithTx", "Create", "AddAuditEvent"}, wantLog: 1},
+		{name: "audit failure fails the create", auditErr: errAudit, wantCalls: []string{"WithTx", "Create", "AddAuditEvent"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var stored audit.Event
+			repo := &mocks.MockUserRepository{
+				CreateFunc: func(ctx context.Context, user *model.User) error { return nil },
+				AddAuditEventFunc: func(ctx context.Context, event audit.Event) error {
+					stored = event
+					return tt.auditErr
+				},
+			}
+			log := &auditLog{}
+			svc := newTestService(repo, service.Options{Audit: log})
+
+			user, err := svc.CreateUser(context.Background(), "jane@example.com", testPassword, "Jane", "Doe", "")
+			if !errors.Is(err, tt.auditErr) {
+				t.Fatalf("CreateUser = %v, want %v", err, tt.auditErr)
+			}
+
+			var calls []string
+			for _, call := range repo.Calls() {
+				calls = append(calls, call.Method)
+			}
+			if !reflect.DeepEqual(calls, tt.wantCalls) {
+				t.Errorf("repository calls = %v, want %v", calls, tt.wantCalls)
+			}
+			if stored.Action != "user.created" || (user != nil && stored.TargetID != user.ID) {
+				t.Errorf("stored audit event = %+v, want user.created for the new user", stored)
+			}
+			if len(log.events) != tt.wantLog {
+				t.Errorf("recorder got %d events, want %d", len(log.events), tt.wantLog)
+			}
+		})
+	}
+}
@@ -0,0 +1,136 @@
+package service
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/golang-standards/project-layout/internal/app/user-service/model"
+	"github.com/golang-standards/project-layout/internal/app/user-service/repository"
+	"github.com/golang-standards/project-layout/internal/pkg/apperror"
+	"github.com/golang-standards/project-layout/internal/pkg/audit"
+	"github.com/golang-standards/project-layout/internal/pkg/database"
+	"github.com/golang-standards/project-layout/internal/pkg/events"
+)
+
+var (
+	ErrInvalidStatus           = apperror.New(apperror.CodeInvalidArgument, "invalid user status")
+	ErrInvalidStatusTransition = apperror.New(apperror.CodeFailedPrecondition, "user status transition not allowed")
+	ErrInvalidDateRange        = apperror.New(apperror.CodeInvalidArgument, "invalid date range")
+	ErrAccountInactive         = apperror.New(apperror.CodeFailedPrecondition, "account is not active")
+)
+
+// validStatuses are the statuses a user can be set to
+var validStatuses = map[model.UserStatus]bool{
+	model.UserStatusActive:    true,
+	model.UserStatusInactive:  true,
+	model.UserStatusSuspended: true,
+}
+
+// SetUserStatus changes only a user's status. Soft-deleted users are not found and must be
+// restored first; setting the current status is a no-op.
+func (s *userService) SetUserStatus(ctx context.Context, id string, status model.UserStatus) (*model.User, error) {
+	ctx, span := tracer.Start(ctx, "UserService.SetUserStatus")
+	defer span.End()
+
+	s.log(ctx).Info("Setting user status", "user_id", id, "status", status)
+
+	if !validStatuses[status] {
+		return nil, fmt.Errorf("%w: %q", ErrInvalidStatus, status)
+	}
+
+	return s.changeStatus(ctx, id, status, "user.status_changed")
+}
+
+// DeactivateUser makes a user inactive, blocking logins while keeping their data
+func (s *userService) DeactivateUser(ctx context.Context, id string) (*model.User, error) {
+	ctx, span := tracer.Start(ctx, "UserService.DeactivateUser")
+	defer span.End()
+
+	s.log(ctx).Info("Deactivating user", "user_id", id)
+
+	return s.changeStatus(ctx, id, model.UserStatusInactive, "user.deactivated")
+}
+
+// ReactivateUser makes an inactive user active again. Suspended users are left to
+// SetUserStatus so that lifting a suspension is an explicit decision.
+func (s *userService) ReactivateUser(ctx context.Context, id string) (*model.User, error) {
+	ctx, span := tracer.Start(ctx, "UserService.ReactivateUser")
+	defer span.End()
+
+	s.log(ctx).Info("Reactivating user", "user_id", id)
+
+	user, err := s.repo.GetByID(database.WithPrimary(ctx), id)
+	if err != nil {
+		s.log(ctx).Error("Failed to get user for reactivation", "error", err, "user_id", id)
+		return nil, err
+	}
+	if user.Status == model.UserStatusSuspended {
+		return nil, fmt.Errorf("%w: user is suspended", ErrInvalidStatusTransition)
+	}
+
+	return s.changeStatus(ctx, id, model.UserStatusActive, "user.reactivated")
+}
+
+// changeStatus sets a user's status and records action as the audit event
+func (s *userService) changeStatus(ctx context.Context, id string, status model.UserStatus, action string) (*model.User, error) {
+	// The transition is checked against the primary so a replica can't approve a stale one
+	user, err := s.repo.GetByID(database.WithPrimary(ctx), id)
+	if err != nil {
+		s.log(ctx).Error("Failed to get user for status change", "error", err, "user_id", id)
+		return nil, err
+	}
+	if user.Status == status {
+		return user, nil
+	}
+	if err := s.checkStatusTransition(user, status); err != nil {
+		return nil, err
+	}
+
+	from := user.Status
+	user.Status = status
+	err = s.write(ctx, func(repo repository.UserRepository) ([]events.Event, error) {
+		if err := repo.Update(ctx, user, []string{"status"}); err != nil {
+			return nil, err
+		}
+		return []events.Event{events.New(events.UserUpdated, id, []string{"status"})}, nil
+	})
+	if err != nil {
+		s.log(ctx).Error("Failed to set user status", "error", err, "user_id", id)
+		return nil, err
+	}
+
+	s.recordAudit(ctx, audit.NewEvent(ctx, action, id, map[string]string{
+		"from": string(from),
+		"to":   string(status),
+	}))
+
+	s.log(ctx).Info("User status set successfully", "user_id", id, "from", from, "to", status)
+	return user, nil
+}
+
+// checkStatusTransition rejects status changes that would bypass other rules. Users awaiting
+// email verification can only be activated by verifying their email, and anonymized users
+// can't be activated at all.
+func (s *userService) checkStatusTransition(user *model.User, to model.UserStatus) error {
+	if to == model.UserStatusActive && user.IsAnonymized() {
+		return fmt.Errorf("%w: user is anonymized", ErrInvalidStatusTransition)
+	}
+	if to == model.UserStatusActive && s.requireEmailVerification && !user.EmailVerified {
+		return fmt.Errorf("%w: email is not verified", ErrInvalidStatusTransition)
+	}
+	return nil
+}
+
+// checkFilter rejects list filters on an unknown status or with an inverted date range
+func checkFilter(filter repository.ListFilter) error {
+	if filter.Status != "" && !validStatuses[filter.Status] {
+		return fmt.Errorf("%w: %q", ErrInvalidStatus, filter.Status)
+	}
+	if !filter.CreatedAfter.IsZero() && !filter.CreatedBefore.IsZero() && filter.CreatedAfter.After(filter.CreatedBefore) {
+		return fmt.Errorf("%w: created_after is later than created_before", ErrInvalidDateRange)
+	}
+	if !filter.UpdatedAfter.IsZero() && !filter.UpdatedBefore.IsZero() && filter.UpdatedAfter.After(filter.UpdatedBefore) {
+		return fmt.Errorf("%w: updated_after is later than updated_before", ErrInvalidDateRange)
+	}
+	return nil
+}
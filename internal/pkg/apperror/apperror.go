@@ -0,0 +1,95 @@
+package apperror
+
+import (
+	"context"
+	"errors"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// Code classifies a domain error independently of the transport
+type Code string
+
+const (
+	CodeInternal           Code = "internal"
+	CodeNotFound           Code = "not_found"
+	CodeAlreadyExists      Code = "already_exists"
+	CodeInvalidArgument    Code = "invalid_argument"
+	CodeFailedPrecondition Code = "failed_precondition"
+	CodePermissionDenied   Code = "permission_denied"
+	CodeUnauthenticated    Code = "unauthenticated"
+	CodeConflict           Code = "conflict"
+	CodeRateLimited        Code = "rate_limited"
+	CodeNotEnabled         Code = "not_enabled"
+)
+
+// grpcCodes maps each Code to its gRPC status code
+var grpcCodes = map[Code]codes.Code{
+	CodeInternal:           codes.Internal,
+	CodeNotFound:           codes.NotFound,
+	CodeAlreadyExists:      codes.AlreadyExists,
+	CodeInvalidArgument:    codes.InvalidArgument,
+	CodeFailedPrecondition: codes.FailedPrecondition,
+	CodePermissionDenied:   codes.PermissionDenied,
+	CodeUnauthenticated:    codes.Unauthenticated,
+	CodeConflict:           codes.Aborted,
+	CodeRateLimited:        codes.ResourceExhausted,
+	CodeNotEnabled:         codes.Unimplemented,
+}
+
+// AppError is a domain error with a code. Sentinel errors are *AppError values, so
+// errors.Is keeps working on them while wrapping with fmt.Errorf adds detail.
+type AppError struct {
+	Code    Code
+	Message string
+	Cause   error
+}
+
+// New creates an AppError without a cause
+func New(code Code, message string) *AppError {
+	return &AppError{Code: code, Message: message}
+}
+
+// Wrap creates an AppError caused by err
+func Wrap(code Code, message string, err error) *AppError {
+	return &AppError{Code: code, Message: message, Cause: err}
+}
+
+func (e *AppError) Error() string {
+	if e.Cause != nil {
+		return e.Message + ": " + e.Cause.Error()
+	}
+	return e.Message
+}
+
+func (e *AppError) Unwrap() error {
+	return e.Cause
+}
+
+// CodeOf returns the code of the first AppError in err's chain, or CodeInternal
+func CodeOf(err error) Code {
+	var appErr *AppError
+	if errors.As(err, &appErr) {
+		return appErr.Code
+	}
+	return CodeInternal
+}
+
+// ToGRPCStatus translates err into a gRPC status. Context errors map to Canceled and
+// DeadlineExceeded. Domain errors keep their full message, which callers add detail to
+// through wrapping; anything else is Internal with a generic message so internals don't leak.
+func ToGRPCStatus(err error) *status.Status {
+	if err == nil {
+		return status.New(codes.OK, "")
+	}
+	if errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		return status.FromContextError(err)
+	}
+
+	code := CodeOf(err)
+	if code == CodeInternal {
+		return status.New(codes.Internal, "internal error")
+	}
+	return status.New(grpcCodes[code], err.Error())
+}
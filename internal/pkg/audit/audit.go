@@ -0,0 +1,60 @@
+package audit
+
+import (
+	"context"
+	"time"
+
+	"github.com/golang-standards/project-layout/internal/pkg/auth"
+	"github.com/golang-standards/project-layout/internal/pkg/logger"
+)
+
+// Event describes an auditable action on a user
+type Event struct {
+	Action   string
+	TargetID string
+	ActorID  string
+	Details  map[string]string
+	Time     time.Time
+	// ImpersonatorID is the admin who acted as ActorID, if any
+	ImpersonatorID string
+}
+
+// Recorder persists audit events
+type Recorder interface {
+	Record(ctx context.Context, event Event) error
+}
+
+// NewEvent creates an event for action on targetID, attributed to the caller in ctx
+// and to the impersonating admin, if any
+func NewEvent(ctx context.Context, action, targetID string, details map[string]string) Event {
+	principal, _ := auth.PrincipalFromContext(ctx)
+	return Event{
+		Action:         action,
+		TargetID:       targetID,
+		ActorID:        principal.UserID,
+		ImpersonatorID: principal.ImpersonatorID,
+		Details:        details,
+		Time:           time.Now().UTC(),
+	}
+}
+
+type logRecorder struct {
+	logger logger.Logger
+}
+
+// NewLogRecorder creates a recorder that writes audit events to the log
+func NewLogRecorder(logger logger.Logger) Recorder {
+	return &logRecorder{logger: logger}
+}
+
+func (r *logRecorder) Record(ctx context.Context, event Event) error {
+	r.logger.Info("Audit event",
+		"action", event.Action,
+		"target_id", event.TargetID,
+		"actor_id", event.ActorID,
+		"impersonator_id", event.ImpersonatorID,
+		"details", event.Details,
+		"time", event.Time,
+	)
+	return nil
+}
@@ -0,0 +1,53 @@
+package auth
+
+import "context"
+
+// Role is the authorization role of a caller
+type Role string
+
+const (
+	RoleAdmin Role = "admin"
+	RoleUser  Role = "user"
+)
+
+// Principal identifies the authenticated caller of a request
+type Principal struct {
+	UserID string
+	Role   Role
+	// OrgID is the caller's organization (tenant), if any
+	OrgID string
+	// ImpersonatorID is the admin acting on behalf of UserID, if impersonating
+	ImpersonatorID string
+}
+
+// IsAdmin reports whether the principal has the admin role
+func (p Principal) IsAdmin() bool {
+	return p.Role == RoleAdmin
+}
+
+// IsImpersonated reports whether an admin is acting on behalf of the principal
+func (p Principal) IsImpersonated() bool {
+	return p.ImpersonatorID != ""
+}
+
+type principalKey struct{}
+
+// WithPrincipal returns a copy of ctx carrying the authenticated principal
+func WithPrincipal(ctx context.Context, p Principal) context.Context {
+	return context.WithValue(ctx, principalKey{}, p)
+}
+
+// PrincipalFromContext returns the authenticated principal, if any
+func PrincipalFromContext(ctx context.Context) (Principal, bool) {
+	p, ok := ctx.Value(principalKey{}).(Principal)
+	return p, ok
+}
+
+// UserIDFromContext returns the authenticated user ID, if any
+func UserIDFromContext(ctx context.Context) (string, bool) {
+	p, ok := PrincipalFromContext(ctx)
+	if !ok || p.UserID == "" {
+		return "", false
+	}
+	return p.UserID, true
+}
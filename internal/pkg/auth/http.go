@@ -0,0 +1,35 @@
+package auth
+
+import (
+	"net/http"
+	"strings"
+)
+
+// RequireRoleHTTP returns an HTTP middleware that only lets requests whose bearer token
+// has role through. Requests without a valid token get 401 and others 403. A nil
+// authenticator, i.e. no JWT secret configured, refuses every request.
+func RequireRoleHTTP(a *Authenticator, role Role, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if a == nil {
+			http.Error(w, "authentication is not configured", http.StatusForbidden)
+			return
+		}
+
+		token, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+		if !ok {
+			http.Error(w, "authentication required", http.StatusUnauthorized)
+			return
+		}
+		principal, err := a.Verify(strings.TrimSpace(token))
+		if err != nil {
+			http.Error(w, "invalid or expired token", http.StatusUnauthorized)
+			return
+		}
+		if principal.Role != role {
+			http.Error(w, "requires the "+string(role)+" role", http.StatusForbidden)
+			return
+		}
+
+		next.ServeHTTP(w, r.WithContext(WithPrincipal(r.Context(), principal)))
+	})
+}
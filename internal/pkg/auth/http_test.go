@@ -0,0 +1,54 @@
+package auth
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestRequireRoleHTTP(t *testing.T) {
+	a := NewAuthenticator(testSecret, "user-service")
+	adminToken, err := a.Issue(Principal{UserID: "a1", Role: RoleAdmin}, time.Hour)
+	if err != nil {
+		t.Fatalf("Issue: %v", err)
+	}
+	userToken, err := a.Issue(Principal{UserID: "u1", Role: RoleUser}, time.Hour)
+	if err != nil {
+		t.Fatalf("Issue: %v", err)
+	}
+
+	tests := []struct {
+		name          string
+		authenticator *Authenticator
+		authorization string
+		wantStatus    int
+	}{
+		{name: "admin", authenticator: a, authorization: "Bearer " + adminToken, wantStatus: http.StatusOK},
+		{name: "user", authenticator: a, authorization: "Bearer " + userToken, wantStatus: http.StatusForbidden},
+		{name: "anonymous", authenticator: a, wantStatus: http.StatusUnauthorized},
+		{name: "invalid token", authenticator: a, authorization: "Bearer nope", wantStatus: http.StatusUnauthorized},
+		{name: "not configured", authorization: "Bearer " + adminToken, wantStatus: http.StatusForbidden},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			handler := RequireRoleHTTP(tt.authenticator, RoleAdmin, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if _, ok := PrincipalFromContext(r.Context()); !ok {
+					t.Error("expected the principal in the request context")
+				}
+			}))
+
+			req := httptest.NewRequest(http.MethodPost, "/drain", nil)
+			if tt.authorization != "" {
+				req.Header.Set("Authorization", tt.authorization)
+			}
+			rec := httptest.NewRecorder()
+			handler.ServeHTTP(rec, req)
+
+			if rec.Code != tt.wantStatus {
+				t.Errorf("status = %d, want %d", rec.Code, tt.wantStatus)
+			}
+		})
+	}
+}
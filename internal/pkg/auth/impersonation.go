@@ -0,0 +1,80 @@
+package auth
+
+import (
+	"context"
+
+	"github.com/golang-standards/project-layout/internal/pkg/interceptors"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+// ImpersonateMetadataKey is the metadata key an admin sets to act on behalf of a user
+const ImpersonateMetadataKey = "x-impersonate-user"
+
+// Impersonator lets an admin act as the user named in ImpersonateMetadataKey
+type Impersonator struct {
+	enabled bool
+	allowed map[string]bool
+}
+
+// NewImpersonator creates an impersonator; only allowedMethods (full gRPC method names)
+// may be called while impersonating
+func NewImpersonator(enabled bool, allowedMethods []string) *Impersonator {
+	allowed := make(map[string]bool, len(allowedMethods))
+	for _, method := range allowedMethods {
+		allowed[method] = true
+	}
+	return &Impersonator{enabled: enabled, allowed: allowed}
+}
+
+// Impersonate returns ctx with the impersonated principal when the caller asked for
+// one. That principal keeps the admin as ImpersonatorID and has the user role.
+func (i *Impersonator) Impersonate(ctx context.Context, method string) (context.Context, error) {
+	md, _ := metadata.FromIncomingContext(ctx)
+	values := md.Get(ImpersonateMetadataKey)
+	if len(values) == 0 || values[0] == "" {
+		return ctx, nil
+	}
+
+	if !i.enabled {
+		return nil, status.Error(codes.PermissionDenied, "impersonation is not enabled")
+	}
+	admin, ok := PrincipalFromContext(ctx)
+	if !ok || !admin.IsAdmin() || admin.IsImpersonated() {
+		return nil, status.Error(codes.PermissionDenied, "impersonation requires admin role")
+	}
+	if !i.allowed[method] {
+		return nil, status.Errorf(codes.PermissionDenied, "%s is not permitted while impersonating", method)
+	}
+
+	return WithPrincipal(ctx, Principal{
+		UserID:         values[0],
+		Role:           RoleUser,
+		ImpersonatorID: admin.UserID,
+	}), nil
+}
+
+// ImpersonationInterceptor returns a new unary server interceptor applying i. It must
+// run after the principal is authenticated.
+func ImpersonationInterceptor(i *Impersonator) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		ctx, err := i.Impersonate(ctx, info.FullMethod)
+		if err != nil {
+			return nil, err
+		}
+		return handler(ctx, req)
+	}
+}
+
+// ImpersonationStreamInterceptor is the streaming counterpart of ImpersonationInterceptor
+func ImpersonationStreamInterceptor(i *Impersonator) grpc.StreamServerInterceptor {
+	return func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		ctx, err := i.Impersonate(ss.Context(), info.FullMethod)
+		if err != nil {
+			return err
+		}
+		return handler(srv, interceptors.WrapServerStream(ctx, ss))
+	}
+}
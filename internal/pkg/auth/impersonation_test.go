@@ -0,0 +1,117 @@
+package auth
+
+import (
+	"context"
+	"testing"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+// contextStream is a server stream carrying ctx
+type contextStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (s *contextStream) Context() context.Context { return s.ctx }
+
+func TestImpersonationInterceptor(t *testing.T) {
+	const (
+		allowedMethod    = "/user.v1.UserService/GetUser"
+		restrictedMethod = "/user.v1.UserService/DeleteUser"
+	)
+	admin := Principal{UserID: "admin-1", Role: RoleAdmin}
+
+	tests := []struct {
+		name          string
+		enabled       bool
+		principal     *Principal
+		impersonate   string
+		method        string
+		wantCode      codes.Code
+		wantPrincipal Principal
+	}{
+		{name: "no header", enabled: true, principal: &admin, method: restrictedMethod, wantPrincipal: admin},
+		{
+			name:          "admin impersonates",
+			enabled:       true,
+			principal:     &admin,
+			impersonate:   "user-1",
+			method:        allowedMethod,
+			wantPrincipal: Principal{UserID: "user-1", Role: RoleUser, ImpersonatorID: "admin-1"},
+		},
+		{name: "restricted method", enabled: true, principal: &admin, impersonate: "user-1", method: restrictedMethod, wantCode: codes.PermissionDenied},
+		{name: "disabled", principal: &admin, impersonate: "user-1", method: allowedMethod, wantCode: codes.PermissionDenied},
+		{
+			name:        "user may not impersonate",
+			enabled:     true,
+			principal:   &Principal{UserID: "user-2", Role: RoleUser},
+			impersonate: "user-1",
+			method:      allowedMethod,
+			wantCode:    codes.PermissionDenied,
+		},
+		{name: "anonymous", enabled: true, impersonate: "user-1", method: allowedMethod, wantCode: codes.PermissionDenied},
+		{
+			name:        "no nested impersonation",
+			enabled:     true,
+			principal:   &Principal{UserID: "admin-2", Role: RoleAdmin, ImpersonatorID: "admin-1"},
+			impersonate: "user-1",
+			method:      allowedMethod,
+			wantCode:    codes.PermissionDenied,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			if tt.principal != nil {
+				ctx = WithPrincipal(ctx, *tt.principal)
+			}
+			if tt.impersonate != "" {
+				ctx = metadata.NewIncomingContext(ctx, metadata.Pairs(ImpersonateMetadataKey, tt.impersonate))
+			}
+
+			impersonator := NewImpersonator(tt.enabled, []string{allowedMethod})
+			calls := map[string]func() (Principal, bool, error){
+				"unary": func() (Principal, bool, error) {
+					var got Principal
+					called := false
+					_, err := ImpersonationInterceptor(impersonator)(ctx, nil, &grpc.UnaryServerInfo{FullMethod: tt.method},
+						func(ctx context.Context, req interface{}) (interface{}, error) {
+							called = true
+							got, _ = PrincipalFromContext(ctx)
+							return nil, nil
+						})
+					return got, called, err
+				},
+				"stream": func() (Principal, bool, error) {
+					var got Principal
+					called := false
+					err := ImpersonationStreamInterceptor(impersonator)(nil, &contextStream{ctx: ctx}, &grpc.StreamServerInfo{FullMethod: tt.method},
+						func(srv interface{}, ss grpc.ServerStream) error {
+							called = true
+							got, _ = PrincipalFromContext(ss.Context())
+							return nil
+						})
+					return got, called, err
+				},
+			}
+
+			for kind, call := range calls {
+				got, called, err := call()
+				if code := status.Code(err); code != tt.wantCode {
+					t.Fatalf("%s: code = %v, want %v", kind, code, tt.wantCode)
+				}
+				if called != (tt.wantCode == codes.OK) {
+					t.Fatalf("%s: handler called = %v, want %v", kind, called, tt.wantCode == codes.OK)
+				}
+				if called && got != tt.wantPrincipal {
+					t.Errorf("%s: principal = %+v, want %+v", kind, got, tt.wantPrincipal)
+				}
+			}
+		})
+	}
+}
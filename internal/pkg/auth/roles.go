@@ -0,0 +1,58 @@
+package auth
+
+import (
+	"context"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// RequireRole returns a new unary server interceptor that only lets callers with role
+// call the given methods (full gRPC method names); other methods are not checked.
+// Callers without a principal get Unauthenticated and others PermissionDenied.
+// It must run after the principal is authenticated.
+func RequireRole(role Role, methods ...string) grpc.UnaryServerInterceptor {
+	guarded := methodSet(methods)
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		if guarded[info.FullMethod] {
+			if err := checkRole(ctx, role, info.FullMethod); err != nil {
+				return nil, err
+			}
+		}
+		return handler(ctx, req)
+	}
+}
+
+// RequireRoleStream is the streaming counterpart of RequireRole
+func RequireRoleStream(role Role, methods ...string) grpc.StreamServerInterceptor {
+	guarded := methodSet(methods)
+	return func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		if guarded[info.FullMethod] {
+			if err := checkRole(ss.Context(), role, info.FullMethod); err != nil {
+				return err
+			}
+		}
+		return handler(srv, ss)
+	}
+}
+
+// checkRole reports an error unless the caller has role
+func checkRole(ctx context.Context, role Role, method string) error {
+	principal, ok := PrincipalFromContext(ctx)
+	if !ok {
+		return status.Error(codes.Unauthenticated, "authentication required")
+	}
+	if principal.Role != role {
+		return status.Errorf(codes.PermissionDenied, "%s requires the %s role", method, role)
+	}
+	return nil
+}
+
+func methodSet(methods []string) map[string]bool {
+	set := make(map[string]bool, len(methods))
+	for _, method := range methods {
+		set[method] = true
+	}
+	return set
+}
@@ -0,0 +1,125 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/golang-standards/project-layout/internal/pkg/interceptors"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+// AuthorizationMetadataKey is the metadata key carrying the bearer token; the gateway
+// forwards the HTTP Authorization header under it
+const AuthorizationMetadataKey = "authorization"
+
+var ErrInvalidToken = errors.New("invalid token")
+
+// claims are the JWT claims of an access token; the subject is the user ID
+type claims struct {
+	Role  Role   `json:"role"`
+	OrgID string `json:"org_id,omitempty"`
+	jwt.RegisteredClaims
+}
+
+// Authenticator issues and verifies HMAC-signed JWT access tokens
+type Authenticator struct {
+	secret []byte
+	issuer string
+	now    func() time.Time
+}
+
+// NewAuthenticator creates an authenticator signing with secret. Tokens must carry issuer.
+func NewAuthenticator(secret, issuer string) *Authenticator {
+	return &Authenticator{secret: []byte(secret), issuer: issuer, now: time.Now}
+}
+
+// Issue returns a token for p that expires after ttl
+func (a *Authenticator) Issue(p Principal, ttl time.Duration) (string, error) {
+	now := a.now()
+	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims{
+		Role:  p.Role,
+		OrgID: p.OrgID,
+		RegisteredClaims: jwt.RegisteredClaims{
+			Subject:   p.UserID,
+			Issuer:    a.issuer,
+			IssuedAt:  jwt.NewNumericDate(now),
+			ExpiresAt: jwt.NewNumericDate(now.Add(ttl)),
+		},
+	})
+	return token.SignedString(a.secret)
+}
+
+// Verify checks the signature, issuer and expiry of token and returns its principal
+func (a *Authenticator) Verify(token string) (Principal, error) {
+	var c claims
+	_, err := jwt.ParseWithClaims(token, &c, func(*jwt.Token) (interface{}, error) {
+		return a.secret, nil
+	},
+		jwt.WithValidMethods([]string{jwt.SigningMethodHS256.Alg()}),
+		jwt.WithIssuer(a.issuer),
+		jwt.WithExpirationRequired(),
+		jwt.WithTimeFunc(a.now),
+	)
+	if err != nil {
+		return Principal{}, fmt.Errorf("%w: %w", ErrInvalidToken, err)
+	}
+	if c.Subject == "" {
+		return Principal{}, fmt.Errorf("%w: missing subject", ErrInvalidToken)
+	}
+	if c.Role != RoleAdmin && c.Role != RoleUser {
+		return Principal{}, fmt.Errorf("%w: unknown role %q", ErrInvalidToken, c.Role)
+	}
+
+	return Principal{UserID: c.Subject, Role: c.Role, OrgID: c.OrgID}, nil
+}
+
+// Authenticate returns ctx carrying the principal of the bearer token in the incoming
+// metadata. Requests without a token stay anonymous; an invalid token is Unauthenticated.
+func (a *Authenticator) Authenticate(ctx context.Context) (context.Context, error) {
+	md, _ := metadata.FromIncomingContext(ctx)
+	values := md.Get(AuthorizationMetadataKey)
+	if len(values) == 0 || values[0] == "" {
+		return ctx, nil
+	}
+
+	token, ok := strings.CutPrefix(values[0], "Bearer ")
+	if !ok {
+		return nil, status.Error(codes.Unauthenticated, "authorization must be a bearer token")
+	}
+	principal, err := a.Verify(strings.TrimSpace(token))
+	if err != nil {
+		return nil, status.Error(codes.Unauthenticated, "invalid or expired token")
+	}
+	return WithPrincipal(ctx, principal), nil
+}
+
+// AuthenticationInterceptor returns a new unary server interceptor that sets the
+// principal from the request's bearer token. It must run before any stage reading
+// the principal.
+func AuthenticationInterceptor(a *Authenticator) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		ctx, err := a.Authenticate(ctx)
+		if err != nil {
+			return nil, err
+		}
+		return handler(ctx, req)
+	}
+}
+
+// AuthenticationStreamInterceptor is the streaming counterpart of AuthenticationInterceptor
+func AuthenticationStreamInterceptor(a *Authenticator) grpc.StreamServerInterceptor {
+	return func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		ctx, err := a.Authenticate(ss.Context())
+		if err != nil {
+			return err
+		}
+		return handler(srv, interceptors.WrapServerStream(ctx, ss))
+	}
+}
@@ -0,0 +1,148 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+const testSecret = "0123456789abcdef0123456789abcdef"
+
+func TestAuthenticatorVerify(t *testing.T) {
+	now := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	issuer := NewAuthenticator(testSecret, "user-service")
+	issuer.now = func() time.Time { return now }
+
+	issue := func(t *testing.T, a *Authenticator, p Principal, ttl time.Duration) string {
+		t.Helper()
+		token, err := a.Issue(p, ttl)
+		if err != nil {
+			t.Fatalf("Issue: %v", err)
+		}
+		return token
+	}
+	otherSecret := NewAuthenticator("fedcba9876543210fedcba9876543210", "user-service")
+	otherSecret.now = issuer.now
+	otherIssuer := NewAuthenticator(testSecret, "someone-else")
+	otherIssuer.now = issuer.now
+
+	tests := []struct {
+		name    string
+		token   func(t *testing.T) string
+		want    Principal
+		wantErr bool
+	}{
+		{
+			name:  "user token",
+			token: func(t *testing.T) string { return issue(t, issuer, Principal{UserID: "u1", Role: RoleUser}, time.Hour) },
+			want:  Principal{UserID: "u1", Role: RoleUser},
+		},
+		{
+			name: "admin token with organization",
+			token: func(t *testing.T) string {
+				return issue(t, issuer, Principal{UserID: "a1", Role: RoleAdmin, OrgID: "org-1"}, time.Hour)
+			},
+			want: Principal{UserID: "a1", Role: RoleAdmin, OrgID: "org-1"},
+		},
+		{
+			name: "expired",
+			token: func(t *testing.T) string {
+				return issue(t, issuer, Principal{UserID: "u1", Role: RoleUser}, -time.Minute)
+			},
+			wantErr: true,
+		},
+		{
+			name: "wrong secret",
+			token: func(t *testing.T) string {
+				return issue(t, otherSecret, Principal{UserID: "u1", Role: RoleAdmin}, time.Hour)
+			},
+			wantErr: true,
+		},
+		{
+			name: "wrong issuer",
+			token: func(t *testing.T) string {
+				return issue(t, otherIssuer, Principal{UserID: "u1", Role: RoleAdmin}, time.Hour)
+			},
+			wantErr: true,
+		},
+		{
+			name:    "unknown role",
+			token:   func(t *testing.T) string { return issue(t, issuer, Principal{UserID: "u1", Role: "root"}, time.Hour) },
+			wantErr: true,
+		},
+		{
+			name:    "missing subject",
+			token:   func(t *testing.T) string { return issue(t, issuer, Principal{Role: RoleAdmin}, time.Hour) },
+			wantErr: true,
+		},
+		{
+			name:    "garbage",
+			token:   func(t *testing.T) string { return "not.a.token" },
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := issuer.Verify(tt.token(t))
+			if tt.wantErr {
+				if !errors.Is(err, ErrInvalidToken) {
+					t.Fatalf("err = %v, want ErrInvalidToken", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Verify: %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("principal = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestAuthenticatorAuthenticate(t *testing.T) {
+	a := NewAuthenticator(testSecret, "user-service")
+	token, err := a.Issue(Principal{UserID: "u1", Role: RoleUser}, time.Hour)
+	if err != nil {
+		t.Fatalf("Issue: %v", err)
+	}
+
+	tests := []struct {
+		name          string
+		authorization string
+		wantUserID    string
+		wantCode      codes.Code
+	}{
+		{name: "anonymous"},
+		{name: "bearer token", authorization: "Bearer " + token, wantUserID: "u1"},
+		{name: "not a bearer token", authorization: "Basic dXNlcjpwYXNz", wantCode: codes.Unauthenticated},
+		{name: "invalid token", authorization: "Bearer " + token + "x", wantCode: codes.Unauthenticated},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			if tt.authorization != "" {
+				ctx = metadata.NewIncomingContext(ctx, metadata.Pairs(AuthorizationMetadataKey, tt.authorization))
+			}
+
+			ctx, err := a.Authenticate(ctx)
+			if got := status.Code(err); got != tt.wantCode {
+				t.Fatalf("code = %v, want %v", got, tt.wantCode)
+			}
+			if err != nil {
+				return
+			}
+			userID, _ := UserIDFromContext(ctx)
+			if userID != tt.wantUserID {
+				t.Errorf("user ID = %q, want %q", userID, tt.wantUserID)
+			}
+		})
+	}
+}
@@ -0,0 +1,89 @@
+package concurrency
+
+import (
+	"context"
+	"strings"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// healthServicePrefix identifies gRPC health checks, which are never limited
+const healthServicePrefix = "/grpc.health.v1.Health/"
+
+// errAtLimit rejects requests beyond the limit
+var errAtLimit = status.Error(codes.ResourceExhausted, "server is at its concurrent request limit")
+
+// Limiter caps the number of requests the server handles at once
+type Limiter struct {
+	slots chan struct{}
+}
+
+// NewLimiter creates a limiter allowing at most max in-flight requests.
+// A max of zero or less disables the limit.
+func NewLimiter(max int) *Limiter {
+	if max <= 0 {
+		return &Limiter{}
+	}
+	return &Limiter{slots: make(chan struct{}, max)}
+}
+
+// TryAcquire reserves a slot, returning false when the limit is reached
+func (l *Limiter) TryAcquire() bool {
+	if l.slots == nil {
+		return true
+	}
+	select {
+	case l.slots <- struct{}{}:
+		return true
+	default:
+		return false
+	}
+}
+
+// Release frees a slot previously reserved with TryAcquire
+func (l *Limiter) Release() {
+	if l.slots == nil {
+		return
+	}
+	<-l.slots
+}
+
+// InFlight returns the number of requests currently holding a slot
+func (l *Limiter) InFlight() int {
+	return len(l.slots)
+}
+
+// UnaryServerInterceptor returns a new unary server interceptor enforcing the global in-flight limit
+func UnaryServerInterceptor(limiter *Limiter) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		if strings.HasPrefix(info.FullMethod, healthServicePrefix) {
+			return handler(ctx, req)
+		}
+
+		if !limiter.TryAcquire() {
+			return nil, errAtLimit
+		}
+		defer limiter.Release()
+
+		return handler(ctx, req)
+	}
+}
+
+// StreamServerInterceptor returns a new stream server interceptor enforcing the global
+// in-flight limit; a stream holds its slot until it ends
+func StreamServerInterceptor(limiter *Limiter) grpc.StreamServerInterceptor {
+	return func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		if strings.HasPrefix(info.FullMethod, healthServicePrefix) {
+			return handler(srv, ss)
+		}
+
+		if !limiter.TryAcquire() {
+			return errAtLimit
+		}
+		defer limiter.Release()
+
+		return handler(srv, ss)
+	}
+}
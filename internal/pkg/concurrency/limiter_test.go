@@ -0,0 +1,53 @@
+package concurrency
+
+import (
+	"context"
+	"testing"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// fakeStream is a server stream carrying only a context
+type fakeStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (s *fakeStream) Context() context.Context { return s.ctx }
+
+func TestStreamServerInterceptor(t *testing.T) {
+	tests := []struct {
+		name     string
+		max      int
+		held     int
+		method   string
+		wantCode codes.Code
+	}{
+		{name: "under the limit", max: 2, held: 1, method: "/user.v1.UserService/StreamUsers"},
+		{name: "at the limit", max: 1, held: 1, method: "/user.v1.UserService/StreamUsers", wantCode: codes.ResourceExhausted},
+		{name: "health checks bypass", max: 1, held: 1, method: "/grpc.health.v1.Health/Watch"},
+		{name: "unlimited", held: 5, method: "/user.v1.UserService/StreamUsers"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			limiter := NewLimiter(tt.max)
+			for i := 0; i < tt.held; i++ {
+				limiter.TryAcquire()
+			}
+			held := limiter.InFlight()
+
+			err := StreamServerInterceptor(limiter)(nil, &fakeStream{ctx: context.Background()},
+				&grpc.StreamServerInfo{FullMethod: tt.method},
+				func(srv interface{}, ss grpc.ServerStream) error { return nil })
+			if got := status.Code(err); got != tt.wantCode {
+				t.Fatalf("code = %v, want %v", got, tt.wantCode)
+			}
+			if got := limiter.InFlight(); got != held {
+				t.Errorf("in flight after the stream = %d, want %d", got, held)
+			}
+		})
+	}
+}
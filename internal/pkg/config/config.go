@@ -1,17 +1,33 @@
 package config
 
 import (
+	"errors"
 	"fmt"
+	"net"
+	"os"
+	"path/filepath"
 	"strings"
+	"time"
 
 	"github.com/spf13/viper"
+	"golang.org/x/crypto/bcrypt"
 )
 
 // Config holds all configuration for the application
 type Config struct {
-	Server   ServerConfig
-	Database DatabaseConfig
-	Logger   LoggerConfig
+	Server    ServerConfig
+	Database  DatabaseConfig
+	Logger    LoggerConfig
+	Email     EmailConfig
+	Tracing   TracingConfig
+	Auth      AuthConfig
+	Service   ServiceConfig
+	Metrics   MetricsConfig
+	Redis     RedisConfig
+	Kafka     KafkaConfig
+	Outbox    OutboxConfig
+	SMTP      SMTPConfig
+	RateLimit RateLimitConfig `mapstructure:"rate_limit"`
 }
 
 // ServerConfig holds server configuration
@@ -19,41 +35,360 @@ type ServerConfig struct {
 	GRPCPort string `mapstructure:"grpc_port"`
 	HTTPPort string `mapstructure:"http_port"`
 	Host     string `mapstructure:"host"`
+	// MaxConcurrentRequests caps in-flight unary calls and open streams across the server (0 disables the limit)
+	MaxConcurrentRequests int `mapstructure:"max_concurrent_requests"`
+	// DedupWindow replays the result of a mutating request retried by the same caller with
+	// the same payload, or the same idempotency-key metadata, for this long (0 disables)
+	DedupWindow time.Duration `mapstructure:"dedup_window"`
+	// ReusePort binds the gRPC and HTTP listeners with SO_REUSEPORT so a new process can take over the ports
+	ReusePort bool `mapstructure:"reuse_port"`
+	// HealthCheckInterval is how often the database is pinged to update the gRPC health status
+	HealthCheckInterval time.Duration `mapstructure:"health_check_interval"`
+	// DefaultTimeout bounds requests and streams that arrive without a deadline (0 disables)
+	DefaultTimeout time.Duration `mapstructure:"default_timeout"`
+	// MethodTimeouts overrides DefaultTimeout per RPC method name, e.g. CreateUsersBatch
+	MethodTimeouts map[string]time.Duration `mapstructure:"method_timeouts"`
+	// TLS serves gRPC over TLS when a certificate is configured
+	TLS TLSConfig `mapstructure:"tls"`
+	// HTTPTLS serves the HTTP server (gateway, health, metrics) over TLS, independently of TLS
+	HTTPTLS TLSConfig `mapstructure:"http_tls"`
+	// MaxRecvMsgSize and MaxSendMsgSize cap gRPC message sizes in bytes; 0 keeps the
+	// gRPC defaults (4MB received, unlimited sent)
+	MaxRecvMsgSize int             `mapstructure:"max_recv_msg_size"`
+	MaxSendMsgSize int             `mapstructure:"max_send_msg_size"`
+	Keepalive      KeepaliveConfig `mapstructure:"keepalive"`
+	// ShutdownTimeout bounds draining in-flight requests on shutdown; keep it below the
+	// orchestrator's grace period (terminationGracePeriodSeconds on Kubernetes)
+	ShutdownTimeout time.Duration `mapstructure:"shutdown_timeout"`
+	// DisabledInterceptors names gRPC interceptor stages to leave out of the chain,
+	// e.g. "dedup"; see the interceptors package for the stages and their order
+	DisabledInterceptors []string `mapstructure:"disabled_interceptors"`
+	// CORS lets browser clients on other origins call the HTTP server
+	CORS CORSConfig `mapstructure:"cors"`
 }
 
+// CORSConfig is the CORS policy for the HTTP server. It is disabled while no origins are
+// allowed; "*" allows any origin but can't be combined with credentials.
+type CORSConfig struct {
+	AllowedOrigins   []string      `mapstructure:"allowed_origins"`
+	AllowedMethods   []string      `mapstructure:"allowed_methods"`
+	AllowedHeaders   []string      `mapstructure:"allowed_headers"`
+	ExposedHeaders   []string      `mapstructure:"exposed_headers"`
+	AllowCredentials bool          `mapstructure:"allow_credentials"`
+	MaxAge           time.Duration `mapstructure:"max_age"`
+}
+
+// Enabled reports whether any origin is allowed
+func (c CORSConfig) Enabled() bool {
+	return len(c.AllowedOrigins) > 0
+}
+
+// KeepaliveConfig holds gRPC server keepalive parameters and enforcement. Zero durations
+// keep the gRPC defaults, which never close idle or old connections.
+type KeepaliveConfig struct {
+	// MaxConnectionIdle closes connections without active RPCs after this long
+	MaxConnectionIdle time.Duration `mapstructure:"max_connection_idle"`
+	// MaxConnectionAge closes connections after this long so clients rebalance; in-flight
+	// RPCs, including StreamUsers exports, get MaxConnectionAgeGrace to finish
+	MaxConnectionAge      time.Duration `mapstructure:"max_connection_age"`
+	MaxConnectionAgeGrace time.Duration `mapstructure:"max_connection_age_grace"`
+	// Time and Timeout control server pings on idle connections
+	Time    time.Duration `mapstructure:"time"`
+	Timeout time.Duration `mapstructure:"timeout"`
+	// MinTime is the shortest client ping interval allowed before the connection is closed
+	MinTime time.Duration `mapstructure:"min_time"`
+	// PermitWithoutStream allows client pings when there are no active RPCs
+	PermitWithoutStream bool `mapstructure:"permit_without_stream"`
+}
+
+// TLSConfig holds a server certificate; TLS is enabled when CertFile is set
+type TLSConfig struct {
+	CertFile string `mapstructure:"cert_file"`
+	KeyFile  string `mapstructure:"key_file"`
+	// ClientCAFile requires clients to present a certificate signed by this CA (mutual TLS)
+	ClientCAFile string `mapstructure:"client_ca_file"`
+}
+
+// Enabled reports whether a certificate is configured
+func (t TLSConfig) Enabled() bool {
+	return t.CertFile != ""
+}
+
+// Storage drivers accepted by DatabaseConfig.Driver
+const (
+	DriverPostgres = "postgres"
+	DriverSQLite   = "sqlite"
+	DriverMemory   = "memory"
+)
+
 // DatabaseConfig holds database configuration
 type DatabaseConfig struct {
-	Host     string `mapstructure:"host"`
-	Port     string `mapstructure:"port"`
-	User     string `mapstructure:"user"`
-	Password string `mapstructure:"password"`
-	Database string `mapstructure:"database"`
-	SSLMode  string `mapstructure:"ssl_mode"`
+	// Driver selects the storage backend; the host and credential settings only apply to postgres
+	Driver string `mapstructure:"driver"`
+	// SQLitePath is the database file used by the sqlite driver
+	SQLitePath string `mapstructure:"sqlite_path"`
+	Host       string `mapstructure:"host"`
+	Port       string `mapstructure:"port"`
+	User       string `mapstructure:"user"`
+	Password   string `mapstructure:"password"`
+	Database   string `mapstructure:"database"`
+	SSLMode    string `mapstructure:"ssl_mode"`
+	// ApplicationName labels connections in pg_stat_activity (defaults to service name and version)
+	ApplicationName string `mapstructure:"application_name"`
+	// Connection pool settings. Zero values follow database/sql semantics:
+	// MaxOpenConns 0 means unlimited, MaxIdleConns 0 keeps no idle connections,
+	// and ConnMaxLifetime 0 reuses connections forever.
+	MaxOpenConns    int           `mapstructure:"max_open_conns"`
+	MaxIdleConns    int           `mapstructure:"max_idle_conns"`
+	ConnMaxLifetime time.Duration `mapstructure:"conn_max_lifetime"`
+	// Replicas are read replica hosts, as "host" or "host:port" (default port: Port), sharing
+	// the primary's credentials and database name. Reads go to a random replica and writes
+	// and transactions to the primary; with none, everything uses the primary.
+	Replicas []string `mapstructure:"replicas"`
+	// ReadYourWritesWindow pins reads to the primary for this long after a write (0 disables)
+	ReadYourWritesWindow time.Duration `mapstructure:"read_your_writes_window"`
+	Retry                RetryConfig   `mapstructure:"retry"`
+	// AutoMigrate applies pending migrations on startup; disable it when migrations
+	// run as a separate job (--migrate)
+	AutoMigrate bool `mapstructure:"auto_migrate"`
+}
+
+// RetryConfig holds retry configuration for transient database errors
+type RetryConfig struct {
+	MaxAttempts int           `mapstructure:"max_attempts"`
+	BaseDelay   time.Duration `mapstructure:"base_delay"`
+	// MaxDelay caps the backoff between attempts (0 leaves it uncapped)
+	MaxDelay time.Duration `mapstructure:"max_delay"`
+	// RetryableCodes overrides the Postgres SQLSTATE codes treated as transient
+	RetryableCodes []string `mapstructure:"retryable_codes"`
 }
 
 // LoggerConfig holds logger configuration
 type LoggerConfig struct {
 	Level  string `mapstructure:"level"`
 	Format string `mapstructure:"format"`
+	// LogPayloads logs gRPC request and response messages at debug level, with secrets redacted
+	LogPayloads bool `mapstructure:"log_payloads"`
+	// MaxPayloadBytes truncates each logged payload (0 disables truncation)
+	MaxPayloadBytes int `mapstructure:"max_payload_bytes"`
+}
+
+// EmailConfig holds email canonicalization configuration
+type EmailConfig struct {
+	CanonicalizeAliases bool                `mapstructure:"canonicalize_aliases"`
+	Providers           []EmailProviderRule `mapstructure:"providers"`
+}
+
+// EmailProviderRule describes how aliases are collapsed for a mail provider
+type EmailProviderRule struct {
+	Domains         []string `mapstructure:"domains"`
+	StripDots       bool     `mapstructure:"strip_dots"`
+	StripPlus       bool     `mapstructure:"strip_plus"`
+	CanonicalDomain string   `mapstructure:"canonical_domain"`
+}
+
+// TracingConfig holds OpenTelemetry tracing configuration
+type TracingConfig struct {
+	Enabled     bool    `mapstructure:"enabled"`
+	Endpoint    string  `mapstructure:"endpoint"`
+	Insecure    bool    `mapstructure:"insecure"`
+	SampleRate  float64 `mapstructure:"sample_rate"`
+	ServiceName string  `mapstructure:"service_name"`
+}
+
+// AuthConfig holds authentication and authorization configuration
+type AuthConfig struct {
+	// MaskContactFields redacts email and phone of other users for non-admin callers
+	MaskContactFields bool                 `mapstructure:"mask_contact_fields"`
+	PasswordPolicy    PasswordPolicyConfig `mapstructure:"password_policy"`
+	// BcryptCost is the work factor for new password hashes
+	BcryptCost int `mapstructure:"bcrypt_cost"`
+	// MinVerificationTime pads credential checks to at least this duration (0 disables)
+	MinVerificationTime time.Duration `mapstructure:"min_verification_time"`
+	// PasswordResetTTL is how long a password reset token stays valid
+	PasswordResetTTL time.Duration `mapstructure:"password_reset_ttl"`
+	// RequireEmailVerification keeps new users inactive until they verify their email
+	RequireEmailVerification bool `mapstructure:"require_email_verification"`
+	// EmailVerificationTTL is how long an email verification token stays valid
+	EmailVerificationTTL time.Duration `mapstructure:"email_verification_ttl"`
+	// VerificationResendInterval is the minimum time between verification emails to one user
+	VerificationResendInterval time.Duration       `mapstructure:"verification_resend_interval"`
+	Lockout                    LockoutConfig       `mapstructure:"lockout"`
+	JWT                        JWTConfig           `mapstructure:"jwt"`
+	Impersonation              ImpersonationConfig `mapstructure:"impersonation"`
+	Roles                      RolesConfig         `mapstructure:"roles"`
 }
 
-// Load loads configuration from environment variables and config files
-func Load() (*Config, error) {
-	viper.SetConfigName("config")
-	viper.SetConfigType("yaml")
-	viper.AddConfigPath("./configs")
-	viper.AddConfigPath(".")
+// JWTConfig holds the settings for verifying bearer tokens. Without a secret no caller
+// is authenticated, so methods restricted by role are refused.
+type JWTConfig struct {
+	// Secret is the HMAC key tokens are signed with; at least 32 bytes
+	Secret string `mapstructure:"secret"`
+	// Issuer is the required iss claim
+	Issuer string `mapstructure:"issuer"`
+}
+
+// Enabled reports whether bearer tokens are verified
+func (c JWTConfig) Enabled() bool {
+	return c.Secret != ""
+}
+
+// ImpersonationConfig controls admins acting on behalf of users via x-impersonate-user metadata
+type ImpersonationConfig struct {
+	Enabled bool `mapstructure:"enabled"`
+	// AllowedMethods are the full gRPC method names callable while impersonating; with
+	// role enforcement on they must not be admin methods, as the user role is assumed
+	AllowedMethods []string `mapstructure:"allowed_methods"`
+}
+
+// RolesConfig restricts methods to callers with a role, as set from their bearer token
+type RolesConfig struct {
+	Enforce bool `mapstructure:"enforce"`
+	// AdminMethods are the full gRPC method names only admins may call
+	AdminMethods []string `mapstructure:"admin_methods"`
+}
+
+// LockoutConfig controls locking accounts after repeated failed logins
+type LockoutConfig struct {
+	// Threshold is the number of failures within Window that locks the account (0 disables)
+	Threshold int           `mapstructure:"threshold"`
+	Window    time.Duration `mapstructure:"window"`
+	Duration  time.Duration `mapstructure:"duration"`
+}
+
+// PasswordPolicyConfig holds the rules new passwords must satisfy
+type PasswordPolicyConfig struct {
+	MinLength     int  `mapstructure:"min_length"`
+	RequireUpper  bool `mapstructure:"require_upper"`
+	RequireDigit  bool `mapstructure:"require_digit"`
+	RequireSymbol bool `mapstructure:"require_symbol"`
+	// RejectPersonalInfo rejects passwords containing the user's email local part or names
+	RejectPersonalInfo bool `mapstructure:"reject_personal_info"`
+}
+
+// ServiceConfig holds user service behavior configuration
+type ServiceConfig struct {
+	// BatchGetPartialResults returns fetched users with per-ID errors instead of failing the whole batch
+	BatchGetPartialResults bool `mapstructure:"batch_get_partial_results"`
+}
+
+// MetricsConfig holds Prometheus metrics configuration
+type MetricsConfig struct {
+	// PoolScrapeInterval is how often database pool statistics are sampled
+	PoolScrapeInterval time.Duration `mapstructure:"pool_scrape_interval"`
+	// Buckets are the upper bounds in seconds of the gRPC latency histogram buckets;
+	// empty uses the Prometheus defaults
+	Buckets []float64 `mapstructure:"buckets"`
+}
+
+// RateLimitConfig holds per-client rate limiting configuration
+type RateLimitConfig struct {
+	Enabled bool `mapstructure:"enabled"`
+	// Rate is the sustained number of requests per second allowed per client
+	Rate float64 `mapstructure:"rate"`
+	// Burst is the number of requests a client may make at once
+	Burst int `mapstructure:"burst"`
+	// Store keeps the token buckets: "memory" per instance, or "redis" shared across instances
+	Store string `mapstructure:"store"`
+}
+
+// RedisConfig holds Redis configuration; caching is enabled only when Addr is set
+type RedisConfig struct {
+	Addr     string `mapstructure:"addr"`
+	Password string `mapstructure:"password"`
+	DB       int    `mapstructure:"db"`
+	// CacheTTL is how long cached users are kept
+	CacheTTL time.Duration `mapstructure:"cache_ttl"`
+}
+
+// Event encodings accepted by KafkaConfig.Encoding
+const (
+	EncodingJSON     = "json"
+	EncodingProtobuf = "protobuf"
+)
+
+// KafkaConfig holds Kafka configuration; user events are published only when Brokers is set
+type KafkaConfig struct {
+	Brokers []string `mapstructure:"brokers"`
+	Topic   string   `mapstructure:"topic"`
+	// Encoding serializes events as json, or protobuf using the user.v1.UserEvent message
+	Encoding string `mapstructure:"encoding"`
+}
+
+// Enabled reports whether any broker is configured
+func (k KafkaConfig) Enabled() bool {
+	return len(k.Brokers) > 0
+}
+
+// OutboxConfig holds transactional outbox configuration. When enabled, user events are
+// stored in the same transaction as each change and a background relay publishes them,
+// so none are lost if the process stops after a commit; it requires a SQL database.
+type OutboxConfig struct {
+	Enabled bool `mapstructure:"enabled"`
+	// RelayInterval is how often the relay checks for unpublished events
+	RelayInterval time.Duration `mapstructure:"relay_interval"`
+	// BatchSize is the number of events the relay reads at a time
+	BatchSize int `mapstructure:"batch_size"`
+	// Retention is how long published events are kept before the relay deletes them; zero keeps them
+	Retention time.Duration `mapstructure:"retention"`
+}
+
+// SMTPConfig holds the mail server that delivers password reset and email verification
+// tokens. Both features are disabled when Host is unset.
+type SMTPConfig struct {
+	Host     string `mapstructure:"host"`
+	Port     int    `mapstructure:"port"`
+	Username string `mapstructure:"username"`
+	Password string `mapstructure:"password"`
+	From     string `mapstructure:"from"`
+	// ResetURL and VerifyURL are the links sent to users; {token} is replaced with the token
+	ResetURL  string `mapstructure:"reset_url"`
+	VerifyURL string `mapstructure:"verify_url"`
+}
+
+// Enabled reports whether a mail server is configured
+func (c SMTPConfig) Enabled() bool {
+	return c.Host != ""
+}
+
+// Load loads configuration from environment variables and config files. The base file is
+// the given path, else APP_CONFIG_FILE, else config.yaml in ./configs or the working
+// directory. When APP_ENV is set, config.<APP_ENV>.yaml next to it is merged on top.
+func Load(file string) (*Config, error) {
+	if file == "" {
+		file = os.Getenv("APP_CONFIG_FILE")
+	}
+	if file != "" {
+		viper.SetConfigFile(file)
+	} else {
+		viper.SetConfigName("config")
+		viper.SetConfigType("yaml")
+		viper.AddConfigPath("./configs")
+		viper.AddConfigPath(".")
+	}
 
 	// Set defaults
 	setDefaults()
 
-	// Read config file (optional)
+	// Read config file (optional unless given explicitly)
 	if err := viper.ReadInConfig(); err != nil {
 		if _, ok := err.(viper.ConfigFileNotFoundError); !ok {
 			return nil, fmt.Errorf("failed to read config file: %w", err)
 		}
 	}
 
+	files := []string{viper.ConfigFileUsed()}
+
+	// Overlay the environment profile, if any
+	if env := os.Getenv("APP_ENV"); env != "" {
+		profile, err := mergeProfile(env)
+		if err != nil {
+			return nil, err
+		}
+		files = append(files, profile)
+	}
+	setLoadedFiles(files)
+
 	// Read from environment variables
 	viper.SetEnvPrefix("APP")
 	viper.SetEnvKeyReplacer(strings.NewReplacer(".", "_"))
@@ -64,31 +399,230 @@ func Load() (*Config, error) {
 		return nil, fmt.Errorf("failed to unmarshal config: %w", err)
 	}
 
+	if err := config.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid config: %w", err)
+	}
+
 	return &config, nil
 }
 
+// mergeProfile merges the profile overlay for env over the loaded configuration and returns
+// its path. A missing overlay is not an error, so profiles only need to exist when they
+// override something.
+func mergeProfile(env string) (string, error) {
+	if strings.ContainsAny(env, `/\`) || strings.Contains(env, "..") {
+		return "", fmt.Errorf("invalid APP_ENV %q", env)
+	}
+
+	base := viper.ConfigFileUsed()
+	if base == "" {
+		base = filepath.Join("configs", "config.yaml")
+	}
+	ext := filepath.Ext(base)
+	profile := strings.TrimSuffix(base, ext) + "." + env + ext
+
+	if _, err := os.Stat(profile); errors.Is(err, os.ErrNotExist) {
+		return profile, nil
+	}
+	viper.SetConfigFile(profile)
+	if err := viper.MergeInConfig(); err != nil {
+		return "", fmt.Errorf("failed to merge %s profile: %w", env, err)
+	}
+	return profile, nil
+}
+
 // setDefaults sets default configuration values
 func setDefaults() {
 	// Server defaults
 	viper.SetDefault("server.grpc_port", "50051")
 	viper.SetDefault("server.http_port", "8080")
 	viper.SetDefault("server.host", "0.0.0.0")
+	viper.SetDefault("server.max_concurrent_requests", 0)
+	viper.SetDefault("server.dedup_window", "0s")
+	viper.SetDefault("server.reuse_port", false)
+	viper.SetDefault("server.health_check_interval", "10s")
+	viper.SetDefault("server.default_timeout", "30s")
+	viper.SetDefault("server.method_timeouts", map[string]string{"StreamUsers": "10m"})
+	viper.SetDefault("server.shutdown_timeout", "30s")
+	viper.SetDefault("server.disabled_interceptors", []string{})
+	viper.SetDefault("server.tls.cert_file", "")
+	viper.SetDefault("server.tls.key_file", "")
+	viper.SetDefault("server.tls.client_ca_file", "")
+	viper.SetDefault("server.http_tls.cert_file", "")
+	viper.SetDefault("server.http_tls.key_file", "")
+	viper.SetDefault("server.http_tls.client_ca_file", "")
+	viper.SetDefault("server.max_recv_msg_size", 0)
+	viper.SetDefault("server.max_send_msg_size", 0)
+	viper.SetDefault("server.keepalive.max_connection_idle", "5m")
+	viper.SetDefault("server.keepalive.max_connection_age", "0s")
+	viper.SetDefault("server.keepalive.max_connection_age_grace", "0s")
+	viper.SetDefault("server.keepalive.time", "2h")
+	viper.SetDefault("server.keepalive.timeout", "20s")
+	viper.SetDefault("server.keepalive.min_time", "5m")
+	viper.SetDefault("server.keepalive.permit_without_stream", false)
+	viper.SetDefault("server.cors.allowed_origins", []string{})
+	viper.SetDefault("server.cors.allowed_methods", []string{"GET", "POST", "PATCH", "DELETE"})
+	viper.SetDefault("server.cors.allowed_headers", []string{"Authorization", "Content-Type", "X-Request-ID"})
+	viper.SetDefault("server.cors.exposed_headers", []string{"X-Request-ID"})
+	viper.SetDefault("server.cors.allow_credentials", false)
+	viper.SetDefault("server.cors.max_age", "10m")
 
 	// Database defaults
+	viper.SetDefault("database.driver", DriverPostgres)
+	viper.SetDefault("database.sqlite_path", "user-service.db")
 	viper.SetDefault("database.host", "localhost")
 	viper.SetDefault("database.port", "5432")
 	viper.SetDefault("database.user", "postgres")
 	viper.SetDefault("database.password", "postgres")
 	viper.SetDefault("database.database", "users")
 	viper.SetDefault("database.ssl_mode", "disable")
+	viper.SetDefault("database.application_name", "")
+	viper.SetDefault("database.max_open_conns", 25)
+	viper.SetDefault("database.max_idle_conns", 10)
+	viper.SetDefault("database.conn_max_lifetime", "30m")
+	viper.SetDefault("database.replicas", []string{})
+	viper.SetDefault("database.read_your_writes_window", "5s")
+	viper.SetDefault("database.auto_migrate", true)
+	viper.SetDefault("database.retry.max_attempts", 3)
+	viper.SetDefault("database.retry.base_delay", "50ms")
+	viper.SetDefault("database.retry.max_delay", "1s")
 
 	// Logger defaults
 	viper.SetDefault("logger.level", "info")
 	viper.SetDefault("logger.format", "json")
+	viper.SetDefault("logger.log_payloads", false)
+	viper.SetDefault("logger.max_payload_bytes", 4096)
+
+	// Email defaults
+	viper.SetDefault("email.canonicalize_aliases", false)
+	viper.SetDefault("email.providers", []map[string]interface{}{
+		{
+			"domains":          []string{"gmail.com", "googlemail.com"},
+			"strip_dots":       true,
+			"strip_plus":       true,
+			"canonical_domain": "gmail.com",
+		},
+	})
+
+	// Tracing defaults
+	viper.SetDefault("tracing.enabled", false)
+	viper.SetDefault("tracing.endpoint", "localhost:4317")
+	viper.SetDefault("tracing.insecure", true)
+	viper.SetDefault("tracing.sample_rate", 1.0)
+	viper.SetDefault("tracing.service_name", "user-service")
+
+	// Auth defaults
+	viper.SetDefault("auth.mask_contact_fields", false)
+	viper.SetDefault("auth.password_policy.min_length", 8)
+	viper.SetDefault("auth.password_policy.require_upper", false)
+	viper.SetDefault("auth.password_policy.require_digit", false)
+	viper.SetDefault("auth.password_policy.require_symbol", false)
+	viper.SetDefault("auth.password_policy.reject_personal_info", false)
+	viper.SetDefault("auth.bcrypt_cost", bcrypt.DefaultCost)
+	viper.SetDefault("auth.min_verification_time", "0s")
+	viper.SetDefault("auth.password_reset_ttl", "1h")
+	viper.SetDefault("auth.require_email_verification", false)
+	viper.SetDefault("auth.email_verification_ttl", "24h")
+	viper.SetDefault("auth.verification_resend_interval", "1m")
+	viper.SetDefault("auth.lockout.threshold", 0)
+	viper.SetDefault("auth.lockout.window", "15m")
+	viper.SetDefault("auth.lockout.duration", "15m")
+	viper.SetDefault("auth.jwt.secret", "")
+	viper.SetDefault("auth.jwt.issuer", "user-service")
+	viper.SetDefault("auth.impersonation.enabled", false)
+	viper.SetDefault("auth.impersonation.allowed_methods", []string{
+		"/user.v1.UserService/GetUser",
+		"/user.v1.UserService/GetMe",
+	})
+	viper.SetDefault("auth.roles.enforce", true)
+	viper.SetDefault("auth.roles.admin_methods", []string{
+		"/user.v1.UserService/CreateUsersBatch",
+		"/user.v1.UserService/UpdateUser",
+		"/user.v1.UserService/DeleteUser",
+		"/user.v1.UserService/RestoreUser",
+		"/user.v1.UserService/ListUsers",
+		"/user.v1.UserService/CountUsers",
+		"/user.v1.UserService/StreamUsers",
+		"/user.v1.UserService/SetUserStatus",
+		"/user.v1.UserService/DeactivateUser",
+		"/user.v1.UserService/ReactivateUser",
+		"/user.v1.UserService/AnonymizeUser",
+		"/user.v1.UserService/UnlockUser",
+	})
+
+	// Service defaults
+	viper.SetDefault("service.batch_get_partial_results", false)
+
+	// Metrics defaults
+	viper.SetDefault("metrics.pool_scrape_interval", "15s")
+	viper.SetDefault("metrics.buckets", []float64{})
+
+	// Rate limit defaults
+	viper.SetDefault("rate_limit.enabled", false)
+	viper.SetDefault("rate_limit.rate", 10)
+	viper.SetDefault("rate_limit.burst", 20)
+	viper.SetDefault("rate_limit.store", "memory")
+
+	// Redis defaults
+	viper.SetDefault("redis.addr", "")
+	viper.SetDefault("redis.password", "")
+	viper.SetDefault("redis.db", 0)
+	viper.SetDefault("redis.cache_ttl", "5m")
+
+	// Kafka defaults
+	viper.SetDefault("kafka.brokers", []string{})
+	viper.SetDefault("kafka.topic", "user-events")
+	viper.SetDefault("kafka.encoding", EncodingJSON)
+
+	// Outbox defaults
+	viper.SetDefault("outbox.enabled", false)
+	viper.SetDefault("outbox.relay_interval", "1s")
+	viper.SetDefault("outbox.batch_size", 100)
+	viper.SetDefault("outbox.retention", "168h")
+
+	// SMTP defaults
+	viper.SetDefault("smtp.host", "")
+	viper.SetDefault("smtp.port", 587)
+	viper.SetDefault("smtp.username", "")
+	viper.SetDefault("smtp.password", "")
+	viper.SetDefault("smtp.from", "")
+	viper.SetDefault("smtp.reset_url", "")
+	viper.SetDefault("smtp.verify_url", "")
 }
 
 // GetDSN returns the database connection string
 func (d *DatabaseConfig) GetDSN() string {
-	return fmt.Sprintf("host=%s port=%s user=%s password=%s dbname=%s sslmode=%s",
-		d.Host, d.Port, d.User, d.Password, d.Database, d.SSLMode)
+	return d.dsn(d.Host, d.Port)
+}
+
+// GetReplicaDSNs returns the connection strings of the read replicas
+func (d *DatabaseConfig) GetReplicaDSNs() []string {
+	dsns := make([]string, 0, len(d.Replicas))
+	for _, replica := range d.Replicas {
+		host, port := replica, d.Port
+		if h, p, err := net.SplitHostPort(replica); err == nil {
+			host, port = h, p
+		}
+		dsns = append(dsns, d.dsn(host, port))
+	}
+	return dsns
+}
+
+// dsn returns the connection string for a server of the database
+func (d *DatabaseConfig) dsn(host, port string) string {
+	dsn := fmt.Sprintf("host=%s port=%s user=%s password=%s dbname=%s sslmode=%s",
+		dsnValue(host), dsnValue(port), dsnValue(d.User), dsnValue(d.Password), dsnValue(d.Database), dsnValue(d.SSLMode))
+	if d.ApplicationName != "" {
+		dsn += " application_name=" + dsnValue(d.ApplicationName)
+	}
+	return dsn
+}
+
+// dsnEscaper escapes the characters that are special inside a quoted DSN value
+var dsnEscaper = strings.NewReplacer(`\`, `\\`, `'`, `\'`)
+
+// dsnValue quotes v for a key=value connection string, so values with spaces, quotes or
+// backslashes, such as passwords, are passed through unchanged
+func dsnValue(v string) string {
+	return "'" + dsnEscaper.Replace(v) + "'"
 }
@@ -0,0 +1,156 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+func TestGetDSN(t *testing.T) {
+	tests := []struct {
+		name            string
+		password        string
+		applicationName string
+	}{
+		{name: "plain", password: "secret", applicationName: "user-service/1.0.0"},
+		{name: "spaces", password: "correct horse", applicationName: "user service"},
+		{name: "quotes", password: `it's`, applicationName: `o'clock`},
+		{name: "backslashes", password: `back\slash\`, applicationName: `C:\service\`},
+		{name: "backslash before quote", password: `\'`, applicationName: `\'x`},
+		{name: "no application name", password: "secret"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			d := &DatabaseConfig{
+				Host:            "db.internal",
+				Port:            "5432",
+				User:            "app",
+				Password:        tt.password,
+				Database:        "users",
+				SSLMode:         "disable",
+				ApplicationName: tt.applicationName,
+			}
+
+			parsed, err := pgconn.ParseConfig(d.GetDSN())
+			if err != nil {
+				t.Fatalf("ParseConfig(%q): %v", d.GetDSN(), err)
+			}
+			if parsed.Host != "db.internal" || parsed.Port != 5432 || parsed.User != "app" || parsed.Database != "users" {
+				t.Errorf("parsed %s@%s:%d/%s, want app@db.internal:5432/users", parsed.User, parsed.Host, parsed.Port, parsed.Database)
+			}
+			if parsed.Password != tt.password {
+				t.Errorf("password = %q, want %q", parsed.Password, tt.password)
+			}
+			if got := parsed.RuntimeParams["application_name"]; got != tt.applicationName {
+				t.Errorf("application_name = %q, want %q", got, tt.applicationName)
+			}
+		})
+	}
+}
+
+func TestGetReplicaDSNs(t *testing.T) {
+	d := &DatabaseConfig{Port: "5432", User: "app", Database: "users", SSLMode: "disable", Replicas: []string{"replica-1", "replica-2:6432"}}
+
+	want := []struct {
+		host string
+		port uint16
+	}{{"replica-1", 5432}, {"replica-2", 6432}}
+	dsns := d.GetReplicaDSNs()
+	if len(dsns) != len(want) {
+		t.Fatalf("got %d replica DSNs, want %d", len(dsns), len(want))
+	}
+	for i, dsn := range dsns {
+		parsed, err := pgconn.ParseConfig(dsn)
+		if err != nil {
+			t.Fatalf("ParseConfig(%q): %v", dsn, err)
+		}
+		if parsed.Host != want[i].host || parsed.Port != want[i].port {
+			t.Errorf("replica %d = %s:%d, want %s:%d", i, parsed.Host, parsed.Port, want[i].host, want[i].port)
+		}
+	}
+}
+
+func TestLoadBcryptCost(t *testing.T) {
+	tests := []struct {
+		cost    int
+		wantErr bool
+	}{
+		{cost: 3, wantErr: true},
+		{cost: 4},
+		{cost: 12},
+		{cost: 31},
+		{cost: 32, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(strconv.Itoa(tt.cost), func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "config.yaml")
+			content := "auth:\n  bcrypt_cost: " + strconv.Itoa(tt.cost) + "\n"
+			if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+				t.Fatalf("write config: %v", err)
+			}
+
+			cfg, err := Load(path)
+			if tt.wantErr {
+				if err == nil || !strings.Contains(err.Error(), "auth.bcrypt_cost") {
+					t.Errorf("Load = %v, want an auth.bcrypt_cost error", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Load: %v", err)
+			}
+			if cfg.Auth.BcryptCost != tt.cost {
+				t.Errorf("BcryptCost = %d, want %d", cfg.Auth.BcryptCost, tt.cost)
+			}
+		})
+	}
+}
+
+func TestLoadImpersonationMethods(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		wantErr bool
+	}{
+		{name: "defaults", content: "auth:\n  impersonation:\n    enabled: true\n"},
+		{
+			name:    "admin method",
+			content: "auth:\n  impersonation:\n    enabled: true\n    allowed_methods: [\"/user.v1.UserService/UpdateUser\"]\n",
+			wantErr: true,
+		},
+		{
+			name:    "admin method without role enforcement",
+			content: "auth:\n  impersonation:\n    enabled: true\n    allowed_methods: [\"/user.v1.UserService/UpdateUser\"]\n  roles:\n    enforce: false\n",
+		},
+		{
+			name:    "admin method while disabled",
+			content: "auth:\n  impersonation:\n    allowed_methods: [\"/user.v1.UserService/UpdateUser\"]\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "config.yaml")
+			if err := os.WriteFile(path, []byte(tt.content), 0o600); err != nil {
+				t.Fatalf("write config: %v", err)
+			}
+
+			_, err := Load(path)
+			if tt.wantErr {
+				if err == nil || !strings.Contains(err.Error(), "auth.impersonation.allowed_methods") {
+					t.Errorf("Load = %v, want an auth.impersonation.allowed_methods error", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Errorf("Load: %v", err)
+			}
+		})
+	}
+}
@@ -0,0 +1,55 @@
+package config
+
+import (
+	"reflect"
+	"strings"
+	"sync"
+)
+
+// reloadMu serializes reloads, which share viper's global state
+var reloadMu sync.Mutex
+
+// Reload re-reads the configuration from the files and environment used by the last Load.
+// An invalid configuration is returned as an error, so the caller keeps the current one.
+func Reload() (*Config, error) {
+	reloadMu.Lock()
+	defer reloadMu.Unlock()
+
+	var base string
+	if files := getLoadedFiles(); len(files) > 0 {
+		base = files[0]
+	}
+	return Load(base)
+}
+
+// RestartRequired returns the sections that differ between old and new in settings that
+// can't be applied live. Only the log level, rate limit rate and burst, and request
+// timeouts are reloadable.
+func RestartRequired(old, new *Config) []string {
+	a, b := withoutReloadable(*old), withoutReloadable(*new)
+
+	var sections []string
+	va, vb := reflect.ValueOf(a), reflect.ValueOf(b)
+	for i := 0; i < va.NumField(); i++ {
+		if reflect.DeepEqual(va.Field(i).Interface(), vb.Field(i).Interface()) {
+			continue
+		}
+		field := va.Type().Field(i)
+		name := field.Tag.Get("mapstructure")
+		if name == "" {
+			name = strings.ToLower(field.Name)
+		}
+		sections = append(sections, name)
+	}
+	return sections
+}
+
+// withoutReloadable clears the settings that can be applied live
+func withoutReloadable(c Config) Config {
+	c.Logger.Level = ""
+	c.RateLimit.Rate = 0
+	c.RateLimit.Burst = 0
+	c.Server.DefaultTimeout = 0
+	c.Server.MethodTimeouts = nil
+	return c
+}
@@ -0,0 +1,269 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+
+	"golang.org/x/crypto/bcrypt"
+)
+
+var (
+	validLogLevels  = map[string]bool{"debug": true, "info": true, "warn": true, "error": true, "dpanic": true, "panic": true, "fatal": true}
+	validLogFormats = map[string]bool{"json": true, "console": true}
+)
+
+// Validate checks the configuration and returns an error listing every problem found
+func (c *Config) Validate() error {
+	var errs []error
+
+	errs = append(errs, validatePort("server.grpc_port", c.Server.GRPCPort))
+	errs = append(errs, validatePort("server.http_port", c.Server.HTTPPort))
+	if c.Server.MaxConcurrentRequests < 0 {
+		errs = append(errs, errors.New("server.max_concurrent_requests must not be negative"))
+	}
+	if c.Server.DedupWindow < 0 {
+		errs = append(errs, errors.New("server.dedup_window must not be negative"))
+	}
+	if c.Server.ShutdownTimeout <= 0 {
+		errs = append(errs, errors.New("server.shutdown_timeout must be positive"))
+	}
+	if c.Server.DefaultTimeout < 0 {
+		errs = append(errs, errors.New("server.default_timeout must not be negative"))
+	}
+	for method, timeout := range c.Server.MethodTimeouts {
+		if timeout < 0 {
+			errs = append(errs, fmt.Errorf("server.method_timeouts[%s] must not be negative", method))
+		}
+	}
+	errs = append(errs, c.Server.TLS.validate("server.tls"))
+	errs = append(errs, c.Server.HTTPTLS.validate("server.http_tls"))
+	if c.Server.MaxRecvMsgSize < 0 || c.Server.MaxSendMsgSize < 0 {
+		errs = append(errs, errors.New("server.max_recv_msg_size and server.max_send_msg_size must not be negative"))
+	}
+	if k := c.Server.Keepalive; k.MaxConnectionIdle < 0 || k.MaxConnectionAge < 0 || k.MaxConnectionAgeGrace < 0 ||
+		k.Time < 0 || k.Timeout < 0 || k.MinTime < 0 {
+		errs = append(errs, errors.New("server.keepalive durations must not be negative"))
+	}
+	errs = append(errs, c.Server.CORS.validate())
+
+	errs = append(errs, c.Database.validate())
+
+	if !validLogLevels[c.Logger.Level] {
+		errs = append(errs, fmt.Errorf("logger.level %q is not one of debug, info, warn, error, dpanic, panic, fatal", c.Logger.Level))
+	}
+	if !validLogFormats[c.Logger.Format] {
+		errs = append(errs, fmt.Errorf("logger.format %q is not one of json, console", c.Logger.Format))
+	}
+	if c.Logger.MaxPayloadBytes < 0 {
+		errs = append(errs, errors.New("logger.max_payload_bytes must not be negative"))
+	}
+
+	if c.Tracing.Enabled {
+		if c.Tracing.Endpoint == "" {
+			errs = append(errs, errors.New("tracing.endpoint is required when tracing is enabled"))
+		}
+		if c.Tracing.SampleRate < 0 || c.Tracing.SampleRate > 1 {
+			errs = append(errs, fmt.Errorf("tracing.sample_rate %v must be between 0 and 1", c.Tracing.SampleRate))
+		}
+	}
+
+	if c.Server.HealthCheckInterval <= 0 {
+		errs = append(errs, errors.New("server.health_check_interval must be positive"))
+	}
+
+	if c.Database.Retry.MaxDelay < 0 || c.Database.Retry.BaseDelay < 0 {
+		errs = append(errs, errors.New("database.retry delays must not be negative"))
+	}
+
+	if c.Metrics.PoolScrapeInterval <= 0 {
+		errs = append(errs, errors.New("metrics.pool_scrape_interval must be positive"))
+	}
+	for i, bound := range c.Metrics.Buckets {
+		if bound <= 0 || (i > 0 && bound <= c.Metrics.Buckets[i-1]) {
+			errs = append(errs, errors.New("metrics.buckets must be positive and strictly increasing"))
+			break
+		}
+	}
+
+	if c.RateLimit.Enabled && (c.RateLimit.Rate <= 0 || c.RateLimit.Burst < 1) {
+		errs = append(errs, errors.New("rate_limit.rate must be positive and rate_limit.burst at least 1 when rate limiting is enabled"))
+	}
+	switch c.RateLimit.Store {
+	case "memory":
+	case "redis":
+		if c.RateLimit.Enabled && c.Redis.Addr == "" {
+			errs = append(errs, errors.New("redis.addr is required when rate_limit.store is redis"))
+		}
+	default:
+		errs = append(errs, fmt.Errorf("rate_limit.store %q is not one of memory, redis", c.RateLimit.Store))
+	}
+
+	if c.Redis.Addr != "" && c.Redis.CacheTTL <= 0 {
+		errs = append(errs, errors.New("redis.cache_ttl must be positive when redis is enabled"))
+	}
+
+	if c.Kafka.Enabled() && c.Kafka.Topic == "" {
+		errs = append(errs, errors.New("kafka.topic is required when kafka is enabled"))
+	}
+	switch c.Kafka.Encoding {
+	case EncodingJSON, EncodingProtobuf:
+	default:
+		errs = append(errs, fmt.Errorf("kafka.encoding %q is not one of json, protobuf", c.Kafka.Encoding))
+	}
+
+	if c.Outbox.Enabled {
+		if c.Outbox.RelayInterval <= 0 || c.Outbox.BatchSize < 1 {
+			errs = append(errs, errors.New("outbox.relay_interval must be positive and outbox.batch_size at least 1 when the outbox is enabled"))
+		}
+		if c.Outbox.Retention < 0 {
+			errs = append(errs, errors.New("outbox.retention must not be negative"))
+		}
+		if c.Database.Driver == DriverMemory {
+			errs = append(errs, errors.New("outbox.enabled requires a postgres or sqlite database"))
+		}
+	}
+
+	if c.SMTP.Enabled() {
+		if c.SMTP.Port < 1 || c.SMTP.Port > 65535 {
+			errs = append(errs, errors.New("smtp.port must be between 1 and 65535"))
+		}
+		if c.SMTP.From == "" {
+			errs = append(errs, errors.New("smtp.from is required when smtp.host is set"))
+		}
+		if !strings.Contains(c.SMTP.ResetURL, "{token}") || !strings.Contains(c.SMTP.VerifyURL, "{token}") {
+			errs = append(errs, errors.New("smtp.reset_url and smtp.verify_url must contain {token}"))
+		}
+	}
+	if c.Auth.RequireEmailVerification && !c.SMTP.Enabled() {
+		errs = append(errs, errors.New("auth.require_email_verification requires smtp.host to deliver verification emails"))
+	}
+
+	if c.Auth.PasswordPolicy.MinLength < 1 {
+		errs = append(errs, errors.New("auth.password_policy.min_length must be at least 1"))
+	}
+	if c.Auth.BcryptCost < bcrypt.MinCost || c.Auth.BcryptCost > bcrypt.MaxCost {
+		errs = append(errs, fmt.Errorf("auth.bcrypt_cost must be between %d and %d", bcrypt.MinCost, bcrypt.MaxCost))
+	}
+	if c.Auth.PasswordResetTTL <= 0 {
+		errs = append(errs, errors.New("auth.password_reset_ttl must be positive"))
+	}
+	if c.Auth.EmailVerificationTTL <= 0 {
+		errs = append(errs, errors.New("auth.email_verification_ttl must be positive"))
+	}
+	if c.Auth.VerificationResendInterval < 0 {
+		errs = append(errs, errors.New("auth.verification_resend_interval must not be negative"))
+	}
+	if c.Auth.Lockout.Threshold < 0 {
+		errs = append(errs, errors.New("auth.lockout.threshold must not be negative"))
+	}
+	if c.Auth.Lockout.Threshold > 0 && (c.Auth.Lockout.Window <= 0 || c.Auth.Lockout.Duration <= 0) {
+		errs = append(errs, errors.New("auth.lockout.window and auth.lockout.duration must be positive when lockout is enabled"))
+	}
+	if c.Auth.Impersonation.Enabled && c.Auth.Roles.Enforce {
+		// An impersonated principal has the user role, so admin methods would always be refused
+		for _, method := range c.Auth.Impersonation.AllowedMethods {
+			if slices.Contains(c.Auth.Roles.AdminMethods, method) {
+				errs = append(errs, fmt.Errorf("auth.impersonation.allowed_methods must not include admin method %s", method))
+			}
+		}
+	}
+	if c.Auth.JWT.Enabled() {
+		if len(c.Auth.JWT.Secret) < 32 {
+			errs = append(errs, errors.New("auth.jwt.secret must be at least 32 bytes"))
+		}
+		if c.Auth.JWT.Issuer == "" {
+			errs = append(errs, errors.New("auth.jwt.issuer is required when auth.jwt.secret is set"))
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+// validate checks that the certificate and key are set together, with prefix naming the section
+func (t TLSConfig) validate(prefix string) error {
+	if (t.CertFile == "") != (t.KeyFile == "") {
+		return fmt.Errorf("%[1]s.cert_file and %[1]s.key_file must be set together", prefix)
+	}
+	if t.ClientCAFile != "" && t.CertFile == "" {
+		return fmt.Errorf("%[1]s.client_ca_file requires %[1]s.cert_file", prefix)
+	}
+	return nil
+}
+
+// validate checks the CORS policy; browsers reject a wildcard origin on credentialed requests
+func (c CORSConfig) validate() error {
+	for _, origin := range c.AllowedOrigins {
+		if origin == "*" && c.AllowCredentials {
+			return errors.New("server.cors.allowed_origins must list explicit origins when allow_credentials is set")
+		}
+	}
+	if c.Enabled() && len(c.AllowedMethods) == 0 {
+		return errors.New("server.cors.allowed_methods must not be empty when CORS is enabled")
+	}
+	if c.MaxAge < 0 {
+		return errors.New("server.cors.max_age must not be negative")
+	}
+	return nil
+}
+
+// validate checks the database configuration
+func (d *DatabaseConfig) validate() error {
+	switch d.Driver {
+	case DriverPostgres:
+	case DriverSQLite:
+		if d.SQLitePath == "" {
+			return errors.New("database.sqlite_path is required for the sqlite driver")
+		}
+		return nil
+	case DriverMemory:
+		return nil
+	default:
+		return fmt.Errorf("database.driver %q is not one of %s, %s, %s", d.Driver, DriverPostgres, DriverSQLite, DriverMemory)
+	}
+
+	var errs []error
+
+	required := []struct{ key, value string }{
+		{"database.host", d.Host},
+		{"database.user", d.User},
+		{"database.password", d.Password},
+		{"database.database", d.Database},
+	}
+	for _, field := range required {
+		if field.value == "" {
+			errs = append(errs, fmt.Errorf("%s is required", field.key))
+		}
+	}
+
+	errs = append(errs, validatePort("database.port", d.Port))
+	for _, replica := range d.Replicas {
+		if replica == "" {
+			errs = append(errs, errors.New("database.replicas must not contain empty hosts"))
+			break
+		}
+	}
+
+	if d.MaxOpenConns < 0 || d.MaxIdleConns < 0 || d.ConnMaxLifetime < 0 {
+		errs = append(errs, errors.New("database connection pool settings must not be negative"))
+	}
+	if d.MaxOpenConns == 1 {
+		errs = append(errs, errors.New("database.max_open_conns must not be 1; migrations hold a lock on one connection and run on another"))
+	}
+
+	return errors.Join(errs...)
+}
+
+// validatePort checks that value is a numeric TCP port
+func validatePort(key, value string) error {
+	port, err := strconv.Atoi(value)
+	if err != nil {
+		return fmt.Errorf("%s %q is not numeric", key, value)
+	}
+	if port < 1 || port > 65535 {
+		return fmt.Errorf("%s %d is out of range 1-65535", key, port)
+	}
+	return nil
+}
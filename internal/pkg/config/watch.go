@@ -0,0 +1,120 @@
+package config
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"sync"
+
+	"github.com/fsnotify/fsnotify"
+)
+
+var (
+	loadedMu sync.Mutex
+	// loadedFiles are the base config file and profile overlay read by the last Load;
+	// either may be empty or not exist yet
+	loadedFiles []string
+)
+
+func setLoadedFiles(files []string) {
+	loadedMu.Lock()
+	defer loadedMu.Unlock()
+	loadedFiles = files
+}
+
+func getLoadedFiles() []string {
+	loadedMu.Lock()
+	defer loadedMu.Unlock()
+	return append([]string(nil), loadedFiles...)
+}
+
+// Watch reloads the configuration whenever the base config file or profile overlay changes
+// and passes the new configuration, or the reload error, to onChange. Callers decide which
+// settings to apply live; most, such as connection settings, only take effect on restart.
+// It blocks until ctx is cancelled.
+func Watch(ctx context.Context, onChange func(*Config, error)) error {
+	w, err := newFileWatcher(getLoadedFiles())
+	if err != nil {
+		return err
+	}
+	defer w.close()
+
+	w.run(ctx, onChange)
+	return nil
+}
+
+// fileWatcher watches the directories of the loaded config files. Like viper's WatchConfig
+// it tracks where each file resolves to, so a Kubernetes ConfigMap update, which swaps the
+// ..data symlink rather than touching the file, is noticed.
+type fileWatcher struct {
+	watcher *fsnotify.Watcher
+	// resolved maps each watched file to the path it resolved to at the last reload
+	resolved map[string]string
+}
+
+func newFileWatcher(files []string) (*fileWatcher, error) {
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		return nil, fmt.Errorf("failed to create config watcher: %w", err)
+	}
+
+	w := &fileWatcher{watcher: watcher, resolved: make(map[string]string)}
+	for _, file := range files {
+		if file == "" {
+			continue
+		}
+		file, err := filepath.Abs(file)
+		if err != nil {
+			watcher.Close()
+			return nil, fmt.Errorf("failed to resolve config path: %w", err)
+		}
+		w.resolved[file], _ = filepath.EvalSymlinks(file)
+		// Watch directories rather than files so replaced files are still noticed
+		if err := watcher.Add(filepath.Dir(file)); err != nil {
+			watcher.Close()
+			return nil, fmt.Errorf("failed to watch %s: %w", filepath.Dir(file), err)
+		}
+	}
+	return w, nil
+}
+
+func (w *fileWatcher) close() {
+	w.watcher.Close()
+}
+
+// run calls onChange after each change to a watched file until ctx is cancelled
+func (w *fileWatcher) run(ctx context.Context, onChange func(*Config, error)) {
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case event, ok := <-w.watcher.Events:
+			if !ok {
+				return
+			}
+			if w.changed(event) {
+				onChange(Reload())
+			}
+		case err, ok := <-w.watcher.Errors:
+			if !ok {
+				return
+			}
+			onChange(nil, fmt.Errorf("config watcher: %w", err))
+		}
+	}
+}
+
+// changed reports whether event wrote a watched file or moved the target of its symlink
+func (w *fileWatcher) changed(event fsnotify.Event) bool {
+	name, _ := filepath.Abs(event.Name)
+	changed := false
+	for file, resolved := range w.resolved {
+		current, _ := filepath.EvalSymlinks(file)
+		written := name == file && event.Op&(fsnotify.Write|fsnotify.Create) != 0
+		if written || (current != "" && current != resolved) {
+			w.resolved[file] = current
+			changed = true
+		}
+	}
+	return changed
+}
@@ -0,0 +1,79 @@
+package config
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strconv"
+	"testing"
+	"time"
+)
+
+// TestWatchSymlinkSwap lays out the config the way Kubernetes mounts a ConfigMap, with
+// config.yaml -> ..data/config.yaml and ..data -> a timestamped directory, and swaps ..data
+func TestWatchSymlinkSwap(t *testing.T) {
+	dir := t.TempDir()
+	writeVersion := func(name string, cost int) {
+		if err := os.Mkdir(filepath.Join(dir, name), 0o700); err != nil {
+			t.Fatalf("mkdir: %v", err)
+		}
+		content := []byte("auth:\n  bcrypt_cost: " + strconv.Itoa(cost) + "\n")
+		if err := os.WriteFile(filepath.Join(dir, name, "config.yaml"), content, 0o600); err != nil {
+			t.Fatalf("write config: %v", err)
+		}
+	}
+	writeVersion("..v1", 10)
+	writeVersion("..v2", 11)
+	if err := os.Symlink("..v1", filepath.Join(dir, "..data")); err != nil {
+		t.Fatalf("symlink: %v", err)
+	}
+	path := filepath.Join(dir, "config.yaml")
+	if err := os.Symlink(filepath.Join("..data", "config.yaml"), path); err != nil {
+		t.Fatalf("symlink: %v", err)
+	}
+
+	cfg, err := Load(path)
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	if cfg.Auth.BcryptCost != 10 {
+		t.Fatalf("BcryptCost = %d, want 10", cfg.Auth.BcryptCost)
+	}
+
+	w, err := newFileWatcher(getLoadedFiles())
+	if err != nil {
+		t.Fatalf("newFileWatcher: %v", err)
+	}
+	defer w.close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	reloaded := make(chan *Config, 1)
+	go w.run(ctx, func(cfg *Config, err error) {
+		if err != nil {
+			t.Errorf("reload: %v", err)
+			return
+		}
+		select {
+		case reloaded <- cfg:
+		default:
+		}
+	})
+
+	// Swap ..data atomically, as the kubelet does
+	if err := os.Symlink("..v2", filepath.Join(dir, "..data_tmp")); err != nil {
+		t.Fatalf("symlink: %v", err)
+	}
+	if err := os.Rename(filepath.Join(dir, "..data_tmp"), filepath.Join(dir, "..data")); err != nil {
+		t.Fatalf("rename: %v", err)
+	}
+
+	select {
+	case cfg := <-reloaded:
+		if cfg.Auth.BcryptCost != 11 {
+			t.Errorf("reloaded BcryptCost = %d, want 11", cfg.Auth.BcryptCost)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("config was not reloaded after the ..data swap")
+	}
+}
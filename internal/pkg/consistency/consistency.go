@@ -0,0 +1,83 @@
+// Package consistency carries read-your-writes sessions across requests. A response to a
+// request that wrote carries a token; a client that sends it back on later requests has
+// them read from the primary until the replicas have caught up, even when they reach
+// another connection or instance.
+package consistency
+
+import (
+	"context"
+	"strconv"
+	"time"
+
+	"github.com/golang-standards/project-layout/internal/pkg/database"
+	"github.com/golang-standards/project-layout/internal/pkg/interceptors"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+)
+
+// MetadataKey carries the consistency token in response headers and request metadata
+const MetadataKey = "x-consistency-token"
+
+// UnaryServerInterceptor returns a new unary server interceptor that starts a
+// read-your-writes session per request, continuing the one in the client's token, and
+// returns a token in the response header once the session has written
+func UnaryServerInterceptor(window time.Duration) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		if window <= 0 {
+			return handler(ctx, req)
+		}
+
+		ctx = database.WithSession(ctx, window, tokenFromContext(ctx, window))
+		resp, err := handler(ctx, req)
+		if lastWrite, ok := database.LastWrite(ctx); ok {
+			_ = grpc.SetHeader(ctx, metadata.Pairs(MetadataKey, formatToken(lastWrite)))
+		}
+		return resp, err
+	}
+}
+
+// StreamServerInterceptor is the stream counterpart of UnaryServerInterceptor. Streams
+// only read, so they continue the client's session without returning a token.
+func StreamServerInterceptor(window time.Duration) grpc.StreamServerInterceptor {
+	return func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		if window <= 0 {
+			return handler(srv, ss)
+		}
+
+		ctx := database.WithSession(ss.Context(), window, tokenFromContext(ss.Context(), window))
+		return handler(srv, interceptors.WrapServerStream(ctx, ss))
+	}
+}
+
+// tokenFromContext returns the write time in the request's token, or zero when there is
+// no valid token. Tokens are not signed: a forged one can only send the caller's own
+// reads to the primary, so times in the future are capped at now and old ones ignored.
+func tokenFromContext(ctx context.Context, window time.Duration) time.Time {
+	md, ok := metadata.FromIncomingContext(ctx)
+	if !ok {
+		return time.Time{}
+	}
+	vals := md.Get(MetadataKey)
+	if len(vals) == 0 {
+		return time.Time{}
+	}
+	millis, err := strconv.ParseInt(vals[0], 10, 64)
+	if err != nil {
+		return time.Time{}
+	}
+
+	now := time.Now()
+	lastWrite := time.UnixMilli(millis)
+	if lastWrite.After(now) {
+		lastWrite = now
+	}
+	if now.Sub(lastWrite) >= window {
+		return time.Time{}
+	}
+	return lastWrite
+}
+
+// formatToken encodes a write time as a token
+func formatToken(lastWrite time.Time) string {
+	return strconv.FormatInt(lastWrite.UnixMilli(), 10)
+}
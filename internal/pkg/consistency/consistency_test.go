@@ -0,0 +1,126 @@
+package consistency
+
+import (
+	"context"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/golang-standards/project-layout/internal/pkg/database"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+)
+
+// headerTransport is a server transport stream recording the header set by a unary handler
+type headerTransport struct {
+	grpc.ServerTransportStream
+	header metadata.MD
+}
+
+func (s *headerTransport) SetHeader(md metadata.MD) error {
+	s.header = metadata.Join(s.header, md)
+	return nil
+}
+
+// fakeStream is a server stream with a fixed context
+type fakeStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (s *fakeStream) Context() context.Context { return s.ctx }
+
+func TestUnaryServerInterceptor(t *testing.T) {
+	now := time.Now()
+	tests := []struct {
+		name        string
+		token       string
+		write       bool
+		wantPrimary bool
+		wantToken   bool
+	}{
+		{name: "no token, read", wantPrimary: false},
+		{name: "no token, write", write: true, wantPrimary: true, wantToken: true},
+		{name: "recent token", token: formatToken(now.Add(-time.Second)), wantPrimary: true, wantToken: true},
+		{name: "expired token", token: formatToken(now.Add(-time.Minute)), wantPrimary: false},
+		{name: "future token", token: formatToken(now.Add(time.Hour)), wantPrimary: true, wantToken: true},
+		{name: "malformed token", token: "soon", wantPrimary: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			transport := &headerTransport{}
+			ctx := grpc.NewContextWithServerTransportStream(context.Background(), transport)
+			if tt.token != "" {
+				ctx = metadata.NewIncomingContext(ctx, metadata.Pairs(MetadataKey, tt.token))
+			}
+
+			var primary bool
+			_, err := UnaryServerInterceptor(5*time.Second)(ctx, nil, &grpc.UnaryServerInfo{},
+				func(ctx context.Context, req interface{}) (interface{}, error) {
+					if tt.write {
+						database.MarkWrite(ctx)
+					}
+					primary = database.ReadFromPrimary(ctx)
+					return nil, nil
+				})
+			if err != nil {
+				t.Fatalf("call: %v", err)
+			}
+
+			if primary != tt.wantPrimary {
+				t.Errorf("read from primary = %v, want %v", primary, tt.wantPrimary)
+			}
+			header := transport.header.Get(MetadataKey)
+			if !tt.wantToken {
+				if len(header) != 0 {
+					t.Errorf("header = %v, want none", header)
+				}
+				return
+			}
+			if len(header) != 1 {
+				t.Fatalf("header = %v, want one token", header)
+			}
+			millis, err := strconv.ParseInt(header[0], 10, 64)
+			if err != nil {
+				t.Fatalf("token %q: %v", header[0], err)
+			}
+			if got := time.UnixMilli(millis); got.After(time.Now()) {
+				t.Errorf("token time %v is in the future", got)
+			}
+		})
+	}
+}
+
+func TestStreamServerInterceptor(t *testing.T) {
+	tests := []struct {
+		name        string
+		token       string
+		wantPrimary bool
+	}{
+		{name: "no token"},
+		{name: "recent token", token: formatToken(time.Now()), wantPrimary: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			if tt.token != "" {
+				ctx = metadata.NewIncomingContext(ctx, metadata.Pairs(MetadataKey, tt.token))
+			}
+
+			var primary bool
+			err := StreamServerInterceptor(5*time.Second)(nil, &fakeStream{ctx: ctx}, &grpc.StreamServerInfo{},
+				func(srv interface{}, ss grpc.ServerStream) error {
+					primary = database.ReadFromPrimary(ss.Context())
+					return nil
+				})
+			if err != nil {
+				t.Fatalf("stream: %v", err)
+			}
+			if primary != tt.wantPrimary {
+				t.Errorf("read from primary = %v, want %v", primary, tt.wantPrimary)
+			}
+		})
+	}
+}
@@ -0,0 +1,103 @@
+package cors
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/golang-standards/project-layout/internal/pkg/config"
+)
+
+// Middleware answers CORS preflight requests and adds CORS headers to requests from
+// allowed origins. Requests from other origins get no CORS headers, so browsers block
+// them; disallowed preflights are rejected with 403.
+func Middleware(cfg config.CORSConfig, next http.Handler) http.Handler {
+	origins := make(map[string]bool, len(cfg.AllowedOrigins))
+	anyOrigin := false
+	for _, origin := range cfg.AllowedOrigins {
+		if origin == "*" {
+			anyOrigin = true
+		}
+		origins[origin] = true
+	}
+	methods := make(map[string]bool, len(cfg.AllowedMethods))
+	for _, method := range cfg.AllowedMethods {
+		methods[strings.ToUpper(method)] = true
+	}
+	headers := make(map[string]bool, len(cfg.AllowedHeaders))
+	for _, header := range cfg.AllowedHeaders {
+		headers[http.CanonicalHeaderKey(header)] = true
+	}
+
+	allowMethods := strings.Join(cfg.AllowedMethods, ", ")
+	allowHeaders := strings.Join(cfg.AllowedHeaders, ", ")
+	exposeHeaders := strings.Join(cfg.ExposedHeaders, ", ")
+	maxAge := strconv.Itoa(int(cfg.MaxAge.Seconds()))
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		origin := r.Header.Get("Origin")
+		if origin == "" {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		// Responses differ by origin, so caches must key on it
+		w.Header().Add("Vary", "Origin")
+		allowed := anyOrigin || origins[origin]
+		preflight := r.Method == http.MethodOptions && r.Header.Get("Access-Control-Request-Method") != ""
+
+		if !preflight {
+			if allowed {
+				setAllowOrigin(w, cfg, anyOrigin, origin)
+				if exposeHeaders != "" {
+					w.Header().Set("Access-Control-Expose-Headers", exposeHeaders)
+				}
+			}
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		w.Header().Add("Vary", "Access-Control-Request-Method")
+		w.Header().Add("Vary", "Access-Control-Request-Headers")
+		if !allowed || !methods[strings.ToUpper(r.Header.Get("Access-Control-Request-Method"))] ||
+			!headersAllowed(headers, r.Header.Get("Access-Control-Request-Headers")) {
+			w.WriteHeader(http.StatusForbidden)
+			return
+		}
+
+		setAllowOrigin(w, cfg, anyOrigin, origin)
+		w.Header().Set("Access-Control-Allow-Methods", allowMethods)
+		if allowHeaders != "" {
+			w.Header().Set("Access-Control-Allow-Headers", allowHeaders)
+		}
+		if cfg.MaxAge > 0 {
+			w.Header().Set("Access-Control-Max-Age", maxAge)
+		}
+		w.WriteHeader(http.StatusNoContent)
+	})
+}
+
+// setAllowOrigin echoes the request origin, or "*" for a wildcard policy without
+// credentials, which lets shared caches serve one response to every origin
+func setAllowOrigin(w http.ResponseWriter, cfg config.CORSConfig, anyOrigin bool, origin string) {
+	if anyOrigin && !cfg.AllowCredentials {
+		w.Header().Set("Access-Control-Allow-Origin", "*")
+		return
+	}
+	w.Header().Set("Access-Control-Allow-Origin", origin)
+	if cfg.AllowCredentials {
+		w.Header().Set("Access-Control-Allow-Credentials", "true")
+	}
+}
+
+// headersAllowed reports whether every header in a comma-separated
+// Access-Control-Request-Headers value is allowed
+func headersAllowed(allowed map[string]bool, requested string) bool {
+	for _, header := range strings.Split(requested, ",") {
+		header = strings.TrimSpace(header)
+		if header != "" && !allowed[http.CanonicalHeaderKey(header)] {
+			return false
+		}
+	}
+	return true
+}
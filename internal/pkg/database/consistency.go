@@ -0,0 +1,93 @@
+package database
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/plugin/dbresolver"
+)
+
+type sessionKey struct{}
+
+type primaryKey struct{}
+
+// session tracks the last write made through a context so reads can be pinned to the primary
+type session struct {
+	mu        sync.Mutex
+	window    time.Duration
+	lastWrite time.Time
+}
+
+// WithSession attaches a read-your-writes session to the context.
+// Reads made within window of a write in the same session are routed to the primary.
+// lastWrite continues a session from an earlier request; zero starts a fresh one.
+// A window of zero or less leaves the context unchanged.
+func WithSession(ctx context.Context, window time.Duration, lastWrite time.Time) context.Context {
+	if window <= 0 {
+		return ctx
+	}
+	if _, ok := ctx.Value(sessionKey{}).(*session); ok {
+		return ctx
+	}
+	return context.WithValue(ctx, sessionKey{}, &session{window: window, lastWrite: lastWrite})
+}
+
+// LastWrite returns when the context's session last wrote, reporting false when it has
+// no session or no write
+func LastWrite(ctx context.Context) (time.Time, bool) {
+	s, ok := ctx.Value(sessionKey{}).(*session)
+	if !ok {
+		return time.Time{}, false
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.lastWrite, !s.lastWrite.IsZero()
+}
+
+// MarkWrite records that a write happened in the context's session
+func MarkWrite(ctx context.Context) {
+	s, ok := ctx.Value(sessionKey{}).(*session)
+	if !ok {
+		return
+	}
+	s.mu.Lock()
+	s.lastWrite = time.Now()
+	s.mu.Unlock()
+}
+
+// WithPrimary returns a context whose reads always go to the primary, for callers that
+// can't tolerate replica lag regardless of session
+func WithPrimary(ctx context.Context) context.Context {
+	return context.WithValue(ctx, primaryKey{}, true)
+}
+
+// ReadFromPrimary reports whether reads in the context must go to the primary: it was
+// marked WithPrimary, or its session wrote recently
+func ReadFromPrimary(ctx context.Context) bool {
+	if primary, _ := ctx.Value(primaryKey{}).(bool); primary {
+		return true
+	}
+	s, ok := ctx.Value(sessionKey{}).(*session)
+	if !ok {
+		return false
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return !s.lastWrite.IsZero() && time.Since(s.lastWrite) < s.window
+}
+
+// Reader returns a session bound to ctx, pinned to the primary after a recent write
+func Reader(ctx context.Context, db *gorm.DB) *gorm.DB {
+	if ReadFromPrimary(ctx) {
+		return Primary(ctx, db)
+	}
+	return db.WithContext(ctx)
+}
+
+// Primary returns a session bound to ctx that reads from the primary, for reads that
+// decide a write and so must not see replica lag
+func Primary(ctx context.Context, db *gorm.DB) *gorm.DB {
+	return db.WithContext(ctx).Clauses(dbresolver.Write)
+}
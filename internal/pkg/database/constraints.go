@@ -0,0 +1,49 @@
+package database
+
+import (
+	"errors"
+	"strings"
+
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+// uniqueViolation is the Postgres SQLSTATE for unique_violation
+const uniqueViolation = "23505"
+
+// TenantNameIndex enforces case-insensitive unique display names within an organization.
+// Migration 00009 creates it.
+const TenantNameIndex = "idx_users_org_name_ci"
+
+// sqliteUniqueViolation prefixes SQLite unique constraint errors, which carry no error
+// type portable across drivers. Indexes on expressions appear after it as index 'name',
+// others as their table.column list, e.g. users.email.
+const sqliteUniqueViolation = "UNIQUE constraint failed: "
+
+// IsUniqueViolation reports whether err is a unique violation, optionally on a specific constraint
+func IsUniqueViolation(err error, constraint string) bool {
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) {
+		return pgErr.Code == uniqueViolation && (constraint == "" || pgErr.ConstraintName == constraint)
+	}
+	if err == nil {
+		return false
+	}
+	_, failed, ok := strings.Cut(err.Error(), sqliteUniqueViolation)
+	if !ok {
+		return false
+	}
+	if constraint == "" {
+		return true
+	}
+	if strings.HasPrefix(failed, "index '") {
+		return strings.HasPrefix(failed, "index '"+constraint+"'")
+	}
+	// Drop the trailing extended result code, e.g. " (2067)"
+	failed, _, _ = strings.Cut(failed, " (")
+	for _, column := range strings.Split(failed, ", ") {
+		if column == constraint {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,86 @@
+package database
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+func TestIsUniqueViolationSQLite(t *testing.T) {
+	db := newTestSQLiteDB(t)
+
+	insert := `INSERT INTO users (id, email, canonical_email, password, org_id, first_name, last_name) VALUES (?, ?, ?, 'x', ?, ?, ?)`
+	if err := db.Exec(insert, "u1", "jane@example.com", "jane@example.com", "org-1", "Jane", "Doe").Error; err != nil {
+		t.Fatalf("insert: %v", err)
+	}
+
+	tests := []struct {
+		name       string
+		args       []interface{}
+		constraint string
+		want       bool
+	}{
+		{
+			name:       "duplicate email",
+			args:       []interface{}{"u2", "jane@example.com", "jane2@example.com", nil, "", ""},
+			constraint: "users.email",
+			want:       true,
+		},
+		{
+			name:       "duplicate email, other constraint",
+			args:       []interface{}{"u2", "jane@example.com", "jane2@example.com", nil, "", ""},
+			constraint: TenantNameIndex,
+		},
+		{
+			name:       "duplicate tenant name",
+			args:       []interface{}{"u2", "other@example.com", "other@example.com", "org-1", "JANE", "doe"},
+			constraint: TenantNameIndex,
+			want:       true,
+		},
+		{
+			name: "duplicate tenant name, any constraint",
+			args: []interface{}{"u2", "other@example.com", "other@example.com", "org-1", "JANE", "doe"},
+			want: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := db.Exec(insert, tt.args...).Error
+			if err == nil {
+				t.Fatal("insert succeeded, want a unique violation")
+			}
+			if got := IsUniqueViolation(err, tt.constraint); got != tt.want {
+				t.Errorf("IsUniqueViolation(%q, %q) = %v, want %v", err, tt.constraint, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestIsUniqueViolation(t *testing.T) {
+	tests := []struct {
+		name       string
+		err        error
+		constraint string
+		want       bool
+	}{
+		{name: "nil"},
+		{name: "other error", err: errors.New("connection refused")},
+		{name: "any constraint", err: &pgconn.PgError{Code: "23505", ConstraintName: "users_email_key"}, want: true},
+		{name: "matching constraint", err: &pgconn.PgError{Code: "23505", ConstraintName: TenantNameIndex}, constraint: TenantNameIndex, want: true},
+		{name: "other constraint", err: &pgconn.PgError{Code: "23505", ConstraintName: "users_email_key"}, constraint: TenantNameIndex},
+		{name: "not a unique violation", err: &pgconn.PgError{Code: "23503"}},
+		{name: "sqlite column list", err: errors.New("UNIQUE constraint failed: users.org_id, users.email (2067)"), constraint: "users.email", want: true},
+		{name: "sqlite column prefix", err: errors.New("UNIQUE constraint failed: users.email_verified (2067)"), constraint: "users.email"},
+		{name: "sqlite index prefix", err: errors.New("UNIQUE constraint failed: index 'idx_users_org_name_ci_v2' (2067)"), constraint: TenantNameIndex},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := IsUniqueViolation(tt.err, tt.constraint); got != tt.want {
+				t.Errorf("IsUniqueViolation(%v, %q) = %v, want %v", tt.err, tt.constraint, got, tt.want)
+			}
+		})
+	}
+}
@@ -4,18 +4,53 @@ import (
 	"fmt"
 	"time"
 
-	"github.com/golang-standards/project-layout/internal/app/user-service/model"
+	"github.com/glebarez/sqlite"
 	"github.com/golang-standards/project-layout/internal/pkg/config"
 	"gorm.io/driver/postgres"
 	"gorm.io/gorm"
 	"gorm.io/gorm/logger"
+	"gorm.io/plugin/dbresolver"
+	"gorm.io/plugin/opentelemetry/tracing"
 )
 
-// NewPostgresDB creates a new PostgreSQL database connection
+// NewPostgresDB creates a new PostgreSQL database connection, routing reads to the
+// configured replicas
 func NewPostgresDB(cfg config.DatabaseConfig) (*gorm.DB, error) {
-	dsn := cfg.GetDSN()
+	db, err := open(postgres.Open(cfg.GetDSN()), cfg)
+	if err != nil {
+		return nil, err
+	}
+	if len(cfg.Replicas) == 0 {
+		return db, nil
+	}
+
+	replicas := make([]gorm.Dialector, 0, len(cfg.Replicas))
+	for _, dsn := range cfg.GetReplicaDSNs() {
+		replicas = append(replicas, postgres.Open(dsn))
+	}
+	resolver := dbresolver.Register(dbresolver.Config{
+		Replicas: replicas,
+		Policy:   dbresolver.RandomPolicy{},
+	}).
+		SetMaxOpenConns(cfg.MaxOpenConns).
+		SetMaxIdleConns(cfg.MaxIdleConns).
+		SetConnMaxLifetime(cfg.ConnMaxLifetime)
+	if err := db.Use(resolver); err != nil {
+		return nil, fmt.Errorf("failed to connect to read replicas: %w", err)
+	}
+	return db, nil
+}
+
+// NewSQLiteDB opens the SQLite database file at cfg.SQLitePath, creating it if needed.
+// WAL mode and a busy timeout let concurrent requests share the file.
+func NewSQLiteDB(cfg config.DatabaseConfig) (*gorm.DB, error) {
+	dsn := cfg.SQLitePath + "?_pragma=journal_mode(WAL)&_pragma=busy_timeout(5000)&_pragma=foreign_keys(1)"
+	return open(sqlite.Open(dsn), cfg)
+}
 
-	db, err := gorm.Open(postgres.Open(dsn), &gorm.Config{
+// open connects with the given dialector and applies the shared GORM and pool settings
+func open(dialector gorm.Dialector, cfg config.DatabaseConfig) (*gorm.DB, error) {
+	db, err := gorm.Open(dialector, &gorm.Config{
 		Logger: logger.Default.LogMode(logger.Info),
 		NowFunc: func() time.Time {
 			return time.Now().UTC()
@@ -25,16 +60,21 @@ func NewPostgresDB(cfg config.DatabaseConfig) (*gorm.DB, error) {
 		return nil, fmt.Errorf("failed to connect to database: %w", err)
 	}
 
+	// Emit a span for every SQL query
+	if err := db.Use(tracing.NewPlugin()); err != nil {
+		return nil, fmt.Errorf("failed to register tracing plugin: %w", err)
+	}
+
 	// Get underlying SQL database
 	sqlDB, err := db.DB()
 	if err != nil {
 		return nil, fmt.Errorf("failed to get underlying database: %w", err)
 	}
 
-	// Set connection pool settings
-	sqlDB.SetMaxIdleConns(10)
-	sqlDB.SetMaxOpenConns(100)
-	sqlDB.SetConnMaxLifetime(time.Hour)
+	// Set connection pool settings (see DatabaseConfig for zero-value behavior)
+	sqlDB.SetMaxOpenConns(cfg.MaxOpenConns)
+	sqlDB.SetMaxIdleConns(cfg.MaxIdleConns)
+	sqlDB.SetConnMaxLifetime(cfg.ConnMaxLifetime)
 
 	// Test connection
 	if err := sqlDB.Ping(); err != nil {
@@ -44,12 +84,9 @@ func NewPostgresDB(cfg config.DatabaseConfig) (*gorm.DB, error) {
 	return db, nil
 }
 
-// RunMigrations runs database migrations
-func RunMigrations(db *gorm.DB) error {
-	return db.AutoMigrate(
-		&model.User{},
-		// Add more models here
-	)
+// IsSQLite reports whether db is backed by SQLite
+func IsSQLite(db *gorm.DB) bool {
+	return db.Dialector.Name() == "sqlite"
 }
 
 // Close closes the database connection
@@ -0,0 +1,172 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"io/fs"
+	"strings"
+
+	"github.com/golang-standards/project-layout/migrations"
+	"github.com/pressly/goose/v3"
+	"gorm.io/gorm"
+)
+
+// migrationsTable records the applied migration versions
+const migrationsTable = "schema_migrations"
+
+// migrationLockID is the Postgres advisory lock key held while migrating
+const migrationLockID int64 = 0x75736572736d6967 // "usersmig"
+
+// RunMigrations applies all pending versioned migrations for the database dialect.
+// On Postgres it holds an advisory lock, so concurrently starting replicas migrate one at a time.
+func RunMigrations(db *gorm.DB) error {
+	sqlDB, err := setupGoose(db)
+	if err != nil {
+		return err
+	}
+	return withMigrationLock(db, sqlDB, func() error {
+		if err := goose.Up(sqlDB, migrationsDir(db)); err != nil {
+			return fmt.Errorf("failed to apply migrations: %w", err)
+		}
+		return nil
+	})
+}
+
+// Rollback reverts the most recently applied steps migrations
+func Rollback(db *gorm.DB, steps int) error {
+	sqlDB, err := setupGoose(db)
+	if err != nil {
+		return err
+	}
+	return withMigrationLock(db, sqlDB, func() error {
+		for i := 0; i < steps; i++ {
+			if err := goose.Down(sqlDB, migrationsDir(db)); err != nil {
+				return fmt.Errorf("failed to roll back migration %d of %d: %w", i+1, steps, err)
+			}
+		}
+		return nil
+	})
+}
+
+// PendingMigrations returns how many embedded migrations have not been applied
+func PendingMigrations(db *gorm.DB) (int, error) {
+	pending, err := pendingMigrations(db)
+	if err != nil {
+		return 0, err
+	}
+	return len(pending), nil
+}
+
+// MigrationPlan returns the Up SQL of each pending migration, in order, without running it.
+// Each entry starts with a comment naming the migration file.
+func MigrationPlan(db *gorm.DB) ([]string, error) {
+	pending, err := pendingMigrations(db)
+	if err != nil {
+		return nil, err
+	}
+
+	plan := make([]string, 0, len(pending))
+	for _, m := range pending {
+		data, err := fs.ReadFile(migrations.FS, m.Source)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read migration %s: %w", m.Source, err)
+		}
+		plan = append(plan, fmt.Sprintf("-- %s\n%s", m.Source, upSection(string(data))))
+	}
+	return plan, nil
+}
+
+// pendingMigrations lists the embedded migrations newer than the database version
+func pendingMigrations(db *gorm.DB) (goose.Migrations, error) {
+	sqlDB, err := setupGoose(db)
+	if err != nil {
+		return nil, err
+	}
+	current, err := goose.GetDBVersion(sqlDB)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read migration version: %w", err)
+	}
+	all, err := goose.CollectMigrations(migrationsDir(db), 0, goose.MaxVersion)
+	if err != nil {
+		return nil, fmt.Errorf("failed to collect migrations: %w", err)
+	}
+
+	var pending goose.Migrations
+	for _, m := range all {
+		if m.Version > current {
+			pending = append(pending, m)
+		}
+	}
+	return pending, nil
+}
+
+// upSection extracts the SQL between the goose Up and Down annotations, dropping
+// the annotation lines themselves
+func upSection(source string) string {
+	var lines []string
+	inUp := false
+	for _, line := range strings.Split(source, "\n") {
+		trimmed := strings.TrimSpace(line)
+		switch {
+		case strings.HasPrefix(trimmed, "-- +goose Up"):
+			inUp = true
+		case strings.HasPrefix(trimmed, "-- +goose Down"):
+			inUp = false
+		case strings.HasPrefix(trimmed, "-- +goose"):
+		case inUp:
+			lines = append(lines, line)
+		}
+	}
+	return strings.TrimSpace(strings.Join(lines, "\n"))
+}
+
+// withMigrationLock runs fn while holding the migration advisory lock on a dedicated
+// connection; fn itself uses other pool connections. SQLite has a single writer, so
+// it needs no lock.
+func withMigrationLock(db *gorm.DB, sqlDB *sql.DB, fn func() error) error {
+	if IsSQLite(db) {
+		return fn()
+	}
+
+	ctx := context.Background()
+	conn, err := sqlDB.Conn(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to get connection for migration lock: %w", err)
+	}
+	defer conn.Close()
+
+	if _, err := conn.ExecContext(ctx, "SELECT pg_advisory_lock($1)", migrationLockID); err != nil {
+		return fmt.Errorf("failed to acquire migration lock: %w", err)
+	}
+	defer conn.ExecContext(ctx, "SELECT pg_advisory_unlock($1)", migrationLockID)
+
+	return fn()
+}
+
+// setupGoose points goose at the embedded migrations and returns the underlying connection
+func setupGoose(db *gorm.DB) (*sql.DB, error) {
+	goose.SetBaseFS(migrations.FS)
+	goose.SetTableName(migrationsTable)
+	dialect := "postgres"
+	if IsSQLite(db) {
+		dialect = "sqlite3"
+	}
+	if err := goose.SetDialect(dialect); err != nil {
+		return nil, fmt.Errorf("failed to set migration dialect: %w", err)
+	}
+
+	sqlDB, err := db.DB()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get underlying database: %w", err)
+	}
+	return sqlDB, nil
+}
+
+// migrationsDir is the embedded directory holding the migrations for db's dialect
+func migrationsDir(db *gorm.DB) string {
+	if IsSQLite(db) {
+		return "sqlite"
+	}
+	return "."
+}
@@ -0,0 +1,103 @@
+package database
+
+import (
+	"io/fs"
+	"path/filepath"
+	"testing"
+
+	"github.com/golang-standards/project-layout/internal/pkg/config"
+	"github.com/golang-standards/project-layout/migrations"
+	"gorm.io/gorm"
+)
+
+// newTestSQLiteDB opens a migrated SQLite database in a temporary directory
+func newTestSQLiteDB(t *testing.T) *gorm.DB {
+	t.Helper()
+	db, err := NewSQLiteDB(config.DatabaseConfig{SQLitePath: filepath.Join(t.TempDir(), "test.db"), MaxOpenConns: 1})
+	if err != nil {
+		t.Fatalf("open sqlite: %v", err)
+	}
+	t.Cleanup(func() { Close(db) })
+
+	if err := RunMigrations(db); err != nil {
+		t.Fatalf("RunMigrations: %v", err)
+	}
+	return db
+}
+
+func TestRunMigrationsSQLite(t *testing.T) {
+	db := newTestSQLiteDB(t)
+
+	pending, err := PendingMigrations(db)
+	if err != nil {
+		t.Fatalf("PendingMigrations: %v", err)
+	}
+	if pending != 0 {
+		t.Errorf("pending = %d after migrating, want 0", pending)
+	}
+	if !db.Migrator().HasIndex("users", "idx_users_canonical_email") {
+		t.Error("expected the canonical email index")
+	}
+
+	insert := `INSERT INTO users (id, email, canonical_email, password) VALUES (?, ?, ?, 'x')`
+	if err := db.Exec(insert, "u1", "j.doe@gmail.com", "jdoe@gmail.com").Error; err != nil {
+		t.Fatalf("insert: %v", err)
+	}
+	err = db.Exec(insert, "u2", "jdoe+x@gmail.com", "jdoe@gmail.com").Error
+	if !IsUniqueViolation(err, "") {
+		t.Errorf("err = %v, want a unique violation on the canonical email", err)
+	}
+}
+
+func TestRollbackSQLite(t *testing.T) {
+	db := newTestSQLiteDB(t)
+
+	applied, err := migrationCount()
+	if err != nil {
+		t.Fatalf("count migrations: %v", err)
+	}
+	if err := Rollback(db, applied); err != nil {
+		t.Fatalf("Rollback: %v", err)
+	}
+	if db.Migrator().HasTable("users") {
+		t.Error("expected rolling back every migration to drop the users table")
+	}
+
+	if err := RunMigrations(db); err != nil {
+		t.Fatalf("RunMigrations after rollback: %v", err)
+	}
+}
+
+// migrationCount returns the number of embedded SQLite migrations
+func migrationCount() (int, error) {
+	entries, err := fs.Glob(migrations.FS, "sqlite/*.sql")
+	return len(entries), err
+}
+
+func TestLowercaseEmailsMigration(t *testing.T) {
+	db := newTestSQLiteDB(t)
+	// Roll back to just before 00010_lowercase_emails
+	count, err := migrationCount()
+	if err != nil {
+		t.Fatalf("count migrations: %v", err)
+	}
+	if err := Rollback(db, count-9); err != nil {
+		t.Fatalf("Rollback: %v", err)
+	}
+
+	insert := `INSERT INTO users (id, email, canonical_email, password) VALUES (?, ?, ?, 'x')`
+	if err := db.Exec(insert, "u1", "Foo@Bar.com", "foo@bar.com").Error; err != nil {
+		t.Fatalf("insert: %v", err)
+	}
+	if err := RunMigrations(db); err != nil {
+		t.Fatalf("RunMigrations: %v", err)
+	}
+
+	var email string
+	if err := db.Raw(`SELECT email FROM users WHERE id = 'u1'`).Scan(&email).Error; err != nil {
+		t.Fatalf("select: %v", err)
+	}
+	if email != "foo@bar.com" {
+		t.Errorf("email = %q, want it lowercased", email)
+	}
+}
@@ -0,0 +1,33 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"gorm.io/gorm"
+)
+
+// Pinger checks that a database is reachable. Health checks depend on it rather than
+// on *gorm.DB, so they can be exercised with a fake.
+type Pinger interface {
+	Ping(ctx context.Context) error
+}
+
+type sqlPinger struct {
+	db *sql.DB
+}
+
+// NewPinger returns a Pinger for the connection pool behind db
+func NewPinger(db *gorm.DB) (Pinger, error) {
+	sqlDB, err := db.DB()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get underlying database: %w", err)
+	}
+	return &sqlPinger{db: sqlDB}, nil
+}
+
+// Ping verifies a connection can be used, honoring ctx's deadline
+func (p *sqlPinger) Ping(ctx context.Context) error {
+	return p.db.PingContext(ctx)
+}
@@ -0,0 +1,113 @@
+package database
+
+import (
+	"context"
+	"database/sql/driver"
+	"errors"
+	"math/rand/v2"
+	"time"
+
+	"github.com/golang-standards/project-layout/internal/pkg/config"
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+// defaultRetryableCodes are Postgres SQLSTATE codes that indicate a transient failure
+var defaultRetryableCodes = []string{
+	"40001", // serialization_failure
+	"40P01", // deadlock_detected
+	"08000", // connection_exception
+	"08003", // connection_does_not_exist
+	"08006", // connection_failure
+	"57P01", // admin_shutdown
+}
+
+// RetryPolicy controls how database operations are retried
+type RetryPolicy struct {
+	MaxAttempts int
+	BaseDelay   time.Duration
+	// MaxDelay caps the backoff between attempts; zero leaves it uncapped
+	MaxDelay time.Duration
+	// IsRetryable classifies errors as transient. Nil falls back to the Postgres default.
+	IsRetryable func(err error) bool
+}
+
+// NewRetryPolicy creates a retry policy from configuration using the Postgres classifier
+func NewRetryPolicy(cfg config.RetryConfig) RetryPolicy {
+	codes := cfg.RetryableCodes
+	if len(codes) == 0 {
+		codes = defaultRetryableCodes
+	}
+
+	return RetryPolicy{
+		MaxAttempts: cfg.MaxAttempts,
+		BaseDelay:   cfg.BaseDelay,
+		MaxDelay:    cfg.MaxDelay,
+		IsRetryable: PostgresRetryable(codes...),
+	}
+}
+
+// PostgresRetryable returns a classifier treating the given SQLSTATE codes and
+// dropped connections as transient
+func PostgresRetryable(codes ...string) func(err error) bool {
+	retryable := make(map[string]bool, len(codes))
+	for _, code := range codes {
+		retryable[code] = true
+	}
+
+	return func(err error) bool {
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) {
+			return retryable[pgErr.Code]
+		}
+		return errors.Is(err, driver.ErrBadConn) || pgconn.SafeToRetry(err)
+	}
+}
+
+// Retry runs op until it succeeds, fails with a non-retryable error, runs out of
+// attempts, or the context is done. The backoff doubles after each attempt up to
+// MaxDelay, and each wait is a random duration up to the backoff ("full jitter") so
+// concurrent retries spread out. It gives up early when the wait would pass the
+// context deadline.
+func Retry(ctx context.Context, policy RetryPolicy, op func() error) error {
+	isRetryable := policy.IsRetryable
+	if isRetryable == nil {
+		isRetryable = PostgresRetryable(defaultRetryableCodes...)
+	}
+
+	attempts := policy.MaxAttempts
+	if attempts < 1 {
+		attempts = 1
+	}
+
+	backoff := policy.BaseDelay
+	var err error
+	for attempt := 1; ; attempt++ {
+		if err = op(); err == nil || attempt >= attempts || !isRetryable(err) {
+			return err
+		}
+
+		wait := jitter(backoff)
+		if deadline, ok := ctx.Deadline(); ok && time.Until(deadline) < wait {
+			return err
+		}
+
+		select {
+		case <-ctx.Done():
+			return err
+		case <-time.After(wait):
+		}
+
+		backoff *= 2
+		if policy.MaxDelay > 0 && backoff > policy.MaxDelay {
+			backoff = policy.MaxDelay
+		}
+	}
+}
+
+// jitter returns a random duration in [0, d]
+func jitter(d time.Duration) time.Duration {
+	if d <= 0 {
+		return 0
+	}
+	return time.Duration(rand.Int64N(int64(d) + 1))
+}
@@ -0,0 +1,98 @@
+package database
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+func TestRetry(t *testing.T) {
+	errTransient := errors.New("transient")
+	errPermanent := errors.New("permanent")
+	retrySentinel := func(err error) bool { return errors.Is(err, errTransient) }
+
+	tests := []struct {
+		name         string
+		policy       RetryPolicy
+		errs         []error
+		wantErr      error
+		wantAttempts int
+	}{
+		{
+			name:         "custom classifier retries until success",
+			policy:       RetryPolicy{MaxAttempts: 5, IsRetryable: retrySentinel},
+			errs:         []error{errTransient, errTransient, nil},
+			wantAttempts: 3,
+		},
+		{
+			name:         "non-retryable error returns immediately",
+			policy:       RetryPolicy{MaxAttempts: 5, IsRetryable: retrySentinel},
+			errs:         []error{errPermanent},
+			wantErr:      errPermanent,
+			wantAttempts: 1,
+		},
+		{
+			name:         "attempts exhausted",
+			policy:       RetryPolicy{MaxAttempts: 3, IsRetryable: retrySentinel},
+			errs:         []error{errTransient, errTransient, errTransient, nil},
+			wantErr:      errTransient,
+			wantAttempts: 3,
+		},
+		{
+			name:         "zero attempts runs once",
+			policy:       RetryPolicy{IsRetryable: retrySentinel},
+			errs:         []error{errTransient, nil},
+			wantErr:      errTransient,
+			wantAttempts: 1,
+		},
+		{
+			name:         "nil classifier retries Postgres serialization failures",
+			policy:       RetryPolicy{MaxAttempts: 3},
+			errs:         []error{&pgconn.PgError{Code: "40001"}, nil},
+			wantAttempts: 2,
+		},
+		{
+			name:         "nil classifier does not retry other errors",
+			policy:       RetryPolicy{MaxAttempts: 3},
+			errs:         []error{errTransient, nil},
+			wantErr:      errTransient,
+			wantAttempts: 1,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			attempts := 0
+			err := Retry(context.Background(), tt.policy, func() error {
+				err := tt.errs[attempts]
+				attempts++
+				return err
+			})
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("err = %v, want %v", err, tt.wantErr)
+			}
+			if attempts != tt.wantAttempts {
+				t.Errorf("attempts = %d, want %d", attempts, tt.wantAttempts)
+			}
+		})
+	}
+}
+
+func TestRetryStopsAtDeadline(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Millisecond)
+	defer cancel()
+
+	attempts := 0
+	policy := RetryPolicy{MaxAttempts: 5, BaseDelay: time.Hour, IsRetryable: func(error) bool { return true }}
+	err := Retry(ctx, policy, func() error {
+		attempts++
+		return errors.New("transient")
+	})
+	// The jittered wait may be shorter than the deadline, but never long enough for a third try
+	if err == nil || attempts > 2 {
+		t.Errorf("Retry = %v after %d attempts, want an error within 2 attempts", err, attempts)
+	}
+}
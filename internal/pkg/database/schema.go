@@ -0,0 +1,42 @@
+package database
+
+import (
+	"errors"
+	"fmt"
+
+	"gorm.io/gorm"
+)
+
+// VerifySchema checks that the table and every column of each model exist, so a skipped
+// migration fails at startup rather than on the first query touching the missing column.
+// The error lists every mismatch found.
+func VerifySchema(db *gorm.DB, models ...interface{}) error {
+	migrator := db.Migrator()
+
+	var errs []error
+	for _, m := range models {
+		stmt := &gorm.Statement{DB: db}
+		if err := stmt.Parse(m); err != nil {
+			return fmt.Errorf("failed to parse model %T: %w", m, err)
+		}
+		table := stmt.Schema.Table
+
+		if !migrator.HasTable(m) {
+			errs = append(errs, fmt.Errorf("missing table %s", table))
+			continue
+		}
+		for _, field := range stmt.Schema.Fields {
+			if field.DBName == "" {
+				continue
+			}
+			if !migrator.HasColumn(m, field.DBName) {
+				errs = append(errs, fmt.Errorf("missing column %s.%s", table, field.DBName))
+			}
+		}
+	}
+
+	if err := errors.Join(errs...); err != nil {
+		return fmt.Errorf("database schema does not match the models; check for skipped migrations:\n%w", err)
+	}
+	return nil
+}
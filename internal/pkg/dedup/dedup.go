@@ -0,0 +1,171 @@
+// Package dedup replays the result of a retried mutating request. A retry from the same
+// caller to the same method with the same payload gets the first attempt's result instead
+// of running again. Clients that send an idempotency key are matched on the key instead of
+// the payload.
+package dedup
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/golang-standards/project-layout/internal/pkg/auth"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+)
+
+// MetadataKey carries the client's idempotency key
+const MetadataKey = "idempotency-key"
+
+// MaxKeyLength caps the idempotency key, which is held in memory for the window
+const MaxKeyLength = 128
+
+var (
+	errKeyTooLong = status.Errorf(codes.InvalidArgument, "%s must be at most %d characters", MetadataKey, MaxKeyLength)
+	errKeyReused  = status.Errorf(codes.InvalidArgument, "%s was already used for a different request", MetadataKey)
+)
+
+// readOnlyPrefixes identify methods that are safe to repeat and never deduplicated
+var readOnlyPrefixes = []string{"Get", "List", "BatchGet", "Stream", "Count", "Check", "Watch"}
+
+// call is a single execution shared by the requests carrying its deduplication key
+type call struct {
+	payload string
+	done    chan struct{}
+	resp    interface{}
+	err     error
+	expires time.Time
+}
+
+// Deduplicator replays mutating requests retried within a window
+type Deduplicator struct {
+	mu     sync.Mutex
+	window time.Duration
+	calls  map[string]*call
+}
+
+// NewDeduplicator creates a deduplicator that replays results for window after the first
+// request completes. A window of zero or less disables deduplication.
+func NewDeduplicator(window time.Duration) *Deduplicator {
+	return &Deduplicator{
+		window: window,
+		calls:  make(map[string]*call),
+	}
+}
+
+// UnaryServerInterceptor returns a new unary server interceptor that runs a mutating
+// request once per caller, method and payload and returns its result to every retry.
+// When the request carries an idempotency key it is used in place of the payload, and a
+// retry whose payload differs from the first request is rejected rather than given
+// another's result.
+func UnaryServerInterceptor(d *Deduplicator) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		if d.window <= 0 || isReadOnly(info.FullMethod) {
+			return handler(ctx, req)
+		}
+
+		idempotencyKey := keyFromContext(ctx)
+		if len(idempotencyKey) > MaxKeyLength {
+			return nil, errKeyTooLong
+		}
+		msg, ok := req.(proto.Message)
+		if !ok {
+			return handler(ctx, req)
+		}
+		payload, err := payloadHash(msg)
+		if err != nil {
+			return handler(ctx, req)
+		}
+		key := callerID(ctx) + "|" + info.FullMethod + "|"
+		if idempotencyKey != "" {
+			key += "key:" + idempotencyKey
+		} else {
+			key += "payload:" + payload
+		}
+
+		d.mu.Lock()
+		if c, ok := d.calls[key]; ok && (c.expires.IsZero() || time.Now().Before(c.expires)) {
+			d.mu.Unlock()
+			if c.payload != payload {
+				return nil, errKeyReused
+			}
+			select {
+			case <-c.done:
+				return c.resp, c.err
+			case <-ctx.Done():
+				return nil, ctx.Err()
+			}
+		}
+		c := &call{payload: payload, done: make(chan struct{})}
+		d.calls[key] = c
+		d.mu.Unlock()
+
+		c.resp, c.err = handler(ctx, req)
+
+		d.mu.Lock()
+		c.expires = time.Now().Add(d.window)
+		d.mu.Unlock()
+		close(c.done)
+
+		time.AfterFunc(d.window, func() {
+			d.mu.Lock()
+			if d.calls[key] == c {
+				delete(d.calls, key)
+			}
+			d.mu.Unlock()
+		})
+
+		return c.resp, c.err
+	}
+}
+
+// isReadOnly reports whether the method name starts with a read-only verb
+func isReadOnly(fullMethod string) bool {
+	name := fullMethod[strings.LastIndex(fullMethod, "/")+1:]
+	for _, prefix := range readOnlyPrefixes {
+		if strings.HasPrefix(name, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// keyFromContext returns the idempotency key in the request metadata, if any
+func keyFromContext(ctx context.Context) string {
+	md, ok := metadata.FromIncomingContext(ctx)
+	if !ok {
+		return ""
+	}
+	if vals := md.Get(MetadataKey); len(vals) > 0 {
+		return vals[0]
+	}
+	return ""
+}
+
+// payloadHash returns the hex SHA-256 of the request's deterministic encoding
+func payloadHash(msg proto.Message) (string, error) {
+	payload, err := proto.MarshalOptions{Deterministic: true}.Marshal(msg)
+	if err != nil {
+		return "", err
+	}
+	sum := sha256.Sum256(payload)
+	return hex.EncodeToString(sum[:]), nil
+}
+
+// callerID returns the authenticated user ID, falling back to the peer address
+func callerID(ctx context.Context) string {
+	if userID, ok := auth.UserIDFromContext(ctx); ok {
+		return "user:" + userID
+	}
+	if p, ok := peer.FromContext(ctx); ok && p.Addr != nil {
+		return "peer:" + p.Addr.String()
+	}
+	return "anonymous"
+}
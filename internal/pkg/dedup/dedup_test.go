@@ -0,0 +1,136 @@
+package dedup
+
+import (
+	"context"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/golang-standards/project-layout/internal/pkg/auth"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/wrapperspb"
+)
+
+// request describes one call to the interceptor
+type request struct {
+	key     string
+	userID  string
+	method  string
+	payload string
+}
+
+func (r request) context() context.Context {
+	ctx := context.Background()
+	if r.key != "" {
+		ctx = metadata.NewIncomingContext(ctx, metadata.Pairs(MetadataKey, r.key))
+	}
+	if r.userID != "" {
+		ctx = auth.WithPrincipal(ctx, auth.Principal{UserID: r.userID, Role: auth.RoleUser})
+	}
+	return ctx
+}
+
+func TestUnaryServerInterceptor(t *testing.T) {
+	first := request{key: "k1", userID: "u1", method: "/user.v1.UserService/CreateUser", payload: "jane"}
+
+	tests := []struct {
+		name      string
+		first     *request
+		second    request
+		wantRuns  int32
+		wantCode  codes.Code
+		wantFirst bool
+	}{
+		{name: "same key and payload replays", second: first, wantRuns: 1, wantFirst: true},
+		{name: "same key, different payload", second: request{key: "k1", userID: "u1", method: first.method, payload: "john"}, wantRuns: 1, wantCode: codes.InvalidArgument},
+		{name: "different key", second: request{key: "k2", userID: "u1", method: first.method, payload: "jane"}, wantRuns: 2},
+		{name: "no key", second: request{userID: "u1", method: first.method, payload: "jane"}, wantRuns: 2},
+		{name: "no keys, same payload replays", first: &request{userID: "u1", method: first.method, payload: "jane"}, second: request{userID: "u1", method: first.method, payload: "jane"}, wantRuns: 1, wantFirst: true},
+		{name: "no keys, different payload", first: &request{userID: "u1", method: first.method, payload: "jane"}, second: request{userID: "u1", method: first.method, payload: "john"}, wantRuns: 2},
+		{name: "no keys, other caller", first: &request{userID: "u1", method: first.method, payload: "jane"}, second: request{userID: "u2", method: first.method, payload: "jane"}, wantRuns: 2},
+		{name: "no keys, other method", first: &request{userID: "u1", method: first.method, payload: "jane"}, second: request{userID: "u1", method: "/user.v1.UserService/UpdateUser", payload: "jane"}, wantRuns: 2},
+		{name: "other caller", second: request{key: "k1", userID: "u2", method: first.method, payload: "jane"}, wantRuns: 2},
+		{name: "other method", second: request{key: "k1", userID: "u1", method: "/user.v1.UserService/UpdateUser", payload: "jane"}, wantRuns: 2},
+		{name: "read-only method", second: request{key: "k1", userID: "u1", method: "/user.v1.UserService/GetUser", payload: "jane"}, wantRuns: 2},
+		{name: "key too long", second: request{key: strings.Repeat("k", MaxKeyLength+1), userID: "u1", method: first.method, payload: "jane"}, wantRuns: 1, wantCode: codes.InvalidArgument},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			interceptor := UnaryServerInterceptor(NewDeduplicator(time.Minute))
+			var runs atomic.Int32
+			call := func(r request) (interface{}, error) {
+				return interceptor(r.context(), wrapperspb.String(r.payload), &grpc.UnaryServerInfo{FullMethod: r.method},
+					func(ctx context.Context, req interface{}) (interface{}, error) {
+						return runs.Add(1), nil
+					})
+			}
+
+			initial := first
+			if tt.first != nil {
+				initial = *tt.first
+			}
+			if _, err := call(initial); err != nil {
+				t.Fatalf("first call: %v", err)
+			}
+			resp, err := call(tt.second)
+			if got := status.Code(err); got != tt.wantCode {
+				t.Fatalf("second call code = %v, want %v", got, tt.wantCode)
+			}
+			if got := runs.Load(); got != tt.wantRuns {
+				t.Errorf("handler ran %d times, want %d", got, tt.wantRuns)
+			}
+			if tt.wantFirst && resp != int32(1) {
+				t.Errorf("second call = %v, want the first result", resp)
+			}
+		})
+	}
+}
+
+func TestUnaryServerInterceptorConcurrent(t *testing.T) {
+	interceptor := UnaryServerInterceptor(NewDeduplicator(time.Minute))
+	r := request{key: "k1", userID: "u1", method: "/user.v1.UserService/CreateUser", payload: "jane"}
+
+	var runs atomic.Int32
+	started := make(chan struct{}, 1)
+	release := make(chan struct{})
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		started <- struct{}{}
+		<-release
+		return runs.Add(1), nil
+	}
+
+	const callers = 10
+	var wg sync.WaitGroup
+	results := make([]interface{}, callers)
+	run := func(i int) {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			results[i], _ = interceptor(r.context(), wrapperspb.String(r.payload), &grpc.UnaryServerInfo{FullMethod: r.method}, handler)
+		}()
+	}
+
+	// The rest join while the first is still in its handler
+	run(0)
+	<-started
+	for i := 1; i < callers; i++ {
+		run(i)
+	}
+	close(release)
+	wg.Wait()
+
+	if got := runs.Load(); got != 1 {
+		t.Errorf("handler ran %d times, want 1", got)
+	}
+	for i, result := range results {
+		if result != int32(1) {
+			t.Errorf("caller %d got %v, want the single result", i, result)
+		}
+	}
+}
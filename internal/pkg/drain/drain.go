@@ -0,0 +1,56 @@
+package drain
+
+import (
+	"context"
+	"sync/atomic"
+
+	"google.golang.org/grpc"
+)
+
+// State tracks whether the instance is draining and how many requests are in flight
+type State struct {
+	draining atomic.Bool
+	inFlight atomic.Int64
+}
+
+// NewState creates a new drain state
+func NewState() *State {
+	return &State{}
+}
+
+// SetDraining flips the instance into draining mode and returns the current in-flight count.
+// Readiness reflects the flip as soon as this returns.
+func (s *State) SetDraining() int64 {
+	s.draining.Store(true)
+	return s.inFlight.Load()
+}
+
+// Draining reports whether the instance is draining
+func (s *State) Draining() bool {
+	return s.draining.Load()
+}
+
+// InFlight returns the number of requests currently being handled
+func (s *State) InFlight() int64 {
+	return s.inFlight.Load()
+}
+
+// UnaryServerInterceptor returns a new unary server interceptor that tracks in-flight requests
+func UnaryServerInterceptor(s *State) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		s.inFlight.Add(1)
+		defer s.inFlight.Add(-1)
+
+		return handler(ctx, req)
+	}
+}
+
+// StreamServerInterceptor returns a new stream server interceptor that counts open streams
+// as in-flight requests
+func StreamServerInterceptor(s *State) grpc.StreamServerInterceptor {
+	return func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		s.inFlight.Add(1)
+		defer s.inFlight.Add(-1)
+		return handler(srv, ss)
+	}
+}
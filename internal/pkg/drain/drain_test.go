@@ -0,0 +1,57 @@
+package drain
+
+import (
+	"context"
+	"testing"
+
+	"google.golang.org/grpc"
+)
+
+// fakeStream is a server stream carrying only a context
+type fakeStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (s *fakeStream) Context() context.Context { return s.ctx }
+
+func TestInterceptorsTrackInFlight(t *testing.T) {
+	s := NewState()
+
+	tests := []struct {
+		name string
+		call func(check func()) error
+	}{
+		{
+			name: "unary",
+			call: func(check func()) error {
+				_, err := UnaryServerInterceptor(s)(context.Background(), nil, &grpc.UnaryServerInfo{},
+					func(ctx context.Context, req interface{}) (interface{}, error) { check(); return nil, nil })
+				return err
+			},
+		},
+		{
+			name: "stream",
+			call: func(check func()) error {
+				return StreamServerInterceptor(s)(nil, &fakeStream{ctx: context.Background()}, &grpc.StreamServerInfo{},
+					func(srv interface{}, ss grpc.ServerStream) error { check(); return nil })
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.call(func() {
+				if got := s.InFlight(); got != 1 {
+					t.Errorf("in flight during the call = %d, want 1", got)
+				}
+			})
+			if err != nil {
+				t.Fatalf("call: %v", err)
+			}
+			if got := s.InFlight(); got != 0 {
+				t.Errorf("in flight after the call = %d, want 0", got)
+			}
+		})
+	}
+}
@@ -0,0 +1,62 @@
+package emailnorm
+
+import (
+	"strings"
+
+	"github.com/golang-standards/project-layout/internal/pkg/config"
+)
+
+// Normalizer computes provider-aware canonical email addresses
+type Normalizer struct {
+	enabled bool
+	rules   map[string]config.EmailProviderRule
+}
+
+// New creates a new normalizer from the email configuration
+func New(cfg config.EmailConfig) *Normalizer {
+	rules := make(map[string]config.EmailProviderRule)
+	for _, rule := range cfg.Providers {
+		for _, domain := range rule.Domains {
+			rules[strings.ToLower(domain)] = rule
+		}
+	}
+
+	return &Normalizer{
+		enabled: cfg.CanonicalizeAliases,
+		rules:   rules,
+	}
+}
+
+// Canonical returns the canonical form of an email address used as a uniqueness key.
+// When canonicalization is disabled the address is returned unchanged.
+func (n *Normalizer) Canonical(email string) string {
+	if n == nil || !n.enabled {
+		return email
+	}
+
+	email = strings.ToLower(strings.TrimSpace(email))
+	at := strings.LastIndex(email, "@")
+	if at <= 0 || at == len(email)-1 {
+		return email
+	}
+
+	local, domain := email[:at], email[at+1:]
+	rule, ok := n.rules[domain]
+	if !ok {
+		return email
+	}
+
+	if rule.StripPlus {
+		if plus := strings.Index(local, "+"); plus >= 0 {
+			local = local[:plus]
+		}
+	}
+	if rule.StripDots {
+		local = strings.ReplaceAll(local, ".", "")
+	}
+	if rule.CanonicalDomain != "" {
+		domain = strings.ToLower(rule.CanonicalDomain)
+	}
+
+	return local + "@" + domain
+}
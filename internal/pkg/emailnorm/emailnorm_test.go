@@ -0,0 +1,41 @@
+package emailnorm
+
+import (
+	"testing"
+
+	"github.com/golang-standards/project-layout/internal/pkg/config"
+)
+
+func TestCanonical(t *testing.T) {
+	gmail := config.EmailProviderRule{
+		Domains:         []string{"gmail.com", "googlemail.com"},
+		StripDots:       true,
+		StripPlus:       true,
+		CanonicalDomain: "gmail.com",
+	}
+
+	tests := []struct {
+		name    string
+		enabled bool
+		a, b    string
+		collide bool
+	}{
+		{name: "dots collide", enabled: true, a: "a.b@gmail.com", b: "ab@gmail.com", collide: true},
+		{name: "plus tag collides", enabled: true, a: "ab+spam@gmail.com", b: "ab@gmail.com", collide: true},
+		{name: "dots and plus collide", enabled: true, a: "A.B+x@Gmail.com", b: "ab@gmail.com", collide: true},
+		{name: "alias domain collides", enabled: true, a: "ab@googlemail.com", b: "ab@gmail.com", collide: true},
+		{name: "other provider keeps dots", enabled: true, a: "a.b@example.com", b: "ab@example.com", collide: false},
+		{name: "different users", enabled: true, a: "ab@gmail.com", b: "ac@gmail.com", collide: false},
+		{name: "disabled", enabled: false, a: "a.b+x@gmail.com", b: "ab@gmail.com", collide: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			n := New(config.EmailConfig{CanonicalizeAliases: tt.enabled, Providers: []config.EmailProviderRule{gmail}})
+			a, b := n.Canonical(tt.a), n.Canonical(tt.b)
+			if (a == b) != tt.collide {
+				t.Errorf("Canonical(%q) = %q, Canonical(%q) = %q, collide = %t, want %t", tt.a, a, tt.b, b, a == b, tt.collide)
+			}
+		})
+	}
+}
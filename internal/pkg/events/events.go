@@ -0,0 +1,89 @@
+package events
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+// Type identifies a user lifecycle event
+type Type string
+
+const (
+	UserCreated Type = "user.created"
+	UserUpdated Type = "user.updated"
+	UserDeleted Type = "user.deleted"
+)
+
+// Event describes a change to a user. It carries no personal data, only the ID and
+// the names of the changed fields, so subscribers re-read the user if they need it.
+type Event struct {
+	Type          Type      `json:"type"`
+	UserID        string    `json:"user_id"`
+	ChangedFields []string  `json:"changed_fields,omitempty"`
+	Time          time.Time `json:"time"`
+}
+
+// New creates an event of type t for userID, timestamped now
+func New(t Type, userID string, changedFields []string) Event {
+	return Event{
+		Type:          t,
+		UserID:        userID,
+		ChangedFields: changedFields,
+		Time:          time.Now().UTC(),
+	}
+}
+
+// Publisher delivers events to interested systems. Publishing happens after the change is
+// committed, so a failed publish doesn't undo it.
+type Publisher interface {
+	Publish(ctx context.Context, event Event) error
+}
+
+// BatchPublisher is a Publisher that can deliver several events in one round trip.
+// PublishBatch returns how many leading events were delivered before the first failure.
+type BatchPublisher interface {
+	Publisher
+	PublishBatch(ctx context.Context, events []Event) (int, error)
+}
+
+type noopPublisher struct{}
+
+// NewNoopPublisher creates a publisher that discards every event
+func NewNoopPublisher() Publisher {
+	return noopPublisher{}
+}
+
+func (noopPublisher) Publish(context.Context, Event) error {
+	return nil
+}
+
+// ErrBufferFull is returned when a ChannelPublisher's subscriber has fallen behind
+var ErrBufferFull = errors.New("event buffer is full")
+
+// ChannelPublisher delivers events to an in-process subscriber through a buffered
+// channel. Publish never blocks: when the buffer is full the event is dropped and
+// ErrBufferFull returned, so a slow subscriber can't stall requests.
+type ChannelPublisher struct {
+	ch chan Event
+}
+
+// NewChannelPublisher creates a publisher buffering up to size events
+func NewChannelPublisher(size int) *ChannelPublisher {
+	return &ChannelPublisher{ch: make(chan Event, size)}
+}
+
+// Publish queues event for the subscriber
+func (p *ChannelPublisher) Publish(ctx context.Context, event Event) error {
+	select {
+	case p.ch <- event:
+		return nil
+	default:
+		return ErrBufferFull
+	}
+}
+
+// Events returns the channel the subscriber reads events from
+func (p *ChannelPublisher) Events() <-chan Event {
+	return p.ch
+}
@@ -0,0 +1,132 @@
+package events
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/golang-standards/project-layout/internal/pkg/config"
+	"github.com/golang-standards/project-layout/internal/pkg/logger"
+	pb "github.com/golang-standards/project-layout/pkg/api/user/v1"
+	"github.com/segmentio/kafka-go"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// KafkaPublisher publishes events to a Kafka topic, keyed by user ID so each user's
+// events stay ordered within a partition. Asynchronous publishers batch writes in the
+// background: Publish returns once the event is queued, and delivery failures are logged
+// and counted instead of failing the request that caused them. Otherwise Publish waits
+// until the brokers acknowledge the event, as the outbox relay needs; a synchronous
+// writer flushes partial batches after syncBatchTimeout rather than kafka-go's one second
+// default, which would otherwise delay every relayed batch. Close flushes queued events.
+type KafkaPublisher struct {
+	writer  *kafka.Writer
+	encode  func(Event) ([]byte, error)
+	metrics *Metrics
+}
+
+// syncBatchTimeout is how long a synchronous writer waits to fill a batch
+const syncBatchTimeout = 10 * time.Millisecond
+
+var _ BatchPublisher = (*KafkaPublisher)(nil)
+
+// NewKafkaPublisher creates a publisher writing to cfg's brokers and topic
+func NewKafkaPublisher(cfg config.KafkaConfig, async bool, log logger.Logger, metrics *Metrics) (*KafkaPublisher, error) {
+	p := &KafkaPublisher{metrics: metrics}
+	switch cfg.Encoding {
+	case config.EncodingJSON:
+		p.encode = encodeJSON
+	case config.EncodingProtobuf:
+		p.encode = encodeProtobuf
+	default:
+		return nil, fmt.Errorf("unsupported event encoding %q", cfg.Encoding)
+	}
+
+	p.writer = &kafka.Writer{
+		Addr:         kafka.TCP(cfg.Brokers...),
+		Topic:        cfg.Topic,
+		Balancer:     &kafka.Hash{},
+		RequiredAcks: kafka.RequireAll,
+		Async:        async,
+	}
+	// Synchronous failures are returned by Publish and counted there
+	if !async {
+		p.writer.BatchTimeout = syncBatchTimeout
+	}
+	if async {
+		p.writer.Completion = func(messages []kafka.Message, err error) {
+			if err == nil {
+				return
+			}
+			metrics.failures.Add(float64(len(messages)))
+			log.Error("Failed to publish user events", "error", err, "count", len(messages), "topic", cfg.Topic)
+		}
+	}
+	return p, nil
+}
+
+// Publish queues event for delivery, or delivers it when the publisher is synchronous
+func (p *KafkaPublisher) Publish(ctx context.Context, event Event) error {
+	_, err := p.PublishBatch(ctx, []Event{event})
+	return err
+}
+
+// PublishBatch writes events in one call, so a synchronous writer sends them together
+// instead of waiting for an acknowledgement per event
+func (p *KafkaPublisher) PublishBatch(ctx context.Context, events []Event) (int, error) {
+	messages := make([]kafka.Message, len(events))
+	for i, event := range events {
+		value, err := p.encode(event)
+		if err != nil {
+			p.metrics.failures.Add(float64(len(events) - i))
+			return 0, fmt.Errorf("failed to encode event: %w", err)
+		}
+		messages[i] = kafka.Message{
+			Key:     []byte(event.UserID),
+			Value:   value,
+			Headers: []kafka.Header{{Key: "type", Value: []byte(event.Type)}},
+		}
+	}
+
+	err := p.writer.WriteMessages(ctx, messages...)
+	if err == nil {
+		return len(events), nil
+	}
+	p.metrics.failures.Add(float64(len(events)))
+	return deliveredPrefix(err), fmt.Errorf("failed to write events: %w", err)
+}
+
+// deliveredPrefix counts the leading messages a failed write still delivered
+func deliveredPrefix(err error) int {
+	var writeErrs kafka.WriteErrors
+	if !errors.As(err, &writeErrs) {
+		return 0
+	}
+	for i, err := range writeErrs {
+		if err != nil {
+			return i
+		}
+	}
+	return len(writeErrs)
+}
+
+// Close flushes queued events and releases the connections
+func (p *KafkaPublisher) Close() error {
+	return p.writer.Close()
+}
+
+func encodeJSON(event Event) ([]byte, error) {
+	return json.Marshal(event)
+}
+
+func encodeProtobuf(event Event) ([]byte, error) {
+	return proto.Marshal(&pb.UserEvent{
+		Type:          string(event.Type),
+		UserId:        event.UserID,
+		ChangedFields: event.ChangedFields,
+		Time:          timestamppb.New(event.Time),
+	})
+}
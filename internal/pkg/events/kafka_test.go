@@ -0,0 +1,63 @@
+package events
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/golang-standards/project-layout/internal/pkg/config"
+	"github.com/golang-standards/project-layout/internal/pkg/logger"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/segmentio/kafka-go"
+)
+
+func TestNewKafkaPublisherBatchTimeout(t *testing.T) {
+	cfg := config.KafkaConfig{Brokers: []string{"localhost:9092"}, Topic: "user-events", Encoding: config.EncodingJSON}
+
+	tests := []struct {
+		name  string
+		async bool
+		want  time.Duration
+	}{
+		{name: "synchronous", want: syncBatchTimeout},
+		{name: "asynchronous", async: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p, err := NewKafkaPublisher(cfg, tt.async, logger.NewNopLogger(), NewMetrics(prometheus.NewRegistry()))
+			if err != nil {
+				t.Fatalf("NewKafkaPublisher: %v", err)
+			}
+			defer p.Close()
+
+			if p.writer.BatchTimeout != tt.want {
+				t.Errorf("BatchTimeout = %v, want %v", p.writer.BatchTimeout, tt.want)
+			}
+		})
+	}
+}
+
+func TestDeliveredPrefix(t *testing.T) {
+	errBroker := errors.New("broker unavailable")
+
+	tests := []struct {
+		name string
+		err  error
+		want int
+	}{
+		{name: "not a write error", err: errBroker, want: 0},
+		{name: "first fails", err: kafka.WriteErrors{errBroker, nil, nil}, want: 0},
+		{name: "middle fails", err: kafka.WriteErrors{nil, nil, errBroker, nil}, want: 2},
+		{name: "wrapped", err: fmt.Errorf("write: %w", kafka.WriteErrors{nil, errBroker}), want: 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := deliveredPrefix(tt.err); got != tt.want {
+				t.Errorf("deliveredPrefix = %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,23 @@
+package events
+
+import "github.com/prometheus/client_golang/prometheus"
+
+// Metrics holds the Prometheus collectors for event publishing
+type Metrics struct {
+	failures prometheus.Counter
+}
+
+// NewMetrics creates the event publishing collectors and registers them with the given registerer
+func NewMetrics(reg prometheus.Registerer) *Metrics {
+	m := &Metrics{
+		failures: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: "user_service",
+			Name:      "event_publish_failures_total",
+			Help:      "Total number of user events that could not be published.",
+		}),
+	}
+
+	reg.MustRegister(m.failures)
+
+	return m
+}
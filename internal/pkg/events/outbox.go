@@ -0,0 +1,140 @@
+package events
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/golang-standards/project-layout/internal/pkg/logger"
+)
+
+// OutboxRecord is an event waiting in the outbox, with the ID used to mark it published
+type OutboxRecord struct {
+	ID    string
+	Event Event
+}
+
+// Outbox holds events committed together with the changes they describe until a Relay
+// publishes them
+type Outbox interface {
+	// Claim locks up to limit unpublished events, oldest first, skipping events another
+	// relay holds, and passes them to publish. The IDs publish returns are marked published
+	// before the claim is released, even when it also returns an error. Events that can't
+	// be decoded are flagged and never passed on. It returns how many events it claimed.
+	Claim(ctx context.Context, limit int, publish func(ctx context.Context, records []OutboxRecord) ([]string, error)) (int, error)
+	// Prune deletes events published before cutoff and returns how many it deleted
+	Prune(ctx context.Context, cutoff time.Time) (int64, error)
+}
+
+// Relay publishes events from an outbox. An event is marked published only after the
+// publisher accepts it, so every committed event is delivered at least once; consumers
+// must tolerate duplicates, e.g. after a crash between publishing and marking. Claims keep
+// several relays from publishing the same event concurrently.
+type Relay struct {
+	outbox    Outbox
+	publisher Publisher
+	batchSize int
+	retention time.Duration
+	log       logger.Logger
+	now       func() time.Time
+	lastPrune time.Time
+}
+
+// pruneInterval is how often the relay deletes published events past the retention period
+const pruneInterval = time.Hour
+
+// NewRelay creates a relay publishing up to batchSize events per outbox read. Published
+// events are deleted once they are older than retention; zero keeps them forever.
+func NewRelay(outbox Outbox, publisher Publisher, batchSize int, retention time.Duration, log logger.Logger) *Relay {
+	return &Relay{
+		outbox:    outbox,
+		publisher: publisher,
+		batchSize: batchSize,
+		retention: retention,
+		log:       log,
+		now:       time.Now,
+	}
+}
+
+// Run flushes the outbox every interval until ctx is done, pruning published events
+// along the way
+func (r *Relay) Run(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		if err := r.Flush(ctx); err != nil && ctx.Err() == nil {
+			r.log.Error("Failed to relay outbox events", "error", err)
+		}
+		if err := r.prune(ctx); err != nil && ctx.Err() == nil {
+			r.log.Error("Failed to prune outbox events", "error", err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// Flush publishes pending events in the order they were stored until none remain. It stops
+// at the first event that fails to publish, so later events don't overtake it.
+func (r *Relay) Flush(ctx context.Context) error {
+	for {
+		claimed, err := r.outbox.Claim(ctx, r.batchSize, r.publish)
+		if err != nil {
+			return err
+		}
+		if claimed < r.batchSize {
+			return nil
+		}
+	}
+}
+
+// publish sends records in order, returning the IDs of those published before any failure.
+// A BatchPublisher gets the whole batch in one call.
+func (r *Relay) publish(ctx context.Context, records []OutboxRecord) ([]string, error) {
+	if batcher, ok := r.publisher.(BatchPublisher); ok {
+		batch := make([]Event, len(records))
+		for i, record := range records {
+			batch[i] = record.Event
+		}
+		delivered, err := batcher.PublishBatch(ctx, batch)
+		published := make([]string, delivered)
+		for i := range published {
+			published[i] = records[i].ID
+		}
+		if err != nil {
+			return published, fmt.Errorf("failed to publish outbox events: %w", err)
+		}
+		return published, nil
+	}
+
+	published := make([]string, 0, len(records))
+	for _, record := range records {
+		if err := r.publisher.Publish(ctx, record.Event); err != nil {
+			return published, fmt.Errorf("failed to publish outbox event: %w", err)
+		}
+		published = append(published, record.ID)
+	}
+	return published, nil
+}
+
+// prune deletes published events older than the retention period, at most once per
+// pruneInterval
+func (r *Relay) prune(ctx context.Context) error {
+	if r.retention <= 0 || r.now().Sub(r.lastPrune) < pruneInterval {
+		return nil
+	}
+	r.lastPrune = r.now()
+
+	deleted, err := r.outbox.Prune(ctx, r.now().Add(-r.retention))
+	if err != nil {
+		return err
+	}
+	if deleted > 0 {
+		r.log.Info("Pruned published outbox events", "count", deleted)
+	}
+	return nil
+}
@@ -0,0 +1,177 @@
+package events
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/golang-standards/project-layout/internal/pkg/logger"
+)
+
+// memoryOutbox is an Outbox over a slice, recording prune cutoffs
+type memoryOutbox struct {
+	pending   []OutboxRecord
+	published []string
+	cutoffs   []time.Time
+}
+
+func (o *memoryOutbox) Claim(ctx context.Context, limit int, publish func(ctx context.Context, records []OutboxRecord) ([]string, error)) (int, error) {
+	batch := o.pending
+	if len(batch) > limit {
+		batch = batch[:limit]
+	}
+	if len(batch) == 0 {
+		return 0, nil
+	}
+	ids, err := publish(ctx, batch)
+	o.published = append(o.published, ids...)
+	o.pending = o.pending[len(ids):]
+	return len(batch), err
+}
+
+func (o *memoryOutbox) Prune(ctx context.Context, cutoff time.Time) (int64, error) {
+	o.cutoffs = append(o.cutoffs, cutoff)
+	return 0, nil
+}
+
+// failingPublisher rejects events for one user
+type failingPublisher struct {
+	failUserID string
+}
+
+func (p failingPublisher) Publish(ctx context.Context, event Event) error {
+	if event.UserID == p.failUserID {
+		return errors.New("broker unavailable")
+	}
+	return nil
+}
+
+// batchPublisher delivers up to deliver events per batch, recording batch sizes
+type batchPublisher struct {
+	deliver int
+	batches []int
+}
+
+func (p *batchPublisher) Publish(ctx context.Context, event Event) error {
+	return errors.New("Publish called on a batch publisher")
+}
+
+func (p *batchPublisher) PublishBatch(ctx context.Context, events []Event) (int, error) {
+	p.batches = append(p.batches, len(events))
+	if len(events) > p.deliver {
+		return p.deliver, errors.New("broker unavailable")
+	}
+	return len(events), nil
+}
+
+func TestRelayFlush(t *testing.T) {
+	tests := []struct {
+		name          string
+		events        int
+		failUserID    string
+		wantPublished int
+		wantErr       bool
+	}{
+		{name: "empty outbox"},
+		{name: "several batches", events: 7, wantPublished: 7},
+		{name: "stops at a failure", events: 7, failUserID: "u4", wantPublished: 4, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			outbox := &memoryOutbox{}
+			for i := 0; i < tt.events; i++ {
+				outbox.pending = append(outbox.pending, OutboxRecord{ID: fmt.Sprint(i), Event: Event{UserID: fmt.Sprintf("u%d", i)}})
+			}
+			relay := NewRelay(outbox, failingPublisher{failUserID: tt.failUserID}, 3, 0, logger.NewNopLogger())
+
+			err := relay.Flush(context.Background())
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("err = %v, wantErr %v", err, tt.wantErr)
+			}
+			if len(outbox.published) != tt.wantPublished {
+				t.Errorf("published %d events, want %d", len(outbox.published), tt.wantPublished)
+			}
+		})
+	}
+}
+
+func TestRelayPrune(t *testing.T) {
+	now := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name        string
+		retention   time.Duration
+		advance     []time.Duration
+		wantCutoffs []time.Time
+	}{
+		{name: "disabled", advance: []time.Duration{0, 2 * time.Hour}},
+		{
+			name:        "once per interval",
+			retention:   24 * time.Hour,
+			advance:     []time.Duration{0, time.Minute, pruneInterval},
+			wantCutoffs: []time.Time{now.Add(-24 * time.Hour), now.Add(pruneInterval + time.Minute - 24*time.Hour)},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			outbox := &memoryOutbox{}
+			relay := NewRelay(outbox, NewNoopPublisher(), 10, tt.retention, logger.NewNopLogger())
+			clock := now
+			relay.now = func() time.Time { return clock }
+
+			for _, d := range tt.advance {
+				clock = clock.Add(d)
+				if err := relay.prune(context.Background()); err != nil {
+					t.Fatalf("prune: %v", err)
+				}
+			}
+			if len(outbox.cutoffs) != len(tt.wantCutoffs) {
+				t.Fatalf("pruned %d times, want %d", len(outbox.cutoffs), len(tt.wantCutoffs))
+			}
+			for i, want := range tt.wantCutoffs {
+				if !outbox.cutoffs[i].Equal(want) {
+					t.Errorf("cutoff %d = %v, want %v", i, outbox.cutoffs[i], want)
+				}
+			}
+		})
+	}
+}
+
+func TestRelayFlushBatches(t *testing.T) {
+	tests := []struct {
+		name          string
+		deliver       int
+		wantBatches   []int
+		wantPublished int
+		wantErr       bool
+	}{
+		{name: "one call per batch", deliver: 3, wantBatches: []int{3, 3, 1}, wantPublished: 7},
+		{name: "keeps the delivered prefix", deliver: 2, wantBatches: []int{3}, wantPublished: 2, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			outbox := &memoryOutbox{}
+			for i := 0; i < 7; i++ {
+				outbox.pending = append(outbox.pending, OutboxRecord{ID: fmt.Sprint(i)})
+			}
+			publisher := &batchPublisher{deliver: tt.deliver}
+			relay := NewRelay(outbox, publisher, 3, 0, logger.NewNopLogger())
+
+			err := relay.Flush(context.Background())
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("err = %v, wantErr %v", err, tt.wantErr)
+			}
+			if fmt.Sprint(publisher.batches) != fmt.Sprint(tt.wantBatches) {
+				t.Errorf("batches = %v, want %v", publisher.batches, tt.wantBatches)
+			}
+			if len(outbox.published) != tt.wantPublished {
+				t.Errorf("published %d events, want %d", len(outbox.published), tt.wantPublished)
+			}
+		})
+	}
+}
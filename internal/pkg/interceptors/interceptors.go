@@ -0,0 +1,124 @@
+package interceptors
+
+import (
+	"fmt"
+
+	"google.golang.org/grpc"
+)
+
+// Stage is a position in the interceptor chain. Interceptors run in stage order,
+// outermost first:
+//
+//   - recovery turns panics in every later stage into Internal errors
+//   - drain and concurrency reject requests before any work is done for them
+//   - request_id and tracing establish the identifiers later stages log and propagate
+//   - timeout bounds everything after it, including the handler
+//   - authn sets the principal from the caller's token
+//   - impersonation settles the principal before it is logged
+//   - logging and metrics observe every outcome below them, including auth and rate limit rejections
+//   - auth rejects callers without the required role before they consume rate limit tokens
+//   - rate_limit throttles authorized callers
+//   - dedup and database run closest to the handler, since they depend on its result
+type Stage int
+
+const (
+	StageRecovery Stage = iota
+	StageDrain
+	StageConcurrency
+	StageRequestID
+	StageTracing
+	StageTimeout
+	StageAuthn
+	StageImpersonation
+	StageLogging
+	StageMetrics
+	StageAuth
+	StageRateLimit
+	StageDedup
+	StageDatabase
+)
+
+// stageNames are the names stages are configured by
+var stageNames = map[Stage]string{
+	StageRecovery:      "recovery",
+	StageDrain:         "drain",
+	StageConcurrency:   "concurrency",
+	StageRequestID:     "request_id",
+	StageTracing:       "tracing",
+	StageTimeout:       "timeout",
+	StageAuthn:         "authn",
+	StageImpersonation: "impersonation",
+	StageLogging:       "logging",
+	StageMetrics:       "metrics",
+	StageAuth:          "auth",
+	StageRateLimit:     "rate_limit",
+	StageDedup:         "dedup",
+	StageDatabase:      "database",
+}
+
+func (s Stage) String() string {
+	if name, ok := stageNames[s]; ok {
+		return name
+	}
+	return fmt.Sprintf("stage(%d)", int(s))
+}
+
+// Chain assembles unary and stream interceptors in stage order, regardless of the
+// order they are added in, leaving out disabled stages
+type Chain struct {
+	disabled map[Stage]bool
+	unary    map[Stage][]grpc.UnaryServerInterceptor
+	stream   map[Stage][]grpc.StreamServerInterceptor
+}
+
+// NewChain creates a chain with the named stages disabled; unknown names are an error
+func NewChain(disabled []string) (*Chain, error) {
+	byName := make(map[string]Stage, len(stageNames))
+	for stage, name := range stageNames {
+		byName[name] = stage
+	}
+
+	c := &Chain{
+		disabled: make(map[Stage]bool, len(disabled)),
+		unary:    make(map[Stage][]grpc.UnaryServerInterceptor),
+		stream:   make(map[Stage][]grpc.StreamServerInterceptor),
+	}
+	for _, name := range disabled {
+		stage, ok := byName[name]
+		if !ok {
+			return nil, fmt.Errorf("unknown interceptor stage %q", name)
+		}
+		c.disabled[stage] = true
+	}
+	return c, nil
+}
+
+// Unary adds a unary interceptor at stage. Interceptors added to the same stage run
+// in the order they were added.
+func (c *Chain) Unary(stage Stage, interceptor grpc.UnaryServerInterceptor) *Chain {
+	c.unary[stage] = append(c.unary[stage], interceptor)
+	return c
+}
+
+// Stream adds a stream interceptor at stage
+func (c *Chain) Stream(stage Stage, interceptor grpc.StreamServerInterceptor) *Chain {
+	c.stream[stage] = append(c.stream[stage], interceptor)
+	return c
+}
+
+// ServerOptions returns the server options installing the chain
+func (c *Chain) ServerOptions() []grpc.ServerOption {
+	var unary []grpc.UnaryServerInterceptor
+	var stream []grpc.StreamServerInterceptor
+	for stage := StageRecovery; stage <= StageDatabase; stage++ {
+		if c.disabled[stage] {
+			continue
+		}
+		unary = append(unary, c.unary[stage]...)
+		stream = append(stream, c.stream[stage]...)
+	}
+	return []grpc.ServerOption{
+		grpc.ChainUnaryInterceptor(unary...),
+		grpc.ChainStreamInterceptor(stream...),
+	}
+}
@@ -0,0 +1,22 @@
+package interceptors
+
+import (
+	"context"
+
+	"google.golang.org/grpc"
+)
+
+// WrapServerStream returns ss with its context replaced by ctx, for stream
+// interceptors that add values to the context before calling the handler
+func WrapServerStream(ctx context.Context, ss grpc.ServerStream) grpc.ServerStream {
+	return &wrappedStream{ServerStream: ss, ctx: ctx}
+}
+
+type wrappedStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (s *wrappedStream) Context() context.Context {
+	return s.ctx
+}
@@ -0,0 +1,20 @@
+package listener
+
+import (
+	"context"
+	"errors"
+	"net"
+)
+
+// ErrReusePortUnsupported is returned when SO_REUSEPORT is requested on a platform without it
+var ErrReusePortUnsupported = errors.New("SO_REUSEPORT is not supported on this platform")
+
+// Listen opens a TCP listener on addr. With reusePort the socket is bound with SO_REUSEPORT,
+// so a new process can bind the same port and take over while the old one drains.
+func Listen(ctx context.Context, addr string, reusePort bool) (net.Listener, error) {
+	var lc net.ListenConfig
+	if reusePort {
+		lc.Control = controlReusePort
+	}
+	return lc.Listen(ctx, "tcp", addr)
+}
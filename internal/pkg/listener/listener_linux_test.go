@@ -0,0 +1,64 @@
+//go:build linux
+
+package listener
+
+import (
+	"context"
+	"net"
+	"testing"
+)
+
+func TestListenReusePort(t *testing.T) {
+	tests := []struct {
+		name      string
+		reusePort bool
+		wantErr   bool
+	}{
+		{name: "second listener shares the port", reusePort: true},
+		{name: "second listener is refused without reuse", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			first, err := Listen(context.Background(), "127.0.0.1:0", tt.reusePort)
+			if err != nil {
+				t.Fatalf("first Listen: %v", err)
+			}
+			defer first.Close()
+
+			addr := first.Addr().String()
+			second, err := Listen(context.Background(), addr, tt.reusePort)
+			if tt.wantErr {
+				if err == nil {
+					second.Close()
+					t.Fatalf("second Listen on %s succeeded, want an error", addr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("second Listen on %s: %v", addr, err)
+			}
+			defer second.Close()
+
+			// Closing the old listener leaves the new one accepting connections, as
+			// during a handover
+			first.Close()
+			accepted := make(chan error, 1)
+			go func() {
+				conn, err := second.Accept()
+				if err == nil {
+					conn.Close()
+				}
+				accepted <- err
+			}()
+			conn, err := net.Dial("tcp", addr)
+			if err != nil {
+				t.Fatalf("dial after handover: %v", err)
+			}
+			conn.Close()
+			if err := <-accepted; err != nil {
+				t.Fatalf("Accept: %v", err)
+			}
+		})
+	}
+}
@@ -0,0 +1,10 @@
+//go:build !(linux || darwin || dragonfly || freebsd || netbsd || openbsd)
+
+package listener
+
+import "syscall"
+
+// controlReusePort fails because SO_REUSEPORT is unavailable on this platform
+func controlReusePort(_, _ string, _ syscall.RawConn) error {
+	return ErrReusePortUnsupported
+}
@@ -0,0 +1,35 @@
+package logger
+
+import (
+	"context"
+
+	"go.uber.org/zap"
+)
+
+type loggerKey struct{}
+
+// NewNopLogger creates a logger that discards all output
+func NewNopLogger() Logger {
+	return &logger{
+		zap:   zap.NewNop().Sugar(),
+		level: zap.NewAtomicLevel(),
+	}
+}
+
+// WithContext returns a copy of ctx carrying the request-scoped logger
+func WithContext(ctx context.Context, l Logger) context.Context {
+	return context.WithValue(ctx, loggerKey{}, l)
+}
+
+// FromContext returns the request-scoped logger, or a no-op logger when none is set
+func FromContext(ctx context.Context) Logger {
+	return FromContextOr(ctx, NewNopLogger())
+}
+
+// FromContextOr returns the request-scoped logger, or fallback when none is set
+func FromContextOr(ctx context.Context, fallback Logger) Logger {
+	if l, ok := ctx.Value(loggerKey{}).(Logger); ok {
+		return l
+	}
+	return fallback
+}
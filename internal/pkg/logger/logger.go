@@ -2,11 +2,16 @@ package logger
 
 import (
 	"context"
+	"fmt"
 
+	"github.com/golang-standards/project-layout/internal/pkg/auth"
+	"github.com/golang-standards/project-layout/internal/pkg/config"
+	"github.com/golang-standards/project-layout/internal/pkg/interceptors"
+	"github.com/golang-standards/project-layout/internal/pkg/requestid"
+	"go.opentelemetry.io/otel/trace"
 	"go.uber.org/zap"
 	"go.uber.org/zap/zapcore"
 	"google.golang.org/grpc"
-	"google.golang.org/grpc/metadata"
 )
 
 // Logger is a wrapper around zap logger
@@ -17,11 +22,14 @@ type Logger interface {
 	Error(msg string, keysAndValues ...interface{})
 	Fatal(msg string, keysAndValues ...interface{})
 	With(keysAndValues ...interface{}) Logger
+	// SetLevel changes the minimum level of this logger and every logger derived from it
+	SetLevel(level string) error
 	Sync() error
 }
 
 type logger struct {
-	zap *zap.SugaredLogger
+	zap   *zap.SugaredLogger
+	level zap.AtomicLevel
 }
 
 // NewLogger creates a new logger instance
@@ -36,10 +44,45 @@ func NewLogger() Logger {
 	}
 
 	return &logger{
-		zap: zapLogger.Sugar(),
+		zap:   zapLogger.Sugar(),
+		level: config.Level,
 	}
 }
 
+// NewLoggerFromConfig creates a logger with the level and format from configuration.
+// Format is "json" or "console"; unknown levels or formats return an error.
+func NewLoggerFromConfig(cfg config.LoggerConfig) (Logger, error) {
+	level, err := zapcore.ParseLevel(cfg.Level)
+	if err != nil {
+		return nil, fmt.Errorf("invalid log level %q: %w", cfg.Level, err)
+	}
+
+	zapConfig := zap.NewProductionConfig()
+	zapConfig.Level = zap.NewAtomicLevelAt(level)
+	zapConfig.EncoderConfig.TimeKey = "timestamp"
+	zapConfig.EncoderConfig.EncodeTime = zapcore.ISO8601TimeEncoder
+
+	switch cfg.Format {
+	case "json":
+		zapConfig.Encoding = "json"
+	case "console":
+		zapConfig.Encoding = "console"
+		zapConfig.EncoderConfig.EncodeLevel = zapcore.CapitalColorLevelEncoder
+	default:
+		return nil, fmt.Errorf("invalid log format %q", cfg.Format)
+	}
+
+	zapLogger, err := zapConfig.Build()
+	if err != nil {
+		return nil, fmt.Errorf("failed to build logger: %w", err)
+	}
+
+	return &logger{
+		zap:   zapLogger.Sugar(),
+		level: zapConfig.Level,
+	}, nil
+}
+
 // NewDevelopmentLogger creates a new development logger
 func NewDevelopmentLogger() Logger {
 	config := zap.NewDevelopmentConfig()
@@ -51,7 +94,8 @@ func NewDevelopmentLogger() Logger {
 	}
 
 	return &logger{
-		zap: zapLogger.Sugar(),
+		zap:   zapLogger.Sugar(),
+		level: config.Level,
 	}
 }
 
@@ -77,38 +121,81 @@ func (l *logger) Fatal(msg string, keysAndValues ...interface{}) {
 
 func (l *logger) With(keysAndValues ...interface{}) Logger {
 	return &logger{
-		zap: l.zap.With(keysAndValues...),
+		zap:   l.zap.With(keysAndValues...),
+		level: l.level,
+	}
+}
+
+func (l *logger) SetLevel(level string) error {
+	parsed, err := zapcore.ParseLevel(level)
+	if err != nil {
+		return fmt.Errorf("invalid log level %q: %w", level, err)
 	}
+	l.level.SetLevel(parsed)
+	return nil
 }
 
 func (l *logger) Sync() error {
 	return l.zap.Sync()
 }
 
-// UnaryServerInterceptor returns a new unary server interceptor for logging
-func UnaryServerInterceptor(logger Logger) grpc.UnaryServerInterceptor {
+// UnaryServerInterceptor returns a new unary server interceptor for logging.
+// With logPayloads, request and response messages are also logged at debug level as JSON,
+// with sensitive fields redacted and each payload truncated to maxPayloadBytes.
+func UnaryServerInterceptor(logger Logger, logPayloads bool, maxPayloadBytes int) grpc.UnaryServerInterceptor {
 	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
-		// Extract metadata from context
-		md, _ := metadata.FromIncomingContext(ctx)
-		requestID := ""
-		if vals := md.Get("x-request-id"); len(vals) > 0 {
-			requestID = vals[0]
+		log := requestLogger(ctx, logger, info.FullMethod)
+		if logPayloads {
+			log.Debug("gRPC request started", "request", payloadJSON(req, maxPayloadBytes))
+		} else {
+			log.Debug("gRPC request started")
 		}
 
-		// Create logger with context
-		log := logger.With("method", info.FullMethod, "request_id", requestID)
-		log.Debug("gRPC request started")
-
-		// Call handler
-		resp, err := handler(ctx, req)
+		// Call handler with the enriched logger available to downstream layers
+		resp, err := handler(WithContext(ctx, log), req)
 
 		// Log result
-		if err != nil {
+		switch {
+		case err != nil:
 			log.Error("gRPC request failed", "error", err)
-		} else {
+		case logPayloads:
+			log.Debug("gRPC request completed", "response", payloadJSON(resp, maxPayloadBytes))
+		default:
 			log.Debug("gRPC request completed")
 		}
 
 		return resp, err
 	}
 }
+
+// StreamServerInterceptor returns a new stream server interceptor for logging. Stream
+// messages are never logged, only how the stream ended.
+func StreamServerInterceptor(logger Logger) grpc.StreamServerInterceptor {
+	return func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		ctx := ss.Context()
+		log := requestLogger(ctx, logger, info.FullMethod)
+		log.Debug("gRPC stream started")
+
+		err := handler(srv, interceptors.WrapServerStream(WithContext(ctx, log), ss))
+		if err != nil {
+			log.Error("gRPC stream failed", "error", err)
+		} else {
+			log.Debug("gRPC stream completed")
+		}
+
+		return err
+	}
+}
+
+// requestLogger returns logger annotated with the method, request ID, impersonation and
+// trace of the request in ctx
+func requestLogger(ctx context.Context, logger Logger, method string) Logger {
+	log := logger.With("method", method, "request_id", requestid.FromContext(ctx))
+	if principal, ok := auth.PrincipalFromContext(ctx); ok && principal.IsImpersonated() {
+		log = log.With("user_id", principal.UserID, "impersonator_id", principal.ImpersonatorID)
+	}
+	if sc := trace.SpanContextFromContext(ctx); sc.IsValid() {
+		log = log.With("trace_id", sc.TraceID().String(), "span_id", sc.SpanID().String())
+	}
+	return log
+}
@@ -0,0 +1,48 @@
+package logger
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/golang-standards/project-layout/internal/pkg/requestid"
+	"google.golang.org/grpc"
+)
+
+// fakeStream is a server stream carrying only a context
+type fakeStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (s *fakeStream) Context() context.Context { return s.ctx }
+
+func TestStreamServerInterceptor(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+	}{
+		{name: "completed"},
+		{name: "failed", err: errors.New("boom")},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := requestid.NewContext(context.Background(), "req-123")
+
+			err := StreamServerInterceptor(NewNopLogger())(nil, &fakeStream{ctx: ctx}, &grpc.StreamServerInfo{FullMethod: "/user.v1.UserService/StreamUsers"},
+				func(srv interface{}, ss grpc.ServerStream) error {
+					if _, ok := ss.Context().Value(loggerKey{}).(Logger); !ok {
+						t.Error("expected the request logger in the stream context")
+					}
+					if id := requestid.FromContext(ss.Context()); id != "req-123" {
+						t.Errorf("request ID = %q, want req-123", id)
+					}
+					return tt.err
+				})
+			if !errors.Is(err, tt.err) {
+				t.Errorf("err = %v, want %v", err, tt.err)
+			}
+		})
+	}
+}
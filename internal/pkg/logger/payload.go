@@ -0,0 +1,78 @@
+package logger
+
+import (
+	"fmt"
+	"strings"
+
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+)
+
+// sensitiveFields are proto field names whose values never reach the logs, in any message
+var sensitiveFields = map[protoreflect.Name]bool{
+	"password":         true,
+	"current_password": true,
+	"new_password":     true,
+	"token":            true,
+}
+
+// redactedValue replaces sensitive string values in logged payloads
+const redactedValue = "[REDACTED]"
+
+// payloadJSON renders msg as JSON with sensitive fields redacted. Output longer than
+// maxBytes is truncated; zero or less means no limit.
+func payloadJSON(msg interface{}, maxBytes int) string {
+	m, ok := msg.(proto.Message)
+	if !ok || m == nil {
+		return fmt.Sprintf("%T", msg)
+	}
+
+	clone := proto.Clone(m)
+	redact(clone.ProtoReflect())
+	data, err := protojson.Marshal(clone)
+	if err != nil {
+		return fmt.Sprintf("<unencodable %T: %v>", msg, err)
+	}
+
+	if maxBytes > 0 && len(data) > maxBytes {
+		return strings.ToValidUTF8(string(data[:maxBytes]), "") + "...(truncated)"
+	}
+	return string(data)
+}
+
+// redact replaces sensitive fields in m and its nested messages
+func redact(m protoreflect.Message) {
+	// Collect first; changing fields while ranging over them is not allowed
+	var fields []protoreflect.FieldDescriptor
+	m.Range(func(fd protoreflect.FieldDescriptor, _ protoreflect.Value) bool {
+		fields = append(fields, fd)
+		return true
+	})
+
+	for _, fd := range fields {
+		v := m.Get(fd)
+		switch {
+		case sensitiveFields[fd.Name()]:
+			if fd.Kind() == protoreflect.StringKind && fd.Cardinality() != protoreflect.Repeated {
+				m.Set(fd, protoreflect.ValueOfString(redactedValue))
+			} else {
+				m.Clear(fd)
+			}
+		case fd.IsMap():
+			if fd.MapValue().Message() != nil {
+				v.Map().Range(func(_ protoreflect.MapKey, mv protoreflect.Value) bool {
+					redact(mv.Message())
+					return true
+				})
+			}
+		case fd.Message() != nil && fd.IsList():
+			list := v.List()
+			for i := 0; i < list.Len(); i++ {
+				redact(list.Get(i).Message())
+			}
+		case fd.Message() != nil:
+			redact(v.Message())
+		}
+	}
+}
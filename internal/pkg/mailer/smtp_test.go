@@ -0,0 +1,47 @@
+package mailer
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/golang-standards/project-layout/internal/pkg/config"
+)
+
+func TestSendRejectsHeaderInjection(t *testing.T) {
+	// An unroutable address: a send that got past validation would fail to connect instead
+	m := NewSMTPMailer(config.SMTPConfig{Host: "127.0.0.1", Port: 1, From: "no-reply@example.com"})
+
+	tests := []struct {
+		name    string
+		to      string
+		subject string
+	}{
+		{name: "recipient", to: "jane@example.com\r\nBcc: eve@example.com", subject: "Hi"},
+		{name: "subject", to: "jane@example.com", subject: "Hi\nBcc: eve@example.com"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := m.Send(context.Background(), tt.to, tt.subject, "body")
+			if err == nil || !strings.Contains(err.Error(), "line breaks") {
+				t.Fatalf("err = %v, want a line break error", err)
+			}
+		})
+	}
+}
+
+func TestMessage(t *testing.T) {
+	msg := string(message("no-reply@example.com", "jane@example.com", "Hello", "line one\nline two\n"))
+
+	for _, want := range []string{
+		"From: no-reply@example.com\r\n",
+		"To: jane@example.com\r\n",
+		"Subject: Hello\r\n",
+		"Content-Type: text/plain; charset=UTF-8\r\n\r\nline one\r\nline two\r\n",
+	} {
+		if !strings.Contains(msg, want) {
+			t.Errorf("message %q does not contain %q", msg, want)
+		}
+	}
+}
@@ -0,0 +1,108 @@
+package metrics
+
+import (
+	"context"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+const namespace = "user_service"
+
+// Metrics holds the Prometheus collectors for gRPC requests
+type Metrics struct {
+	requests *prometheus.CounterVec
+	errors   *prometheus.CounterVec
+	latency  *prometheus.HistogramVec
+	inFlight prometheus.Gauge
+}
+
+// NewMetrics creates the gRPC collectors and registers them with the given registerer.
+// buckets are the latency histogram bounds in seconds; empty uses prometheus.DefBuckets.
+func NewMetrics(reg prometheus.Registerer, buckets []float64) *Metrics {
+	if len(buckets) == 0 {
+		buckets = prometheus.DefBuckets
+	}
+
+	m := &Metrics{
+		requests: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "grpc_requests_total",
+			Help:      "Total number of gRPC requests handled.",
+		}, []string{"method", "code"}),
+		errors: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "grpc_errors_total",
+			Help:      "Total number of gRPC requests that returned an error.",
+		}, []string{"method", "code"}),
+		latency: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Name:      "grpc_request_duration_seconds",
+			Help:      "Latency of gRPC requests in seconds.",
+			Buckets:   buckets,
+		}, []string{"method", "code"}),
+		inFlight: prometheus.NewGauge(prometheus.GaugeOpts{
+			Namespace: namespace,
+			Name:      "grpc_requests_in_flight",
+			Help:      "Number of gRPC requests currently being handled.",
+		}),
+	}
+
+	reg.MustRegister(m.requests, m.errors, m.latency, m.inFlight)
+
+	return m
+}
+
+// RegisterBuildInfo registers a gauge, always 1, labeled with the running build so
+// dashboards can correlate metrics with releases
+func RegisterBuildInfo(reg prometheus.Registerer, version, gitCommit, buildTime string) {
+	buildInfo := prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Name:      "build_info",
+		Help:      "Build information of the running service; always 1.",
+	}, []string{"version", "git_commit", "build_time"})
+	reg.MustRegister(buildInfo)
+	buildInfo.WithLabelValues(version, gitCommit, buildTime).Set(1)
+}
+
+// UnaryServerInterceptor returns a new unary server interceptor recording request metrics
+func UnaryServerInterceptor(m *Metrics) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		m.inFlight.Inc()
+		defer m.inFlight.Dec()
+
+		start := time.Now()
+		resp, err := handler(ctx, req)
+		m.observe(info.FullMethod, start, err)
+
+		return resp, err
+	}
+}
+
+// StreamServerInterceptor returns a new stream server interceptor recording stream
+// metrics; the latency is the stream's whole duration
+func StreamServerInterceptor(m *Metrics) grpc.StreamServerInterceptor {
+	return func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		m.inFlight.Inc()
+		defer m.inFlight.Dec()
+
+		start := time.Now()
+		err := handler(srv, ss)
+		m.observe(info.FullMethod, start, err)
+
+		return err
+	}
+}
+
+// observe records a finished request
+func (m *Metrics) observe(method string, start time.Time, err error) {
+	code := status.Code(err).String()
+	m.requests.WithLabelValues(method, code).Inc()
+	m.latency.WithLabelValues(method, code).Observe(time.Since(start).Seconds())
+	if status.Code(err) != codes.OK {
+		m.errors.WithLabelValues(method, code).Inc()
+	}
+}
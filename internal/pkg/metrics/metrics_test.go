@@ -0,0 +1,58 @@
+package metrics
+
+import (
+	"context"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// fakeStream is a server stream carrying only a context
+type fakeStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (s *fakeStream) Context() context.Context { return s.ctx }
+
+func TestStreamServerInterceptor(t *testing.T) {
+	const method = "/user.v1.UserService/StreamUsers"
+
+	tests := []struct {
+		name       string
+		err        error
+		wantCode   string
+		wantErrors float64
+	}{
+		{name: "completed", wantCode: "OK"},
+		{name: "failed", err: status.Error(codes.Unavailable, "down"), wantCode: "Unavailable", wantErrors: 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := NewMetrics(prometheus.NewRegistry(), nil)
+
+			_ = StreamServerInterceptor(m)(nil, &fakeStream{ctx: context.Background()}, &grpc.StreamServerInfo{FullMethod: method},
+				func(srv interface{}, ss grpc.ServerStream) error {
+					if got := testutil.ToFloat64(m.inFlight); got != 1 {
+						t.Errorf("in flight during the stream = %v, want 1", got)
+					}
+					return tt.err
+				})
+
+			if got := testutil.ToFloat64(m.requests.WithLabelValues(method, tt.wantCode)); got != 1 {
+				t.Errorf("requests = %v, want 1", got)
+			}
+			if got := testutil.ToFloat64(m.errors.WithLabelValues(method, tt.wantCode)); got != tt.wantErrors {
+				t.Errorf("errors = %v, want %v", got, tt.wantErrors)
+			}
+			if got := testutil.ToFloat64(m.inFlight); got != 0 {
+				t.Errorf("in flight after the stream = %v, want 0", got)
+			}
+		})
+	}
+}
@@ -0,0 +1,74 @@
+package metrics
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// PoolMetrics exposes database connection pool saturation
+type PoolMetrics struct {
+	utilization   prometheus.Gauge
+	inUse         prometheus.Gauge
+	waits         prometheus.Counter
+	lastWaitCount int64
+}
+
+// NewPoolMetrics creates the pool collectors and registers them with the given registerer
+func NewPoolMetrics(reg prometheus.Registerer) *PoolMetrics {
+	p := &PoolMetrics{
+		utilization: prometheus.NewGauge(prometheus.GaugeOpts{
+			Namespace: namespace,
+			Name:      "db_pool_utilization_ratio",
+			Help:      "Ratio of in-use connections to the maximum open connections (0 when unlimited).",
+		}),
+		inUse: prometheus.NewGauge(prometheus.GaugeOpts{
+			Namespace: namespace,
+			Name:      "db_pool_in_use_connections",
+			Help:      "Number of database connections currently in use.",
+		}),
+		waits: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "db_pool_wait_total",
+			Help:      "Total number of times a query waited for a free connection.",
+		}),
+	}
+
+	reg.MustRegister(p.utilization, p.inUse, p.waits)
+
+	return p
+}
+
+// Observe updates the collectors from a pool statistics snapshot
+func (p *PoolMetrics) Observe(stats sql.DBStats) {
+	p.inUse.Set(float64(stats.InUse))
+
+	if stats.MaxOpenConnections > 0 {
+		p.utilization.Set(float64(stats.InUse) / float64(stats.MaxOpenConnections))
+	} else {
+		p.utilization.Set(0)
+	}
+
+	if delta := stats.WaitCount - p.lastWaitCount; delta > 0 {
+		p.waits.Add(float64(delta))
+	}
+	p.lastWaitCount = stats.WaitCount
+}
+
+// Run samples stats every interval until ctx is done
+func (p *PoolMetrics) Run(ctx context.Context, interval time.Duration, stats func() sql.DBStats) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		p.Observe(stats())
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
@@ -0,0 +1,75 @@
+package metrics
+
+import (
+	"context"
+	"database/sql"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+)
+
+func TestPoolMetricsObserve(t *testing.T) {
+	// Each snapshot is observed in turn by the same collectors
+	tests := []struct {
+		name            string
+		stats           sql.DBStats
+		wantInUse       float64
+		wantUtilization float64
+		wantWaits       float64
+	}{
+		{name: "idle", stats: sql.DBStats{MaxOpenConnections: 10}},
+		{name: "half used", stats: sql.DBStats{MaxOpenConnections: 10, InUse: 5, WaitCount: 2}, wantInUse: 5, wantUtilization: 0.5, wantWaits: 2},
+		{name: "saturated", stats: sql.DBStats{MaxOpenConnections: 10, InUse: 10, WaitCount: 7}, wantInUse: 10, wantUtilization: 1, wantWaits: 7},
+		{name: "unlimited pool", stats: sql.DBStats{InUse: 3, WaitCount: 7}, wantInUse: 3, wantWaits: 7},
+		{name: "wait count reset", stats: sql.DBStats{MaxOpenConnections: 4, InUse: 1, WaitCount: 1}, wantInUse: 1, wantUtilization: 0.25, wantWaits: 7},
+	}
+
+	p := NewPoolMetrics(prometheus.NewRegistry())
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p.Observe(tt.stats)
+
+			if got := testutil.ToFloat64(p.inUse); got != tt.wantInUse {
+				t.Errorf("in use = %v, want %v", got, tt.wantInUse)
+			}
+			if got := testutil.ToFloat64(p.utilization); got != tt.wantUtilization {
+				t.Errorf("utilization = %v, want %v", got, tt.wantUtilization)
+			}
+			if got := testutil.ToFloat64(p.waits); got != tt.wantWaits {
+				t.Errorf("waits = %v, want %v", got, tt.wantWaits)
+			}
+		})
+	}
+}
+
+func TestPoolMetricsRun(t *testing.T) {
+	p := NewPoolMetrics(prometheus.NewRegistry())
+	ctx, cancel := context.WithCancel(context.Background())
+
+	var samples atomic.Int32
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		p.Run(ctx, time.Millisecond, func() sql.DBStats {
+			samples.Add(1)
+			return sql.DBStats{MaxOpenConnections: 4, InUse: 2}
+		})
+	}()
+
+	deadline := time.Now().Add(5 * time.Second)
+	for samples.Load() < 3 && time.Now().Before(deadline) {
+		time.Sleep(time.Millisecond)
+	}
+	cancel()
+	<-done
+
+	if samples.Load() < 3 {
+		t.Fatalf("sampled %d times, want at least 3", samples.Load())
+	}
+	if got := testutil.ToFloat64(p.utilization); got != 0.5 {
+		t.Errorf("utilization = %v, want 0.5", got)
+	}
+}
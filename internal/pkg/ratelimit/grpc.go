@@ -0,0 +1,119 @@
+package ratelimit
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/hex"
+	"math"
+	"net"
+	"strconv"
+
+	"github.com/golang-standards/project-layout/internal/pkg/auth"
+	"github.com/golang-standards/project-layout/internal/pkg/logger"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+)
+
+// GatewayMetadataKey carries the token the in-process REST gateway presents on the calls
+// it proxies
+const GatewayMetadataKey = "x-gateway-token"
+
+// NewGatewayToken returns a random token for the REST gateway to present in
+// GatewayMetadataKey. It only lives in this process, so no other caller can know it.
+func NewGatewayToken() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
+
+// UnaryServerInterceptor returns a new unary server interceptor that limits requests per
+// authenticated user, or per client IP for anonymous callers, returning ResourceExhausted
+// when the limit is exceeded. Calls presenting gatewayToken come from the REST gateway,
+// which HTTPMiddleware already limits, and pass through whether or not they are
+// authenticated. Store errors let the request through.
+func UnaryServerInterceptor(store Store, m *Metrics, gatewayToken string) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		if err := take(ctx, store, m, gatewayToken, func(md metadata.MD) { _ = grpc.SetHeader(ctx, md) }); err != nil {
+			return nil, err
+		}
+		return handler(ctx, req)
+	}
+}
+
+// StreamServerInterceptor is the stream counterpart of UnaryServerInterceptor; opening a
+// stream takes one token, however many messages it carries
+func StreamServerInterceptor(store Store, m *Metrics, gatewayToken string) grpc.StreamServerInterceptor {
+	return func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		if err := take(ss.Context(), store, m, gatewayToken, func(md metadata.MD) { _ = ss.SetHeader(md) }); err != nil {
+			return err
+		}
+		return handler(srv, ss)
+	}
+}
+
+// take takes a token for the caller in ctx, reporting the limit through setHeader. It
+// returns ResourceExhausted when the caller is over the limit.
+func take(ctx context.Context, store Store, m *Metrics, gatewayToken string, setHeader func(metadata.MD)) error {
+	key, ok := grpcKey(ctx, gatewayToken)
+	if !ok {
+		return nil
+	}
+
+	result, err := store.Take(ctx, key)
+	if err != nil {
+		logger.FromContext(ctx).Warn("Rate limiter unavailable, allowing request", "error", err)
+		return nil
+	}
+
+	reset := strconv.Itoa(int(math.Ceil(result.Reset.Seconds())))
+	setHeader(metadata.Pairs(
+		"x-ratelimit-limit", strconv.Itoa(result.Limit),
+		"x-ratelimit-remaining", strconv.Itoa(result.Remaining),
+		"x-ratelimit-reset", reset,
+	))
+
+	if !result.Allowed {
+		m.throttled.WithLabelValues("grpc").Inc()
+		return status.Errorf(codes.ResourceExhausted, "rate limit exceeded, retry in %ss", reset)
+	}
+	return nil
+}
+
+// grpcKey identifies the caller; it reports false for calls from the gateway
+func grpcKey(ctx context.Context, gatewayToken string) (string, bool) {
+	if fromGateway(ctx, gatewayToken) {
+		return "", false
+	}
+	if userID, ok := auth.UserIDFromContext(ctx); ok {
+		return "user:" + userID, true
+	}
+
+	p, ok := peer.FromContext(ctx)
+	if !ok || p.Addr == nil {
+		return "", false
+	}
+	host, _, err := net.SplitHostPort(p.Addr.String())
+	if err != nil {
+		host = p.Addr.String()
+	}
+	return "ip:" + host, true
+}
+
+// fromGateway reports whether the call presents the gateway token
+func fromGateway(ctx context.Context, gatewayToken string) bool {
+	if gatewayToken == "" {
+		return false
+	}
+	md, ok := metadata.FromIncomingContext(ctx)
+	if !ok {
+		return false
+	}
+	vals := md.Get(GatewayMetadataKey)
+	return len(vals) == 1 && subtle.ConstantTimeCompare([]byte(vals[0]), []byte(gatewayToken)) == 1
+}
@@ -0,0 +1,172 @@
+package ratelimit
+
+import (
+	"context"
+	"net"
+	"testing"
+
+	"github.com/golang-standards/project-layout/internal/pkg/auth"
+	"github.com/prometheus/client_golang/prometheus"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+)
+
+// headerStream is a server stream recording the header it is sent
+type headerStream struct {
+	grpc.ServerStream
+	ctx    context.Context
+	header metadata.MD
+}
+
+func (s *headerStream) Context() context.Context { return s.ctx }
+
+func (s *headerStream) SetHeader(md metadata.MD) error {
+	s.header = metadata.Join(s.header, md)
+	return nil
+}
+
+// headerTransport is a server transport stream recording the header set by a unary handler
+type headerTransport struct {
+	grpc.ServerTransportStream
+	header metadata.MD
+}
+
+func (s *headerTransport) Method() string { return "/user.v1.UserService/GetUser" }
+
+func (s *headerTransport) SetHeader(md metadata.MD) error {
+	s.header = metadata.Join(s.header, md)
+	return nil
+}
+
+func TestUnaryServerInterceptorHeaders(t *testing.T) {
+	tests := []struct {
+		name          string
+		sent          int
+		wantCode      codes.Code
+		wantRemaining string
+	}{
+		{name: "first request", wantRemaining: "1"},
+		{name: "over the limit", sent: 2, wantCode: codes.ResourceExhausted, wantRemaining: "0"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			interceptor := UnaryServerInterceptor(NewMemoryStore(0.001, 2), NewMetrics(prometheus.NewRegistry()), "gateway-token")
+			handler := func(ctx context.Context, req interface{}) (interface{}, error) { return "ok", nil }
+			info := &grpc.UnaryServerInfo{FullMethod: "/user.v1.UserService/GetUser"}
+			call := func() (*headerTransport, error) {
+				transport := &headerTransport{}
+				ctx := grpc.NewContextWithServerTransportStream(context.Background(), transport)
+				ctx = auth.WithPrincipal(ctx, auth.Principal{UserID: "u1", Role: auth.RoleUser})
+				_, err := interceptor(ctx, nil, info, handler)
+				return transport, err
+			}
+			for i := 0; i < tt.sent; i++ {
+				if _, err := call(); err != nil {
+					t.Fatalf("request %d: %v", i, err)
+				}
+			}
+
+			transport, err := call()
+			if got := status.Code(err); got != tt.wantCode {
+				t.Fatalf("code = %v, want %v", got, tt.wantCode)
+			}
+			if got := transport.header.Get("x-ratelimit-limit"); len(got) != 1 || got[0] != "2" {
+				t.Errorf("x-ratelimit-limit = %v, want [2]", got)
+			}
+			if got := transport.header.Get("x-ratelimit-remaining"); len(got) != 1 || got[0] != tt.wantRemaining {
+				t.Errorf("x-ratelimit-remaining = %v, want [%s]", got, tt.wantRemaining)
+			}
+			if got := transport.header.Get("x-ratelimit-reset"); len(got) != 1 || got[0] == "0" {
+				t.Errorf("x-ratelimit-reset = %v, want the seconds until the bucket refills", got)
+			}
+		})
+	}
+}
+
+func TestStreamServerInterceptor(t *testing.T) {
+	tests := []struct {
+		name          string
+		opened        int
+		wantCode      codes.Code
+		wantRemaining string
+	}{
+		{name: "first stream", opened: 0, wantRemaining: "1"},
+		{name: "last token", opened: 1, wantRemaining: "0"},
+		{name: "over the limit", opened: 2, wantCode: codes.ResourceExhausted, wantRemaining: "0"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			store := NewMemoryStore(0.001, 2)
+			interceptor := StreamServerInterceptor(store, NewMetrics(prometheus.NewRegistry()), "gateway-token")
+			ctx := auth.WithPrincipal(context.Background(), auth.Principal{UserID: "u1", Role: auth.RoleUser})
+			handler := func(srv interface{}, ss grpc.ServerStream) error { return nil }
+			info := &grpc.StreamServerInfo{FullMethod: "/user.v1.UserService/StreamUsers"}
+
+			for i := 0; i < tt.opened; i++ {
+				if err := interceptor(nil, &headerStream{ctx: ctx}, info, handler); err != nil {
+					t.Fatalf("opening stream %d: %v", i, err)
+				}
+			}
+			ss := &headerStream{ctx: ctx}
+			err := interceptor(nil, ss, info, handler)
+			if got := status.Code(err); got != tt.wantCode {
+				t.Fatalf("code = %v, want %v", got, tt.wantCode)
+			}
+			if got := ss.header.Get("x-ratelimit-limit"); len(got) != 1 || got[0] != "2" {
+				t.Errorf("x-ratelimit-limit = %v, want [2]", got)
+			}
+			if got := ss.header.Get("x-ratelimit-remaining"); len(got) != 1 || got[0] != tt.wantRemaining {
+				t.Errorf("x-ratelimit-remaining = %v, want [%s]", got, tt.wantRemaining)
+			}
+		})
+	}
+}
+
+func TestGRPCKey(t *testing.T) {
+	const token = "gateway-token"
+	loopback := &net.TCPAddr{IP: net.IPv4(127, 0, 0, 1), Port: 50000}
+	remote := &net.TCPAddr{IP: net.IPv4(203, 0, 113, 7), Port: 50000}
+
+	tests := []struct {
+		name     string
+		userID   string
+		addr     net.Addr
+		md       metadata.MD
+		wantKey  string
+		wantSkip bool
+	}{
+		{name: "authenticated user", userID: "u1", addr: remote, wantKey: "user:u1"},
+		{name: "authenticated gateway call", userID: "u1", addr: loopback, md: metadata.Pairs(GatewayMetadataKey, token), wantSkip: true},
+		{name: "authenticated user with the wrong token", userID: "u1", addr: remote, md: metadata.Pairs(GatewayMetadataKey, "guess"), wantKey: "user:u1"},
+		{name: "anonymous gateway call", addr: loopback, md: metadata.Pairs(GatewayMetadataKey, token), wantSkip: true},
+		{name: "anonymous loopback call without the token", addr: loopback, wantKey: "ip:127.0.0.1"},
+		{name: "wrong token", addr: remote, md: metadata.Pairs(GatewayMetadataKey, "guess"), wantKey: "ip:203.0.113.7"},
+		{name: "token repeated", addr: remote, md: metadata.Pairs(GatewayMetadataKey, token, GatewayMetadataKey, token), wantKey: "ip:203.0.113.7"},
+		{name: "anonymous remote call", addr: remote, wantKey: "ip:203.0.113.7"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := peer.NewContext(context.Background(), &peer.Peer{Addr: tt.addr})
+			if tt.md != nil {
+				ctx = metadata.NewIncomingContext(ctx, tt.md)
+			}
+			if tt.userID != "" {
+				ctx = auth.WithPrincipal(ctx, auth.Principal{UserID: tt.userID, Role: auth.RoleUser})
+			}
+
+			key, ok := grpcKey(ctx, token)
+			if ok == tt.wantSkip {
+				t.Fatalf("limited = %v, want %v", ok, !tt.wantSkip)
+			}
+			if key != tt.wantKey {
+				t.Errorf("key = %q, want %q", key, tt.wantKey)
+			}
+		})
+	}
+}
@@ -0,0 +1,47 @@
+package ratelimit
+
+import (
+	"math"
+	"net"
+	"net/http"
+	"strconv"
+
+	"github.com/golang-standards/project-layout/internal/pkg/logger"
+)
+
+// HTTPMiddleware limits requests per client IP and reports the limiter state in
+// X-RateLimit-Limit, X-RateLimit-Remaining and X-RateLimit-Reset (seconds) headers.
+// Store errors let the request through.
+func HTTPMiddleware(store Store, m *Metrics, log logger.Logger, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		result, err := store.Take(r.Context(), "ip:"+clientIP(r))
+		if err != nil {
+			log.Warn("Rate limiter unavailable, allowing request", "error", err)
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		reset := strconv.Itoa(int(math.Ceil(result.Reset.Seconds())))
+		w.Header().Set("X-RateLimit-Limit", strconv.Itoa(result.Limit))
+		w.Header().Set("X-RateLimit-Remaining", strconv.Itoa(result.Remaining))
+		w.Header().Set("X-RateLimit-Reset", reset)
+
+		if !result.Allowed {
+			m.throttled.WithLabelValues("http").Inc()
+			w.Header().Set("Retry-After", reset)
+			http.Error(w, "rate limit exceeded", http.StatusTooManyRequests)
+			return
+		}
+
+		next.ServeHTTP(w, r)
+	})
+}
+
+// clientIP returns the host part of the request's remote address
+func clientIP(r *http.Request) string {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
+	}
+	return host
+}
@@ -0,0 +1,60 @@
+package ratelimit
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/golang-standards/project-layout/internal/pkg/logger"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+func TestHTTPMiddlewareHeaders(t *testing.T) {
+	tests := []struct {
+		name           string
+		sent           int
+		wantStatus     int
+		wantRemaining  string
+		wantRetryAfter bool
+	}{
+		{name: "first request", wantStatus: http.StatusOK, wantRemaining: "1"},
+		{name: "last token", sent: 1, wantStatus: http.StatusOK, wantRemaining: "0"},
+		{name: "over the limit", sent: 2, wantStatus: http.StatusTooManyRequests, wantRemaining: "0", wantRetryAfter: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// One token per 1000s, so none refill during the test
+			handler := HTTPMiddleware(NewMemoryStore(0.001, 2), NewMetrics(prometheus.NewRegistry()), logger.NewNopLogger(),
+				http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+			serve := func() *httptest.ResponseRecorder {
+				req := httptest.NewRequest(http.MethodGet, "/v1/users", nil)
+				req.RemoteAddr = "203.0.113.7:50000"
+				rec := httptest.NewRecorder()
+				handler.ServeHTTP(rec, req)
+				return rec
+			}
+			for i := 0; i < tt.sent; i++ {
+				serve()
+			}
+
+			rec := serve()
+			if rec.Code != tt.wantStatus {
+				t.Fatalf("status = %d, want %d", rec.Code, tt.wantStatus)
+			}
+			if got := rec.Header().Get("X-RateLimit-Limit"); got != "2" {
+				t.Errorf("X-RateLimit-Limit = %q, want 2", got)
+			}
+			if got := rec.Header().Get("X-RateLimit-Remaining"); got != tt.wantRemaining {
+				t.Errorf("X-RateLimit-Remaining = %q, want %q", got, tt.wantRemaining)
+			}
+			reset := rec.Header().Get("X-RateLimit-Reset")
+			if reset == "" || reset == "0" {
+				t.Errorf("X-RateLimit-Reset = %q, want the seconds until the bucket refills", reset)
+			}
+			if got := rec.Header().Get("Retry-After"); (got != "") != tt.wantRetryAfter || (tt.wantRetryAfter && got != reset) {
+				t.Errorf("Retry-After = %q, want it only when throttled and equal to the reset %q", got, reset)
+			}
+		})
+	}
+}
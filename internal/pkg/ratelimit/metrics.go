@@ -0,0 +1,23 @@
+package ratelimit
+
+import "github.com/prometheus/client_golang/prometheus"
+
+// Metrics holds the Prometheus collectors for rate limiting
+type Metrics struct {
+	throttled *prometheus.CounterVec
+}
+
+// NewMetrics creates the rate limiting collectors and registers them with the given registerer
+func NewMetrics(reg prometheus.Registerer) *Metrics {
+	m := &Metrics{
+		throttled: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: "user_service",
+			Name:      "ratelimit_throttled_requests_total",
+			Help:      "Total number of requests rejected by the rate limiter.",
+		}, []string{"transport"}),
+	}
+
+	reg.MustRegister(m.throttled)
+
+	return m
+}
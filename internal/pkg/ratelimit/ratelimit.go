@@ -0,0 +1,106 @@
+package ratelimit
+
+import (
+	"context"
+	"math"
+	"sync"
+	"time"
+)
+
+// Result describes the limiter state for a key after a request
+type Result struct {
+	Allowed bool
+	// Limit is the bucket capacity (burst)
+	Limit int
+	// Remaining is the number of whole tokens left
+	Remaining int
+	// Reset is how long until the bucket is full again
+	Reset time.Duration
+}
+
+// Store tracks token buckets per client key
+type Store interface {
+	Take(ctx context.Context, key string) (Result, error)
+	// SetLimits changes the rate and burst for subsequent requests; existing buckets
+	// keep their tokens, capped at the new burst
+	SetLimits(rate float64, burst int)
+}
+
+// bucket is a token bucket refilled continuously at the store's rate
+type bucket struct {
+	tokens float64
+	last   time.Time
+}
+
+// MemoryStore keeps token buckets in process memory
+type MemoryStore struct {
+	mu        sync.Mutex
+	rate      float64
+	burst     int
+	buckets   map[string]*bucket
+	lastSweep time.Time
+	now       func() time.Time
+}
+
+// NewMemoryStore creates an in-memory store allowing rate requests per second
+// with bursts of up to burst requests per key
+func NewMemoryStore(rate float64, burst int) *MemoryStore {
+	return &MemoryStore{
+		rate:    rate,
+		burst:   burst,
+		buckets: make(map[string]*bucket),
+		now:     time.Now,
+	}
+}
+
+// Take consumes a token for key if one is available
+func (s *MemoryStore) Take(_ context.Context, key string) (Result, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := s.now()
+	s.sweep(now)
+
+	b, ok := s.buckets[key]
+	if !ok {
+		b = &bucket{tokens: float64(s.burst), last: now}
+		s.buckets[key] = b
+	}
+	b.tokens = math.Min(float64(s.burst), b.tokens+now.Sub(b.last).Seconds()*s.rate)
+	b.last = now
+
+	allowed := b.tokens >= 1
+	if allowed {
+		b.tokens--
+	}
+
+	return Result{
+		Allowed:   allowed,
+		Limit:     s.burst,
+		Remaining: int(b.tokens),
+		Reset:     time.Duration((float64(s.burst) - b.tokens) / s.rate * float64(time.Second)),
+	}, nil
+}
+
+// SetLimits changes the rate and burst for subsequent requests
+func (s *MemoryStore) SetLimits(rate float64, burst int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.rate = rate
+	s.burst = burst
+}
+
+// sweep drops buckets that have refilled completely, since they hold no state;
+// it runs at most once a minute
+func (s *MemoryStore) sweep(now time.Time) {
+	if now.Sub(s.lastSweep) < time.Minute {
+		return
+	}
+	s.lastSweep = now
+
+	for key, b := range s.buckets {
+		if b.tokens+now.Sub(b.last).Seconds()*s.rate >= float64(s.burst) {
+			delete(s.buckets, key)
+		}
+	}
+}
@@ -0,0 +1,91 @@
+package ratelimit
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// redisKeyPrefix namespaces token buckets in Redis
+const redisKeyPrefix = "user-service:ratelimit:"
+
+// takeScript refills and takes from a token bucket stored as a hash. It uses the Redis
+// clock so instances with skewed clocks share buckets consistently. Tokens are returned
+// as a string because Redis truncates Lua numbers to integers.
+var takeScript = redis.NewScript(`
+local rate = tonumber(ARGV[1])
+local burst = tonumber(ARGV[2])
+local t = redis.call('TIME')
+local now = tonumber(t[1]) + tonumber(t[2]) / 1000000
+
+local state = redis.call('HMGET', KEYS[1], 'tokens', 'last')
+local tokens = tonumber(state[1]) or burst
+local last = tonumber(state[2]) or now
+tokens = math.min(burst, tokens + math.max(0, now - last) * rate)
+
+local allowed = 0
+if tokens >= 1 then
+  tokens = tokens - 1
+  allowed = 1
+end
+
+redis.call('HSET', KEYS[1], 'tokens', tostring(tokens), 'last', tostring(now))
+redis.call('PEXPIRE', KEYS[1], math.ceil((burst - tokens) / rate * 1000) + 1000)
+return {allowed, tostring(tokens)}
+`)
+
+// RedisStore keeps token buckets in Redis so every instance shares the same limits
+type RedisStore struct {
+	client *redis.Client
+	mu     sync.RWMutex
+	rate   float64
+	burst  int
+}
+
+// NewRedisStore creates a Redis-backed store allowing rate requests per second
+// with bursts of up to burst requests per key
+func NewRedisStore(client *redis.Client, rate float64, burst int) *RedisStore {
+	return &RedisStore{client: client, rate: rate, burst: burst}
+}
+
+// Take consumes a token for key if one is available
+func (s *RedisStore) Take(ctx context.Context, key string) (Result, error) {
+	s.mu.RLock()
+	rate, burst := s.rate, s.burst
+	s.mu.RUnlock()
+
+	reply, err := takeScript.Run(ctx, s.client, []string{redisKeyPrefix + key}, rate, burst).Slice()
+	if err != nil {
+		return Result{}, fmt.Errorf("failed to take rate limit token: %w", err)
+	}
+	if len(reply) != 2 {
+		return Result{}, fmt.Errorf("unexpected rate limit reply %v", reply)
+	}
+
+	allowed, _ := reply[0].(int64)
+	tokensStr, _ := reply[1].(string)
+	tokens, err := strconv.ParseFloat(tokensStr, 64)
+	if err != nil {
+		return Result{}, fmt.Errorf("invalid rate limit tokens %q: %w", tokensStr, err)
+	}
+
+	return Result{
+		Allowed:   allowed == 1,
+		Limit:     burst,
+		Remaining: int(tokens),
+		Reset:     time.Duration((float64(burst) - tokens) / rate * float64(time.Second)),
+	}, nil
+}
+
+// SetLimits changes the rate and burst for subsequent requests from this instance.
+// Other instances keep their own limits until they reload too.
+func (s *RedisStore) SetLimits(rate float64, burst int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.rate = rate
+	s.burst = burst
+}
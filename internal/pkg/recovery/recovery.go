@@ -0,0 +1,67 @@
+package recovery
+
+import (
+	"context"
+	"runtime/debug"
+
+	"github.com/golang-standards/project-layout/internal/pkg/logger"
+	"github.com/golang-standards/project-layout/internal/pkg/requestid"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+// UnaryServerInterceptor returns a new unary server interceptor that turns a panic in
+// later interceptors or the handler into an Internal error, logging the stack trace.
+// It should run first so it covers the whole chain; the logged request ID is therefore
+// the one sent by the client, if any.
+func UnaryServerInterceptor(log logger.Logger) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (resp interface{}, err error) {
+		defer func() {
+			if r := recover(); r != nil {
+				log.Error("Recovered from panic in gRPC handler",
+					"method", info.FullMethod,
+					"request_id", requestID(ctx),
+					"panic", r,
+					"stack", string(debug.Stack()),
+				)
+				resp, err = nil, status.Error(codes.Internal, "internal error")
+			}
+		}()
+
+		return handler(ctx, req)
+	}
+}
+
+// StreamServerInterceptor is the stream counterpart of UnaryServerInterceptor
+func StreamServerInterceptor(log logger.Logger) grpc.StreamServerInterceptor {
+	return func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) (err error) {
+		defer func() {
+			if r := recover(); r != nil {
+				log.Error("Recovered from panic in gRPC stream handler",
+					"method", info.FullMethod,
+					"request_id", requestID(ss.Context()),
+					"panic", r,
+					"stack", string(debug.Stack()),
+				)
+				err = status.Error(codes.Internal, "internal error")
+			}
+		}()
+
+		return handler(srv, ss)
+	}
+}
+
+// requestID returns the request ID from ctx or, if valid, from the incoming metadata
+func requestID(ctx context.Context) string {
+	if id := requestid.FromContext(ctx); id != "" {
+		return id
+	}
+	if md, ok := metadata.FromIncomingContext(ctx); ok {
+		if vals := md.Get(requestid.MetadataKey); len(vals) > 0 && requestid.Valid(vals[0]) {
+			return vals[0]
+		}
+	}
+	return ""
+}
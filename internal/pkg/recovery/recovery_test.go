@@ -0,0 +1,89 @@
+package recovery
+
+import (
+	"context"
+	"testing"
+
+	"github.com/golang-standards/project-layout/internal/pkg/logger"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// fakeStream is a server stream carrying only a context
+type fakeStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (s *fakeStream) Context() context.Context { return s.ctx }
+
+func TestUnaryServerInterceptor(t *testing.T) {
+	tests := []struct {
+		name     string
+		handler  grpc.UnaryHandler
+		wantCode codes.Code
+	}{
+		{
+			name:     "passes through",
+			handler:  func(ctx context.Context, req interface{}) (interface{}, error) { return "ok", nil },
+			wantCode: codes.OK,
+		},
+		{
+			name: "keeps handler errors",
+			handler: func(ctx context.Context, req interface{}) (interface{}, error) {
+				return nil, status.Error(codes.NotFound, "missing")
+			},
+			wantCode: codes.NotFound,
+		},
+		{
+			name:     "converts panic",
+			handler:  func(ctx context.Context, req interface{}) (interface{}, error) { panic("boom") },
+			wantCode: codes.Internal,
+		},
+	}
+
+	interceptor := UnaryServerInterceptor(logger.NewNopLogger())
+	info := &grpc.UnaryServerInfo{FullMethod: "/user.v1.UserService/GetUser"}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resp, err := interceptor(context.Background(), nil, info, tt.handler)
+			if got := status.Code(err); got != tt.wantCode {
+				t.Fatalf("code = %v, want %v", got, tt.wantCode)
+			}
+			if tt.wantCode == codes.Internal && resp != nil {
+				t.Errorf("resp = %v, want nil after a panic", resp)
+			}
+		})
+	}
+}
+
+func TestStreamServerInterceptor(t *testing.T) {
+	tests := []struct {
+		name     string
+		handler  grpc.StreamHandler
+		wantCode codes.Code
+	}{
+		{
+			name:     "passes through",
+			handler:  func(srv interface{}, ss grpc.ServerStream) error { return nil },
+			wantCode: codes.OK,
+		},
+		{
+			name:     "converts panic",
+			handler:  func(srv interface{}, ss grpc.ServerStream) error { panic("boom") },
+			wantCode: codes.Internal,
+		},
+	}
+
+	interceptor := StreamServerInterceptor(logger.NewNopLogger())
+	info := &grpc.StreamServerInfo{FullMethod: "/user.v1.UserService/StreamUsers", IsServerStream: true}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := interceptor(nil, &fakeStream{ctx: context.Background()}, info, tt.handler)
+			if got := status.Code(err); got != tt.wantCode {
+				t.Fatalf("code = %v, want %v", got, tt.wantCode)
+			}
+		})
+	}
+}
@@ -0,0 +1,91 @@
+package requestid
+
+import (
+	"context"
+
+	"github.com/golang-standards/project-layout/internal/pkg/interceptors"
+	"github.com/google/uuid"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+)
+
+// MetadataKey is the gRPC metadata key carrying the request ID
+const MetadataKey = "x-request-id"
+
+// MaxLength caps client-supplied request IDs, which end up in every log line
+const MaxLength = 128
+
+type requestIDKey struct{}
+
+// New generates a new request ID
+func New() string {
+	return uuid.NewString()
+}
+
+// Valid reports whether id is usable as a request ID: non-empty, at most MaxLength
+// characters, and limited to letters, digits and "-", "_", ".", ":" so it cannot
+// forge log fields or response headers
+func Valid(id string) bool {
+	if id == "" || len(id) > MaxLength {
+		return false
+	}
+	for _, r := range id {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9':
+		case r == '-', r == '_', r == '.', r == ':':
+		default:
+			return false
+		}
+	}
+	return true
+}
+
+// NewContext returns a copy of ctx carrying the request ID
+func NewContext(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, requestIDKey{}, id)
+}
+
+// FromContext returns the request ID stored in ctx, or an empty string
+func FromContext(ctx context.Context) string {
+	id, _ := ctx.Value(requestIDKey{}).(string)
+	return id
+}
+
+// UnaryServerInterceptor returns a new unary server interceptor that reads the request ID
+// from incoming metadata, generating one when absent or not Valid. The ID is stored in the context,
+// propagated to outgoing calls, and returned to the client in the response header.
+func UnaryServerInterceptor() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		ctx, id := withRequestID(ctx)
+		_ = grpc.SetHeader(ctx, metadata.Pairs(MetadataKey, id))
+
+		return handler(ctx, req)
+	}
+}
+
+// StreamServerInterceptor is the stream counterpart of UnaryServerInterceptor
+func StreamServerInterceptor() grpc.StreamServerInterceptor {
+	return func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		ctx, id := withRequestID(ss.Context())
+		_ = ss.SetHeader(metadata.Pairs(MetadataKey, id))
+
+		return handler(srv, interceptors.WrapServerStream(ctx, ss))
+	}
+}
+
+// withRequestID stores the incoming or a new request ID in ctx and its outgoing metadata
+func withRequestID(ctx context.Context) (context.Context, string) {
+	id := ""
+	if md, ok := metadata.FromIncomingContext(ctx); ok {
+		if vals := md.Get(MetadataKey); len(vals) > 0 {
+			id = vals[0]
+		}
+	}
+	if !Valid(id) {
+		id = New()
+	}
+
+	ctx = NewContext(ctx, id)
+	ctx = metadata.AppendToOutgoingContext(ctx, MetadataKey, id)
+	return ctx, id
+}
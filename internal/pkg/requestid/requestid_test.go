@@ -0,0 +1,93 @@
+package requestid
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+)
+
+// headerStream is a server stream recording the header it is sent
+type headerStream struct {
+	grpc.ServerStream
+	ctx    context.Context
+	header metadata.MD
+}
+
+func (s *headerStream) Context() context.Context { return s.ctx }
+
+func (s *headerStream) SetHeader(md metadata.MD) error {
+	s.header = metadata.Join(s.header, md)
+	return nil
+}
+
+func TestStreamServerInterceptor(t *testing.T) {
+	tests := []struct {
+		name     string
+		incoming string
+		keep     bool
+	}{
+		{name: "client ID kept", incoming: "req-123", keep: true},
+		{name: "ID generated"},
+		{name: "too long ID replaced", incoming: strings.Repeat("a", MaxLength+1)},
+		{name: "invalid ID replaced", incoming: "req\nlevel=error"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			if tt.incoming != "" {
+				ctx = metadata.NewIncomingContext(ctx, metadata.Pairs(MetadataKey, tt.incoming))
+			}
+			ss := &headerStream{ctx: ctx}
+
+			var got string
+			err := StreamServerInterceptor()(nil, ss, &grpc.StreamServerInfo{},
+				func(srv interface{}, ss grpc.ServerStream) error {
+					got = FromContext(ss.Context())
+					return nil
+				})
+			if err != nil {
+				t.Fatalf("stream: %v", err)
+			}
+
+			if tt.keep && got != tt.incoming {
+				t.Errorf("request ID = %q, want %q", got, tt.incoming)
+			}
+			if !tt.keep && (got == tt.incoming || !Valid(got)) {
+				t.Errorf("request ID = %q, want a generated one", got)
+			}
+			if header := ss.header.Get(MetadataKey); len(header) != 1 || header[0] != got {
+				t.Errorf("header = %v, want [%s]", header, got)
+			}
+		})
+	}
+}
+
+func TestValid(t *testing.T) {
+	tests := []struct {
+		name string
+		id   string
+		want bool
+	}{
+		{name: "uuid", id: New(), want: true},
+		{name: "punctuation", id: "trace-1_2.3:4", want: true},
+		{name: "max length", id: strings.Repeat("a", MaxLength), want: true},
+		{name: "empty"},
+		{name: "too long", id: strings.Repeat("a", MaxLength+1)},
+		{name: "newline", id: "req\nlevel=error"},
+		{name: "space", id: "req 1"},
+		{name: "quote", id: `req"1`},
+		{name: "non-ASCII", id: "req-é"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Valid(tt.id); got != tt.want {
+				t.Errorf("Valid(%q) = %v, want %v", tt.id, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,93 @@
+package timeout
+
+import (
+	"context"
+	"path"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/golang-standards/project-layout/internal/pkg/interceptors"
+	"google.golang.org/grpc"
+)
+
+// Policy holds the timeouts applied by the interceptor. Set replaces them while the
+// server is running, e.g. on a configuration reload.
+type Policy struct {
+	mu             sync.RWMutex
+	defaultTimeout time.Duration
+	methodTimeouts map[string]time.Duration
+}
+
+// NewPolicy creates a policy; see Set for the meaning of the timeouts
+func NewPolicy(defaultTimeout time.Duration, methodTimeouts map[string]time.Duration) *Policy {
+	p := &Policy{}
+	p.Set(defaultTimeout, methodTimeouts)
+	return p
+}
+
+// Set replaces the timeouts. methodTimeouts overrides defaultTimeout per RPC method name
+// (such as "CreateUsersBatch"), matched case-insensitively since config keys are
+// lowercased; a zero timeout leaves the request without a deadline.
+func (p *Policy) Set(defaultTimeout time.Duration, methodTimeouts map[string]time.Duration) {
+	timeouts := make(map[string]time.Duration, len(methodTimeouts))
+	for method, t := range methodTimeouts {
+		timeouts[strings.ToLower(method)] = t
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.defaultTimeout = defaultTimeout
+	p.methodTimeouts = timeouts
+}
+
+// timeout returns the timeout for a full gRPC method name
+func (p *Policy) timeout(fullMethod string) time.Duration {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	if t, ok := p.methodTimeouts[strings.ToLower(path.Base(fullMethod))]; ok {
+		return t
+	}
+	return p.defaultTimeout
+}
+
+// UnaryServerInterceptor returns a new unary server interceptor that applies the policy's
+// timeout to requests arriving without a deadline. Deadlines set by the client are never
+// changed.
+func UnaryServerInterceptor(policy *Policy) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		if _, ok := ctx.Deadline(); ok {
+			return handler(ctx, req)
+		}
+
+		timeout := policy.timeout(info.FullMethod)
+		if timeout <= 0 {
+			return handler(ctx, req)
+		}
+
+		ctx, cancel := context.WithTimeout(ctx, timeout)
+		defer cancel()
+		return handler(ctx, req)
+	}
+}
+
+// StreamServerInterceptor is the stream counterpart of UnaryServerInterceptor. The timeout
+// bounds the whole stream, so streaming methods that may run long, like StreamUsers,
+// should get their own entry in the policy.
+func StreamServerInterceptor(policy *Policy) grpc.StreamServerInterceptor {
+	return func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		ctx := ss.Context()
+		if _, ok := ctx.Deadline(); ok {
+			return handler(srv, ss)
+		}
+
+		timeout := policy.timeout(info.FullMethod)
+		if timeout <= 0 {
+			return handler(srv, ss)
+		}
+
+		ctx, cancel := context.WithTimeout(ctx, timeout)
+		defer cancel()
+		return handler(srv, interceptors.WrapServerStream(ctx, ss))
+	}
+}
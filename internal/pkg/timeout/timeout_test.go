@@ -0,0 +1,66 @@
+package timeout
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"google.golang.org/grpc"
+)
+
+// fakeStream is a server stream carrying only a context
+type fakeStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (s *fakeStream) Context() context.Context { return s.ctx }
+
+func TestStreamServerInterceptor(t *testing.T) {
+	policy := NewPolicy(30*time.Second, map[string]time.Duration{"StreamUsers": 10 * time.Minute, "Unbounded": 0})
+
+	tests := []struct {
+		name           string
+		method         string
+		clientDeadline time.Duration
+		want           time.Duration
+	}{
+		{name: "method timeout", method: "/user.v1.UserService/StreamUsers", want: 10 * time.Minute},
+		{name: "default timeout", method: "/user.v1.UserService/Other", want: 30 * time.Second},
+		{name: "client deadline kept", method: "/user.v1.UserService/StreamUsers", clientDeadline: time.Second, want: time.Second},
+		{name: "disabled", method: "/user.v1.UserService/Unbounded"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			if tt.clientDeadline > 0 {
+				var cancel context.CancelFunc
+				ctx, cancel = context.WithTimeout(ctx, tt.clientDeadline)
+				defer cancel()
+			}
+
+			err := StreamServerInterceptor(policy)(nil, &fakeStream{ctx: ctx}, &grpc.StreamServerInfo{FullMethod: tt.method},
+				func(srv interface{}, ss grpc.ServerStream) error {
+					deadline, ok := ss.Context().Deadline()
+					if tt.want == 0 {
+						if ok {
+							t.Errorf("deadline set to %v, want none", deadline)
+						}
+						return nil
+					}
+					if !ok {
+						t.Fatal("no deadline on the stream context")
+					}
+					// Allow for the time the test itself takes
+					if remaining := time.Until(deadline); remaining > tt.want || remaining < tt.want-time.Second {
+						t.Errorf("deadline in %v, want about %v", remaining, tt.want)
+					}
+					return nil
+				})
+			if err != nil {
+				t.Fatalf("stream: %v", err)
+			}
+		})
+	}
+}
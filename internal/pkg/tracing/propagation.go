@@ -0,0 +1,69 @@
+package tracing
+
+import (
+	"context"
+
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/trace"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+)
+
+// W3C trace context metadata keys
+const (
+	TraceparentKey = "traceparent"
+	TracestateKey  = "tracestate"
+)
+
+// traceContext handles W3C trace context regardless of the global propagator,
+// so correlation works even when tracing is disabled
+var traceContext = propagation.TraceContext{}
+
+// metadataCarrier adapts gRPC metadata to a propagation.TextMapCarrier
+type metadataCarrier metadata.MD
+
+func (c metadataCarrier) Get(key string) string {
+	if vals := metadata.MD(c).Get(key); len(vals) > 0 {
+		return vals[0]
+	}
+	return ""
+}
+
+func (c metadataCarrier) Set(key, value string) {
+	metadata.MD(c).Set(key, value)
+}
+
+func (c metadataCarrier) Keys() []string {
+	keys := make([]string, 0, len(c))
+	for k := range c {
+		keys = append(keys, k)
+	}
+	return keys
+}
+
+// UnaryServerInterceptor returns a new unary server interceptor that extracts W3C trace
+// context from incoming metadata when no span is in the context yet, and propagates the
+// context's trace to outgoing calls. The logging interceptor then picks up the trace ID.
+func UnaryServerInterceptor() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		if !trace.SpanContextFromContext(ctx).IsValid() {
+			if md, ok := metadata.FromIncomingContext(ctx); ok {
+				ctx = traceContext.Extract(ctx, metadataCarrier(md))
+			}
+		}
+
+		return handler(injectOutgoing(ctx), req)
+	}
+}
+
+// injectOutgoing adds the context's trace context to its outgoing metadata
+func injectOutgoing(ctx context.Context) context.Context {
+	carrier := metadataCarrier(metadata.MD{})
+	traceContext.Inject(ctx, carrier)
+	for key, vals := range carrier {
+		for _, val := range vals {
+			ctx = metadata.AppendToOutgoingContext(ctx, key, val)
+		}
+	}
+	return ctx
+}
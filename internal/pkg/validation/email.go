@@ -0,0 +1,63 @@
+package validation
+
+import (
+	"errors"
+	"fmt"
+	"net/mail"
+	"strings"
+)
+
+// NormalizeEmail trims surrounding whitespace and lowercases an email address
+func NormalizeEmail(email string) string {
+	return strings.ToLower(strings.TrimSpace(email))
+}
+
+// ValidateEmail checks that email is a bare RFC 5322 address with a plausible domain
+func ValidateEmail(email string) error {
+	if email == "" {
+		return errors.New("email is required")
+	}
+
+	addr, err := mail.ParseAddress(email)
+	if err != nil {
+		return fmt.Errorf("malformed email: %w", err)
+	}
+	if addr.Address != email {
+		return errors.New("email must not contain a display name")
+	}
+
+	at := strings.LastIndex(email, "@")
+	return validateDomain(email[at+1:])
+}
+
+// validateDomain performs a sanity check on the domain part of an email address
+func validateDomain(domain string) error {
+	if len(domain) > 253 {
+		return errors.New("email domain is too long")
+	}
+
+	labels := strings.Split(domain, ".")
+	if len(labels) < 2 {
+		return fmt.Errorf("email domain %q has no top-level domain", domain)
+	}
+
+	for _, label := range labels {
+		if label == "" || len(label) > 63 {
+			return fmt.Errorf("email domain %q has an invalid label", domain)
+		}
+		if strings.HasPrefix(label, "-") || strings.HasSuffix(label, "-") {
+			return fmt.Errorf("email domain %q has an invalid label", domain)
+		}
+		for _, r := range label {
+			if !(r == '-' || r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9') {
+				return fmt.Errorf("email domain %q contains invalid characters", domain)
+			}
+		}
+	}
+
+	if tld := labels[len(labels)-1]; len(tld) < 2 {
+		return fmt.Errorf("email domain %q has an invalid top-level domain", domain)
+	}
+
+	return nil
+}
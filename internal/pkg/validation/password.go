@@ -0,0 +1,96 @@
+package validation
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"unicode"
+)
+
+// ErrWeakPassword is returned when a password contains the user's personal information
+var ErrWeakPassword = errors.New("weak password")
+
+// minPersonalInfoLength ignores very short names and local parts to avoid false positives
+const minPersonalInfoLength = 3
+
+// PasswordPolicy describes the rules a password must satisfy
+type PasswordPolicy struct {
+	MinLength     int
+	RequireUpper  bool
+	RequireDigit  bool
+	RequireSymbol bool
+	// RejectPersonalInfo rejects passwords containing the user's email local part or names
+	RejectPersonalInfo bool
+}
+
+// PasswordPolicyError lists every rule a password failed
+type PasswordPolicyError struct {
+	Failures []string
+}
+
+func (e *PasswordPolicyError) Error() string {
+	return "password " + strings.Join(e.Failures, ", ")
+}
+
+// ValidatePassword checks password against policy, returning a *PasswordPolicyError
+// describing all failed rules
+func ValidatePassword(password string, policy PasswordPolicy) error {
+	var hasUpper, hasDigit, hasSymbol bool
+	for _, r := range password {
+		switch {
+		case unicode.IsUpper(r):
+			hasUpper = true
+		case unicode.IsDigit(r):
+			hasDigit = true
+		case unicode.IsPunct(r) || unicode.IsSymbol(r):
+			hasSymbol = true
+		}
+	}
+
+	var failures []string
+	if n := len([]rune(password)); n < policy.MinLength {
+		failures = append(failures, fmt.Sprintf("must be at least %d characters", policy.MinLength))
+	}
+	if policy.RequireUpper && !hasUpper {
+		failures = append(failures, "must contain an uppercase letter")
+	}
+	if policy.RequireDigit && !hasDigit {
+		failures = append(failures, "must contain a digit")
+	}
+	if policy.RequireSymbol && !hasSymbol {
+		failures = append(failures, "must contain a symbol")
+	}
+
+	if len(failures) > 0 {
+		return &PasswordPolicyError{Failures: failures}
+	}
+	return nil
+}
+
+// ValidatePasswordPersonalInfo rejects a password that contains, case-insensitively,
+// the local part of email or any of the given names
+func ValidatePasswordPersonalInfo(password, email string, names ...string) error {
+	lowered := strings.ToLower(password)
+
+	local := email
+	if at := strings.LastIndex(email, "@"); at >= 0 {
+		local = email[:at]
+	}
+
+	candidates := []struct{ label, value string }{{"email", local}}
+	for _, name := range names {
+		candidates = append(candidates, struct{ label, value string }{"name", name})
+	}
+
+	for _, candidate := range candidates {
+		value := strings.ToLower(strings.TrimSpace(candidate.value))
+		if len([]rune(value)) < minPersonalInfoLength {
+			continue
+		}
+		if strings.Contains(lowered, value) {
+			return fmt.Errorf("%w: password must not contain your %s", ErrWeakPassword, candidate.label)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,48 @@
+package validation
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestValidatePasswordPersonalInfo(t *testing.T) {
+	tests := []struct {
+		name      string
+		password  string
+		email     string
+		names     []string
+		wantLabel string
+	}{
+		{name: "unrelated", password: "correct-horse-42", email: "jane@example.com", names: []string{"Jane", "Doe"}},
+		{name: "email local part", password: "xx-janedoe-42", email: "janedoe@example.com", wantLabel: "email"},
+		{name: "email local part, other case", password: "JaneDoe2024!", email: "janedoe@example.com", wantLabel: "email"},
+		{name: "domain is allowed", password: "example-horse-42", email: "jane@example.com"},
+		{name: "first name", password: "i-am-jane-42", email: "jd@example.com", names: []string{"Jane", "Doe"}, wantLabel: "name"},
+		{name: "last name, other case", password: "DOEDOE-horse", email: "jd@example.com", names: []string{"Jane", "doe"}, wantLabel: "name"},
+		{name: "name with spaces trimmed", password: "horse-jane-42", email: "jd@example.com", names: []string{"  Jane  "}, wantLabel: "name"},
+		{name: "short name ignored", password: "al-horse-42", email: "jd@example.com", names: []string{"Al"}},
+		{name: "short local part ignored", password: "jd-horse-42", email: "jd@example.com"},
+		{name: "empty names ignored", password: "horse-42", email: "", names: []string{"", " "}},
+		{name: "email without at sign", password: "horse-janedoe", email: "janedoe", wantLabel: "email"},
+		{name: "non-ASCII name", password: "pass-JOSÉ-word", email: "jd@example.com", names: []string{"José"}, wantLabel: "name"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := ValidatePasswordPersonalInfo(tt.password, tt.email, tt.names...)
+			if tt.wantLabel == "" {
+				if err != nil {
+					t.Errorf("ValidatePasswordPersonalInfo = %v, want nil", err)
+				}
+				return
+			}
+			if !errors.Is(err, ErrWeakPassword) {
+				t.Fatalf("ValidatePasswordPersonalInfo = %v, want %v", err, ErrWeakPassword)
+			}
+			if !strings.HasSuffix(err.Error(), "your "+tt.wantLabel) {
+				t.Errorf("error = %q, want it to name your %s", err, tt.wantLabel)
+			}
+		})
+	}
+}
@@ -0,0 +1,42 @@
+-- +goose Up
+CREATE TABLE IF NOT EXISTS users (
+    id                        uuid PRIMARY KEY DEFAULT gen_random_uuid(),
+    email                     text NOT NULL,
+    canonical_email           text NOT NULL,
+    org_id                    uuid,
+    password                  text NOT NULL,
+    first_name                varchar(100),
+    last_name                 varchar(100),
+    phone                     varchar(20),
+    status                    varchar(20) DEFAULT 'active',
+    email_verified            boolean NOT NULL DEFAULT false,
+    version                   bigint NOT NULL DEFAULT 1,
+    created_at                timestamptz,
+    updated_at                timestamptz,
+    deleted_at                timestamptz,
+    deleted_reason            varchar(500),
+    failed_login_attempts     bigint NOT NULL DEFAULT 0,
+    failed_login_window_start timestamptz,
+    locked_until              timestamptz
+);
+
+-- Databases whose users table was created by GORM AutoMigrate before versioned migrations
+-- only have the original columns; add the rest so the indexes below and the later
+-- migrations apply. Each is a no-op on a table created above.
+ALTER TABLE users ADD COLUMN IF NOT EXISTS canonical_email text;
+ALTER TABLE users ADD COLUMN IF NOT EXISTS org_id uuid;
+-- Existing users predate verification and count as verified; new rows default to unverified
+ALTER TABLE users ADD COLUMN IF NOT EXISTS email_verified boolean NOT NULL DEFAULT true;
+ALTER TABLE users ALTER COLUMN email_verified SET DEFAULT false;
+ALTER TABLE users ADD COLUMN IF NOT EXISTS version bigint NOT NULL DEFAULT 1;
+ALTER TABLE users ADD COLUMN IF NOT EXISTS deleted_reason varchar(500);
+ALTER TABLE users ADD COLUMN IF NOT EXISTS failed_login_attempts bigint NOT NULL DEFAULT 0;
+ALTER TABLE users ADD COLUMN IF NOT EXISTS failed_login_window_start timestamptz;
+ALTER TABLE users ADD COLUMN IF NOT EXISTS locked_until timestamptz;
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_users_email ON users (email);
+CREATE INDEX IF NOT EXISTS idx_users_org_id ON users (org_id);
+CREATE INDEX IF NOT EXISTS idx_users_deleted_at ON users (deleted_at);
+
+-- +goose Down
+DROP TABLE IF EXISTS users;
@@ -0,0 +1,10 @@
+-- +goose Up
+-- IDs are generated by the application, so the schema no longer needs pgcrypto
+ALTER TABLE users ALTER COLUMN id DROP DEFAULT;
+ALTER TABLE password_reset_tokens ALTER COLUMN id DROP DEFAULT;
+ALTER TABLE email_verification_tokens ALTER COLUMN id DROP DEFAULT;
+
+-- +goose Down
+ALTER TABLE users ALTER COLUMN id SET DEFAULT gen_random_uuid();
+ALTER TABLE password_reset_tokens ALTER COLUMN id SET DEFAULT gen_random_uuid();
+ALTER TABLE email_verification_tokens ALTER COLUMN id SET DEFAULT gen_random_uuid();
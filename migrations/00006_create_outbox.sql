@@ -0,0 +1,15 @@
+-- +goose Up
+CREATE TABLE IF NOT EXISTS outbox (
+    id           uuid PRIMARY KEY,
+    event_type   text NOT NULL,
+    user_id      uuid NOT NULL,
+    payload      text NOT NULL,
+    created_at   timestamptz,
+    published_at timestamptz
+);
+
+-- The relay only scans unpublished events, oldest first
+CREATE INDEX IF NOT EXISTS idx_outbox_pending ON outbox (created_at) WHERE published_at IS NULL;
+
+-- +goose Down
+DROP TABLE IF EXISTS outbox;
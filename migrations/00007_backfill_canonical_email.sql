@@ -0,0 +1,11 @@
+-- +goose Up
+-- Rows written before canonicalization have no canonical email. Backfill it with the
+-- lowercased email, which is what the normalizer yields without provider rules; rows are
+-- not rewritten when rules are enabled later. Emails differing only in case must be
+-- merged before this runs, or the unique index fails.
+UPDATE users SET canonical_email = lower(trim(email)) WHERE canonical_email IS NULL OR canonical_email = '';
+ALTER TABLE users ALTER COLUMN canonical_email SET NOT NULL;
+CREATE UNIQUE INDEX IF NOT EXISTS idx_users_canonical_email ON users (canonical_email);
+
+-- +goose Down
+DROP INDEX IF EXISTS idx_users_canonical_email;
@@ -0,0 +1,9 @@
+-- +goose Up
+-- Display names are unique, ignoring case, within an organization. Users without one,
+-- the default until tokens carry an org_id claim, and soft-deleted users are not
+-- constrained. Duplicate names within an organization must be renamed before this runs.
+CREATE UNIQUE INDEX IF NOT EXISTS idx_users_org_name_ci ON users (org_id, lower(first_name), lower(last_name))
+    WHERE org_id IS NOT NULL AND deleted_at IS NULL;
+
+-- +goose Down
+DROP INDEX IF EXISTS idx_users_org_name_ci;
@@ -0,0 +1,11 @@
+// Package migrations embeds the versioned SQL schema migrations.
+// Files are named NNNNN_description.sql and contain goose Up and Down sections.
+// The sqlite directory holds the SQLite equivalents under the same version numbers.
+package migrations
+
+import "embed"
+
+// FS holds the SQL migration files
+//
+//go:embed *.sql sqlite/*.sql
+var FS embed.FS
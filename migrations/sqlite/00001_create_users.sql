@@ -0,0 +1,28 @@
+-- +goose Up
+CREATE TABLE IF NOT EXISTS users (
+    id                        text PRIMARY KEY,
+    email                     text NOT NULL,
+    canonical_email           text NOT NULL,
+    org_id                    text,
+    password                  text NOT NULL,
+    first_name                varchar(100),
+    last_name                 varchar(100),
+    phone                     varchar(20),
+    status                    varchar(20) DEFAULT 'active',
+    email_verified            boolean NOT NULL DEFAULT false,
+    version                   integer NOT NULL DEFAULT 1,
+    created_at                datetime,
+    updated_at                datetime,
+    deleted_at                datetime,
+    deleted_reason            varchar(500),
+    failed_login_attempts     integer NOT NULL DEFAULT 0,
+    failed_login_window_start datetime,
+    locked_until              datetime
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_users_email ON users (email);
+CREATE INDEX IF NOT EXISTS idx_users_org_id ON users (org_id);
+CREATE INDEX IF NOT EXISTS idx_users_deleted_at ON users (deleted_at);
+
+-- +goose Down
+DROP TABLE IF EXISTS users;
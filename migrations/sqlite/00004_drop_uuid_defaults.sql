@@ -0,0 +1,6 @@
+-- +goose Up
+-- SQLite tables never had a database-side ID default; kept to align versions with Postgres
+SELECT 1;
+
+-- +goose Down
+SELECT 1;
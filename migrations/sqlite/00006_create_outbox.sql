@@ -0,0 +1,15 @@
+-- +goose Up
+CREATE TABLE IF NOT EXISTS outbox (
+    id           text PRIMARY KEY,
+    event_type   text NOT NULL,
+    user_id      text NOT NULL,
+    payload      text NOT NULL,
+    created_at   datetime,
+    published_at datetime
+);
+
+-- The relay only scans unpublished events, oldest first
+CREATE INDEX IF NOT EXISTS idx_outbox_pending ON outbox (created_at) WHERE published_at IS NULL;
+
+-- +goose Down
+DROP TABLE IF EXISTS outbox;
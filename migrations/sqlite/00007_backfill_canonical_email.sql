@@ -0,0 +1,6 @@
+-- +goose Up
+-- SQLite databases always had the column, so only the index is created here
+CREATE UNIQUE INDEX IF NOT EXISTS idx_users_canonical_email ON users (canonical_email);
+
+-- +goose Down
+DROP INDEX IF EXISTS idx_users_canonical_email;
@@ -0,0 +1,16 @@
+-- +goose Up
+-- Events that can't be decoded are flagged instead of blocking the relay, and published
+-- events are pruned after the retention period
+ALTER TABLE outbox ADD COLUMN failed_at datetime;
+ALTER TABLE outbox ADD COLUMN last_error text;
+
+DROP INDEX IF EXISTS idx_outbox_pending;
+CREATE INDEX IF NOT EXISTS idx_outbox_pending ON outbox (created_at) WHERE published_at IS NULL AND failed_at IS NULL;
+CREATE INDEX IF NOT EXISTS idx_outbox_published ON outbox (published_at) WHERE published_at IS NOT NULL;
+
+-- +goose Down
+DROP INDEX IF EXISTS idx_outbox_published;
+DROP INDEX IF EXISTS idx_outbox_pending;
+CREATE INDEX IF NOT EXISTS idx_outbox_pending ON outbox (created_at) WHERE published_at IS NULL;
+ALTER TABLE outbox DROP COLUMN last_error;
+ALTER TABLE outbox DROP COLUMN failed_at;
@@ -0,0 +1,10 @@
+-- +goose Up
+-- Emails are lowercased on write and lookup (validation.NormalizeEmail), so the plain
+-- unique index on email serves case-insensitive lookups once rows written before
+-- normalization are lowercased too. Emails differing only in case must be merged before
+-- this runs, or the unique index fails; a lower(email) index is not needed afterwards.
+UPDATE users SET email = lower(trim(email)) WHERE email <> lower(trim(email));
+
+-- +goose Down
+-- The original case is not kept, so there is nothing to restore
+SELECT 1;
@@ -0,0 +1,16 @@
+-- +goose Up
+-- Audit records are written in the same transaction as the change they describe
+CREATE TABLE IF NOT EXISTS audit_log (
+    id              text PRIMARY KEY,
+    action          text NOT NULL,
+    target_id       text NOT NULL,
+    actor_id        text NOT NULL DEFAULT '',
+    impersonator_id text NOT NULL DEFAULT '',
+    details         text NOT NULL DEFAULT '',
+    created_at      datetime NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_audit_log_target ON audit_log (target_id, created_at);
+
+-- +goose Down
+DROP TABLE IF EXISTS audit_log;
@@ -0,0 +1,62 @@
+// Package pagination holds offset pagination parameters and result metadata, so the
+// clamping rules and page arithmetic are defined once for every layer.
+package pagination
+
+// Page sizes; sizes outside 1..MaxPageSize fall back to DefaultPageSize
+const (
+	DefaultPageSize = 10
+	MaxPageSize     = 100
+)
+
+// Params is a validated page request. Build it with NewParams so Page and PageSize
+// are always in range.
+type Params struct {
+	Page     int
+	PageSize int
+}
+
+// NewParams clamps page to at least 1 and replaces a page size outside
+// 1..MaxPageSize with DefaultPageSize
+func NewParams(page, pageSize int) Params {
+	if page < 1 {
+		page = 1
+	}
+	if pageSize < 1 || pageSize > MaxPageSize {
+		pageSize = DefaultPageSize
+	}
+	return Params{Page: page, PageSize: pageSize}
+}
+
+// Offset is the number of items before the page
+func (p Params) Offset() int {
+	return (p.Page - 1) * p.PageSize
+}
+
+// Limit is the maximum number of items on the page
+func (p Params) Limit() int {
+	return p.PageSize
+}
+
+// Result is the metadata returned alongside a page of items
+type Result struct {
+	Total      int64
+	Page       int
+	PageSize   int
+	TotalPages int
+	HasMore    bool
+}
+
+// NewResult describes the page p within total items. Pages past the last one are
+// empty but still report the real bounds. Params not built with NewParams, such as the
+// zero value, are clamped first rather than dividing by a zero page size.
+func NewResult(p Params, total int64) Result {
+	p = NewParams(p.Page, p.PageSize)
+	totalPages := int((total + int64(p.PageSize) - 1) / int64(p.PageSize))
+	return Result{
+		Total:      total,
+		Page:       p.Page,
+		PageSize:   p.PageSize,
+		TotalPages: totalPages,
+		HasMore:    p.Page < totalPages,
+	}
+}
@@ -0,0 +1,57 @@
+package pagination
+
+import "testing"
+
+func TestNewParams(t *testing.T) {
+	tests := []struct {
+		name       string
+		page       int
+		pageSize   int
+		want       Params
+		wantOffset int
+	}{
+		{name: "in range", page: 3, pageSize: 20, want: Params{Page: 3, PageSize: 20}, wantOffset: 40},
+		{name: "zero page", page: 0, pageSize: 20, want: Params{Page: 1, PageSize: 20}},
+		{name: "negative page", page: -2, pageSize: 20, want: Params{Page: 1, PageSize: 20}},
+		{name: "zero page size", page: 1, pageSize: 0, want: Params{Page: 1, PageSize: DefaultPageSize}},
+		{name: "largest page size", page: 1, pageSize: MaxPageSize, want: Params{Page: 1, PageSize: MaxPageSize}},
+		{name: "page size over the max", page: 2, pageSize: MaxPageSize + 1, want: Params{Page: 2, PageSize: DefaultPageSize}, wantOffset: DefaultPageSize},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := NewParams(tt.page, tt.pageSize)
+			if got != tt.want {
+				t.Errorf("NewParams(%d, %d) = %+v, want %+v", tt.page, tt.pageSize, got, tt.want)
+			}
+			if got.Offset() != tt.wantOffset {
+				t.Errorf("Offset = %d, want %d", got.Offset(), tt.wantOffset)
+			}
+		})
+	}
+}
+
+func TestNewResult(t *testing.T) {
+	tests := []struct {
+		name   string
+		params Params
+		total  int64
+		want   Result
+	}{
+		{name: "no items", params: Params{Page: 1, PageSize: 10}, want: Result{Page: 1, PageSize: 10}},
+		{name: "first of several", params: Params{Page: 1, PageSize: 10}, total: 25, want: Result{Total: 25, Page: 1, PageSize: 10, TotalPages: 3, HasMore: true}},
+		{name: "last partial page", params: Params{Page: 3, PageSize: 10}, total: 25, want: Result{Total: 25, Page: 3, PageSize: 10, TotalPages: 3}},
+		{name: "last full page", params: Params{Page: 2, PageSize: 10}, total: 20, want: Result{Total: 20, Page: 2, PageSize: 10, TotalPages: 2}},
+		{name: "one past a full last page", params: Params{Page: 1, PageSize: 10}, total: 11, want: Result{Total: 11, Page: 1, PageSize: 10, TotalPages: 2, HasMore: true}},
+		{name: "past the last page", params: Params{Page: 5, PageSize: 10}, total: 25, want: Result{Total: 25, Page: 5, PageSize: 10, TotalPages: 3}},
+		{name: "zero value params", total: 25, want: Result{Total: 25, Page: 1, PageSize: DefaultPageSize, TotalPages: 3, HasMore: true}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := NewResult(tt.params, tt.total); got != tt.want {
+				t.Errorf("NewResult(%+v, %d) = %+v, want %+v", tt.params, tt.total, got, tt.want)
+			}
+		})
+	}
+}